@@ -56,5 +56,9 @@ func main() {
 	// Block until interrupt signal is received
 	<-sigChan
 	log.Info().Msg("Shutting down DU-UE Simulator")
+	// the UE NAS state is saved on stop
+	if err := duSim.Stop(); err != nil {
+		log.Error().Err(err).Msg("Failed to stop DU simulator")
+	}
 	os.Exit(0)
 }
@@ -1,11 +1,13 @@
 package du
 
 import (
+	"context"
 	"du_ue/internal/common/logger"
 	"du_ue/internal/uecontext"
 	"du_ue/pkg/config"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -15,6 +17,11 @@ const (
 	DU_LOST     = "DU_LOST"
 )
 
+const (
+	RECONNECT_MIN_BACKOFF = 1 * time.Second
+	RECONNECT_MAX_BACKOFF = 30 * time.Second
+)
+
 // DU represents the Distributed Unit simulator
 type DU struct {
 	*logger.Logger
@@ -26,14 +33,36 @@ type DU struct {
 	UEConfig *config.UEConfig
 	f1Client F1Client
 	ue       *UeChannel
-	hoCtx    *HandoverContext // Handover state and role tracking
+	hoCtx    *HandoverContext       // Handover state and role tracking
+	resCtx   *ResourceStatusContext // Resource status reporting (cell load)
+	posCtx   *PositioningContext    // Positioning measurements (synthetic geometry)
+	ovlCtx   *OverloadContext       // Simulated overload and gNB-DU Status Indication
+	f1uCtx   *F1uContext            // F1-U GTP-U endpoint of the DRBs
+	cuUeId   atomic.Int64           // gNB-CU UE F1AP ID, allocated by CU-CP with its first DL message to the UE
 	mu       sync.Mutex
+
+	reconnectStop  chan struct{}     // closed when the DU leaves DU_LOST, nil while no reconnect loop runs
+	reconnectRetry chan f1SetupRetry // failed F1 Setup attempts reported to the reconnect loop
+}
+
+// f1SetupRetry tells the reconnect loop how to retry F1 Setup
+type f1SetupRetry struct {
+	wait      time.Duration // minimum wait before the next attempt, the TimeToWait of F1 Setup Failure
+	reconnect bool          // the connection is lost and is re-established before F1 Setup Request
 }
 
 type UeChannel struct {
 	UE                   *uecontext.UeContext
 	ReceiveFromUeChannel chan []byte // almost rrc msg from ue is encoded to F1 msg then send to CU-CP
 	SendToUeChannel      chan []byte
+	// user plane packets on the DRBs of the UE
+	ReceiveDataFromUeChannel chan uecontext.DrbPdu
+	SendDataToUeChannel      chan uecontext.DrbPdu
+	trace                    atomic.Pointer[TraceContext] // active subscriber trace, nil if none
+	rrcReleased              atomic.Bool                  // RRCRelease sent, the next UE message starts a new RRC connection
+	rrcConnected             atomic.Bool                  // RRCSetupComplete or handover RRCReconfigurationComplete received, until RRCRelease
+	ctx                      context.Context
+	cancel                   context.CancelFunc // stops the UE and its RRC handler
 }
 
 // NewDU creates a new DU simulator instance
@@ -52,7 +81,7 @@ func NewDU(cfg *config.Config) (*DU, error) {
 	}
 
 	// Create F1AP client
-	f1Client, err := NewF1APClient(cfg.DU.CUCPAddr, cfg.DU.CUCPPort, cfg.DU.LocalAddr, cfg.DU.LocalPort, du)
+	f1Client, err := NewF1APClient(&cfg.DU, du)
 	if err != nil {
 		return nil, fmt.Errorf("create F1AP client: %w", err)
 	}
@@ -61,11 +90,24 @@ func NewDU(cfg *config.Config) (*DU, error) {
 	// Initialize handover context
 	du.InitHandoverContext()
 
+	// Initialize resource status reporting context
+	du.InitResourceStatusContext()
+
+	// Initialize positioning context
+	du.InitPositioningContext()
+
+	// Initialize F1-U endpoint
+	du.InitF1uContext()
+
+	// Initialize overload context
+	du.InitOverloadContext()
+
 	return du, nil
 }
 
 // InitUE creates UE context and initializes channels
-// This should be called after F1 Setup Procedure is complete
+// This should be called after F1 Setup Procedure is complete, without du.mu: the UE waits for
+// RRCSetup, and Stop or a lost F1 connection cancels it under du.mu
 func (du *DU) InitUE() error {
 	if du.UEConfig == nil {
 		return fmt.Errorf("UE config not set")
@@ -75,21 +117,35 @@ func (du *DU) InitUE() error {
 	toUE := make(chan []byte, 100)   // DU -> UE (RRC messages)
 	fromUE := make(chan []byte, 100) // UE -> DU (RRC messages)
 	// Set up UE channel structure
-	du.ue = &UeChannel{
-		ReceiveFromUeChannel: fromUE,
-		SendToUeChannel:      toUE,
+	ctx, cancel := context.WithCancel(context.Background())
+	toUEData := make(chan uecontext.DrbPdu, 1000)
+	fromUEData := make(chan uecontext.DrbPdu, 1000)
+	ue := &UeChannel{
+		ReceiveFromUeChannel:     fromUE,
+		SendToUeChannel:          toUE,
+		ReceiveDataFromUeChannel: fromUEData,
+		SendDataToUeChannel:      toUEData,
+		ctx:                      ctx,
+		cancel:                   cancel,
 	}
+	du.mu.Lock()
+	du.ue = ue
+	du.mu.Unlock()
 	// Start goroutine to handle RRC messages from UE
 	go du.HandleRrcFromUE()
+	go du.HandleDataFromUE()
 
 	time.Sleep(1 * time.Second)
 
 	// Create UE context
-	ueCtx := uecontext.InitUE(toUE, fromUE, *du.UEConfig)
+	ueCtx := uecontext.InitUE(ctx, toUE, fromUE, toUEData, fromUEData, *du.UEConfig)
 	if ueCtx == nil {
 		return fmt.Errorf("failed to initialize UE context")
 	}
-	du.ue.UE = ueCtx
+	du.mu.Lock()
+	ue.UE = ueCtx
+	du.mu.Unlock()
+	ueCtx.SetServingCell(du.Config.Cell)
 
 	du.Info("UE context initialized after F1 Setup")
 	return nil
@@ -121,6 +177,7 @@ func (du *DU) Start() error {
 	}
 
 	du.State = DU_ACTIVE
+	du.StartOverloadMonitor()
 	return nil
 }
 
@@ -129,6 +186,13 @@ func (du *DU) Stop() error {
 	du.mu.Lock()
 	defer du.mu.Unlock()
 
+	du.StopResourceStatusReporting()
+	du.StopPositioningMeasurements()
+	du.StopOverloadMonitor()
+	du.StopF1u()
+	du.cancelUe()
+	du.endReconnect()
+
 	if du.f1Client != nil {
 		du.f1Client.Close()
 	}
@@ -137,6 +201,13 @@ func (du *DU) Stop() error {
 	return nil
 }
 
+// saveUeState keeps the NAS state of the UE for its next context, before the current one is dropped
+func (du *DU) saveUeState() {
+	if ue := du.ue; ue != nil && ue.UE != nil {
+		ue.UE.SaveState()
+	}
+}
+
 // SendF1SetupRequest sends F1 Setup Request to CU-CP
 func (du *DU) SendF1SetupRequest() error {
 	return du.f1Client.SendF1SetupRequest()
@@ -145,15 +216,16 @@ func (du *DU) SendF1SetupRequest() error {
 // OnF1SetupResponse handles F1 Setup Response from CU-CP
 func (du *DU) OnF1SetupResponse() {
 	du.mu.Lock()
-	defer du.mu.Unlock()
-
-	if du.State == DU_INACTIVE {
+	if du.State == DU_INACTIVE || du.State == DU_LOST {
 		du.State = DU_ACTIVE
+		du.endReconnect()
 		du.Info("F1 Setup completed successfully")
 	}
+	initUe := du.ue == nil
+	du.mu.Unlock()
 
 	// Initialize UE context and channels after F1 Setup is complete
-	if du.ue == nil {
+	if initUe {
 		if err := du.InitUE(); err != nil {
 			du.Error("Failed to initialize UE context after F1 Setup: %v", err)
 			return
@@ -162,6 +234,191 @@ func (du *DU) OnF1SetupResponse() {
 	}
 }
 
+// OnF1ConnectionLost handles the loss of the SCTP association with CU-CP.
+// The UE context is released and the DU reconnects and redoes F1 Setup,
+// after which the UE goes through a fresh RRC setup and registration.
+func (du *DU) OnF1ConnectionLost() {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+
+	du.cancelUe()
+	switch du.State {
+	case DU_INACTIVE:
+		// stopped on purpose
+		return
+	case DU_ACTIVE:
+		du.Warn("F1 connection to CU-CP lost")
+		du.releaseF1State()
+	default:
+		du.Warn("F1 connection to CU-CP lost before F1 Setup Response")
+	}
+
+	du.startReconnect(f1SetupRetry{reconnect: true})
+}
+
+// OnF1SetupFailure handles F1 Setup Failure from CU-CP. F1 Setup is retried on the same
+// connection with backoff, not before the TimeToWait of the failure.
+func (du *DU) OnF1SetupFailure(timeToWait time.Duration) {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+
+	if du.State == DU_INACTIVE {
+		return
+	}
+	du.Warn("F1 Setup rejected by CU-CP")
+	if du.State == DU_ACTIVE {
+		du.cancelUe()
+		du.releaseF1State()
+	}
+
+	du.startReconnect(f1SetupRetry{wait: timeToWait})
+}
+
+// OnF1PeerRestart handles a CU-CP restart detected on a still usable association.
+// As after a reconnect, the UE context is released and F1 Setup is redone.
+func (du *DU) OnF1PeerRestart() {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+
+	du.cancelUe()
+	switch du.State {
+	case DU_INACTIVE:
+		return
+	case DU_LOST:
+		// the pending F1 Setup Request went with the restart
+		du.startReconnect(f1SetupRetry{})
+		return
+	}
+
+	du.Warn("CU-CP restarted, redoing F1 Setup")
+	du.releaseF1State()
+
+	if err := du.SendF1SetupRequest(); err != nil {
+		du.Error("Failed to send F1 Setup Request after CU-CP restart: %v", err)
+		du.startReconnect(f1SetupRetry{reconnect: true})
+	}
+}
+
+// cancelUe saves the NAS state of the UE and stops it, also while it still waits for RRCSetup
+func (du *DU) cancelUe() {
+	du.saveUeState()
+	if ue := du.ue; ue != nil && ue.cancel != nil {
+		ue.cancel()
+	}
+}
+
+// releaseF1State drops all state shared with CU-CP and moves the DU to DU_LOST
+func (du *DU) releaseF1State() {
+	du.State = DU_LOST
+
+	du.StopResourceStatusReporting()
+	du.StopPositioningMeasurements()
+	du.deactivateTrace()
+	du.releaseDrbs(du.ue)
+	du.ue = nil
+}
+
+// startReconnect starts the reconnect loop, or reports the failed attempt to the loop already running
+func (du *DU) startReconnect(retry f1SetupRetry) {
+	if du.reconnectStop == nil {
+		du.reconnectStop = make(chan struct{})
+		du.reconnectRetry = make(chan f1SetupRetry, 1)
+		go du.reconnect(retry, du.reconnectStop, du.reconnectRetry)
+		return
+	}
+
+	// merged with a retry the loop has not taken yet, the senders hold du.mu
+	select {
+	case pending := <-du.reconnectRetry:
+		retry.wait = max(retry.wait, pending.wait)
+		retry.reconnect = retry.reconnect || pending.reconnect
+	default:
+	}
+	du.reconnectRetry <- retry
+}
+
+// endReconnect stops the reconnect loop, on F1 Setup Response or when the DU is stopped
+func (du *DU) endReconnect() {
+	if du.reconnectStop != nil {
+		close(du.reconnectStop)
+		du.reconnectStop = nil
+	}
+}
+
+// reconnect retries F1 Setup with exponential backoff until F1 Setup Response, re-establishing
+// the SCTP association first when it is lost
+func (du *DU) reconnect(retry f1SetupRetry, stop <-chan struct{}, retries <-chan f1SetupRetry) {
+	backoff := RECONNECT_MIN_BACKOFF
+
+	for {
+		wait := max(backoff, retry.wait)
+		if retry.reconnect {
+			du.Info("Reconnecting to CU-CP in %v", wait)
+		} else {
+			du.Info("Retrying F1 Setup in %v", wait)
+		}
+		select {
+		case <-time.After(wait):
+		case <-stop:
+			return
+		}
+
+		// a connection loss while waiting
+		select {
+		case pending := <-retries:
+			retry.reconnect = retry.reconnect || pending.reconnect
+		default:
+		}
+
+		var err error
+		if retry.reconnect {
+			err = du.reestablishF1(stop)
+		} else {
+			err = du.SendF1SetupRequest()
+		}
+		if err != nil {
+			du.Warn("F1 Setup attempt failed: %v", err)
+			retry = f1SetupRetry{reconnect: true}
+		} else {
+			du.Info("F1 Setup Request sent, waiting for F1 Setup Response")
+			select {
+			case retry = <-retries:
+			case <-stop:
+				return
+			}
+		}
+
+		backoff = min(backoff*2, RECONNECT_MAX_BACKOFF)
+	}
+}
+
+// reestablishF1 reconnects to CU-CP and sends F1 Setup Request. du.mu is not held while
+// connecting, a DU stopped meanwhile closes the new connection.
+func (du *DU) reestablishF1(stop <-chan struct{}) error {
+	du.f1Client.Close()
+
+	if err := du.f1Client.Connect(); err != nil {
+		return fmt.Errorf("connect to CU-CP: %w", err)
+	}
+
+	du.mu.Lock()
+	defer du.mu.Unlock()
+
+	select {
+	case <-stop:
+		du.f1Client.Close()
+		return nil
+	default:
+	}
+
+	go du.f1Client.ReadLoop()
+
+	if err := du.SendF1SetupRequest(); err != nil {
+		return fmt.Errorf("send F1 Setup Request: %w", err)
+	}
+	return nil
+}
+
 func (du *DU) SetUEChannelForTest(ue *UeChannel) {
 	du.mu.Lock()
 	defer du.mu.Unlock()
@@ -179,3 +436,21 @@ func (du *DU) GetUEChannelForTest() *UeChannel {
 	defer du.mu.Unlock()
 	return du.ue
 }
+
+func (du *DU) SetRrcConnectedForTest(connected bool) {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+	if du.ue != nil {
+		du.ue.rrcConnected.Store(connected)
+	}
+}
+
+func (du *DU) EvaluateOverloadForTest() {
+	du.evaluateOverload()
+}
+
+func (du *DU) GetStateForTest() string {
+	du.mu.Lock()
+	defer du.mu.Unlock()
+	return du.State
+}
@@ -10,18 +10,41 @@ func (du *DU) HandleRrcFromUE() {
 	du.Info("==== Started listening for RRC messages from UE ===")
 
 	var isInitialMessage bool = true // First message is Initial UL RRC Message Transfer
+	ue := du.ue
+	var released <-chan struct{} // never closed for UE channels without a context (tests)
+	if ue.ctx != nil {
+		released = ue.ctx.Done()
+	}
 
 	for {
 		select {
-		case rrcBytes, ok := <-du.ue.ReceiveFromUeChannel:
+		case <-released:
+			du.Info("UE released, stopping RRC handler")
+			return
+		case rrcBytes, ok := <-ue.ReceiveFromUeChannel:
 			if !ok {
 				du.Warn("ReceiveFromUeChannel closed, stopping RRC handler")
 				return
 			}
 
+			if ue.rrcReleased.Swap(false) {
+				// RRCSetupRequest of a new RRC connection after RRCRelease
+				isInitialMessage = true
+			}
+
+			du.traceRrc(TRACE_UL, rrcBytes)
+
 			// Intercept and handle specific RRC messages
 			du.dispatchRrcMessage(rrcBytes)
 
+			if isInitialMessage && du.IsOverloaded() {
+				// RRCSetupRequest is not forwarded while overloaded, the UE retries after the wait time
+				if err := du.sendRrcReject(); err != nil {
+					du.Error("Failed to send RRCReject: %v", err)
+				}
+				continue
+			}
+
 			if isInitialMessage {
 				// First RRC message (RRCSetupRequest) -> Initial UL RRC Message Transfer
 				if err := du.sendInitialULRRCMessageTransfer(rrcBytes); err != nil {
@@ -54,6 +77,8 @@ func (du *DU) dispatchRrcMessage(rrcBytes []byte) {
 	}
 
 	switch c1.Choice {
+	case rrcies.UL_DCCH_MessageType_C1_Choice_RrcSetupComplete:
+		du.setRrcConnected()
 	case rrcies.UL_DCCH_MessageType_C1_Choice_MeasurementReport:
 		du.Info("Intercepted MeasurementReport")
 		if c1.MeasurementReport != nil {
@@ -61,11 +86,19 @@ func (du *DU) dispatchRrcMessage(rrcBytes []byte) {
 		}
 	case rrcies.UL_DCCH_MessageType_C1_Choice_RrcReconfigurationComplete:
 		du.Info("Intercepted RRCReconfigurationComplete")
+		du.setRrcConnected()
 		// Signal that Reconfiguration (Handover) is complete
 		du.handleRrcReconfigurationComplete()
 	}
 }
 
+// setRrcConnected counts the UE as active once its RRC connection is set up or handed over
+func (du *DU) setRrcConnected() {
+	if ue := du.ue; ue != nil {
+		ue.rrcConnected.Store(true)
+	}
+}
+
 // handleRrcReconfigurationComplete handles completion of HO or setup
 // Structure: RRCReconfigurationComplete -> CriticalExtensions -> RrcReconfigurationComplete_IEs
 func (du *DU) handleRrcReconfigurationComplete() {
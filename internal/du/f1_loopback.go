@@ -0,0 +1,81 @@
+package du
+
+import (
+	"fmt"
+	"net"
+	"sync"
+)
+
+var (
+	loopbackMu        sync.Mutex
+	loopbackListeners = make(map[string]*LoopbackListener)
+)
+
+// LoopbackListener accepts in-process F1 connections of DUs using the loopback
+// transport. Messages on the accepted connections are framed with WriteF1Frame
+// and ReadF1Frame.
+type LoopbackListener struct {
+	name   string
+	conns  chan net.Conn
+	closed chan struct{}
+	once   sync.Once
+}
+
+// ListenLoopback registers an in-process CU-CP peer under the given name, which
+// DUs reach by setting cucp_address to it
+func ListenLoopback(name string) (*LoopbackListener, error) {
+	loopbackMu.Lock()
+	defer loopbackMu.Unlock()
+
+	if _, ok := loopbackListeners[name]; ok {
+		return nil, fmt.Errorf("loopback %s already in use", name)
+	}
+	l := &LoopbackListener{
+		name:   name,
+		conns:  make(chan net.Conn),
+		closed: make(chan struct{}),
+	}
+	loopbackListeners[name] = l
+	return l, nil
+}
+
+// Accept waits for the next DU connection
+func (l *LoopbackListener) Accept() (net.Conn, error) {
+	select {
+	case conn := <-l.conns:
+		return conn, nil
+	case <-l.closed:
+		return nil, net.ErrClosed
+	}
+}
+
+// Close unregisters the listener, established connections are not affected
+func (l *LoopbackListener) Close() error {
+	l.once.Do(func() {
+		loopbackMu.Lock()
+		delete(loopbackListeners, l.name)
+		loopbackMu.Unlock()
+		close(l.closed)
+	})
+	return nil
+}
+
+// dialLoopback connects to the loopback listener of the given name
+func dialLoopback(name string) (net.Conn, error) {
+	loopbackMu.Lock()
+	l, ok := loopbackListeners[name]
+	loopbackMu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("no loopback listener %s", name)
+	}
+
+	local, remote := net.Pipe()
+	select {
+	case l.conns <- remote:
+		return local, nil
+	case <-l.closed:
+		local.Close()
+		remote.Close()
+		return nil, fmt.Errorf("loopback listener %s closed", name)
+	}
+}
@@ -0,0 +1,53 @@
+package du
+
+import (
+	"du_ue/internal/common/logger"
+	"du_ue/pkg/config"
+	"errors"
+	"fmt"
+)
+
+const (
+	F1_TRANSPORT_SCTP     = "sctp"
+	F1_TRANSPORT_TCP      = "tcp"
+	F1_TRANSPORT_LOOPBACK = "loopback"
+)
+
+var (
+	// ErrF1ConnectionLost is returned by Read when the transport learnt that the connection is gone
+	ErrF1ConnectionLost = errors.New("F1 connection lost")
+	// ErrF1PeerRestarted is returned by Read when CU-CP restarted, the connection stays usable
+	ErrF1PeerRestarted = errors.New("CU-CP restarted")
+)
+
+// F1Transport carries F1AP messages between the DU and CU-CP
+type F1Transport interface {
+	Connect() error
+	Close() error
+	// Write sends one F1AP message, on the given stream if the transport has streams
+	Write(data []byte, stream uint16) error
+	// Read blocks until the next F1AP message, io.EOF when CU-CP closed the connection.
+	// ErrF1PeerRestarted is not fatal, reading can go on.
+	Read() ([]byte, error)
+	// Streams returns the number of outbound streams of the connection
+	Streams() uint16
+}
+
+// NewF1Transport creates the F1 transport selected in the DU config
+func NewF1Transport(cfg *config.DUConfig, log *logger.Logger) (F1Transport, error) {
+	switch cfg.Transport {
+	case "", F1_TRANSPORT_SCTP:
+		cuAddrs := append([]string{cfg.CUCPAddr}, cfg.CUCPAddrs...)
+		var localAddrs []string
+		if cfg.LocalAddr != "" {
+			localAddrs = append(localAddrs, cfg.LocalAddr)
+		}
+		localAddrs = append(localAddrs, cfg.LocalAddrs...)
+		return newSctpTransport(cuAddrs, cfg.CUCPPort, localAddrs, cfg.LocalPort, cfg.SCTPStreams, log), nil
+	case F1_TRANSPORT_TCP:
+		return newTcpTransport(cfg.CUCPAddr, cfg.CUCPPort, cfg.LocalAddr, cfg.LocalPort, log), nil
+	case F1_TRANSPORT_LOOPBACK:
+		return newLoopbackTransport(cfg.CUCPAddr, log), nil
+	}
+	return nil, fmt.Errorf("unknown F1 transport %q", cfg.Transport)
+}
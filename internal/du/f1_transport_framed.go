@@ -0,0 +1,141 @@
+package du
+
+import (
+	"du_ue/internal/common/logger"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"sync"
+)
+
+const (
+	F1_FRAME_HEADER_LEN = 4
+	F1_MAX_FRAME_LEN    = 1 << 20
+)
+
+// framedTransport carries F1AP over a stream connection, each message prefixed
+// by its length as a 4 byte big endian integer. Streams are not preserved.
+type framedTransport struct {
+	*logger.Logger
+
+	name string
+	dial func() (net.Conn, error)
+	mu   sync.Mutex
+	conn net.Conn // replaced by Connect on reconnect while Write and Read use it
+}
+
+// newTcpTransport dials CU-CP over TCP, for environments where SCTP is blocked
+func newTcpTransport(cuAddr string, cuPort int, localAddr string, localPort int, log *logger.Logger) *framedTransport {
+	dialer := &net.Dialer{}
+	if localAddr != "" || localPort > 0 {
+		dialer.LocalAddr = &net.TCPAddr{IP: net.ParseIP(localAddr), Port: localPort}
+	}
+	remote := net.JoinHostPort(cuAddr, strconv.Itoa(cuPort))
+	return &framedTransport{
+		Logger: log,
+		name:   "TCP " + remote,
+		dial: func() (net.Conn, error) {
+			return dialer.Dial("tcp", remote)
+		},
+	}
+}
+
+// newLoopbackTransport connects to an in-process peer listening with ListenLoopback
+func newLoopbackTransport(name string, log *logger.Logger) *framedTransport {
+	return &framedTransport{
+		Logger: log,
+		name:   "loopback " + name,
+		dial: func() (net.Conn, error) {
+			return dialLoopback(name)
+		},
+	}
+}
+
+// Connect establishes the connection with CU-CP
+func (t *framedTransport) Connect() error {
+	conn, err := t.dial()
+	if err != nil {
+		return fmt.Errorf("dial %s: %w", t.name, err)
+	}
+	t.mu.Lock()
+	t.conn = conn
+	t.mu.Unlock()
+	t.Info("F1 connection established to CU-CP over %s", t.name)
+	return nil
+}
+
+// Close closes the connection
+func (t *framedTransport) Close() error {
+	if conn := t.current(); conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+func (t *framedTransport) current() net.Conn {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.conn
+}
+
+// Streams returns 1, a stream connection has a single ordering
+func (t *framedTransport) Streams() uint16 {
+	return 1
+}
+
+// Write sends an F1AP message as one frame, the stream is ignored
+func (t *framedTransport) Write(data []byte, stream uint16) error {
+	conn := t.current()
+	if conn == nil {
+		return fmt.Errorf("%s connection not established", t.name)
+	}
+	return WriteF1Frame(conn, data)
+}
+
+// Read returns the next F1AP message received from CU-CP
+func (t *framedTransport) Read() ([]byte, error) {
+	conn := t.current()
+	if conn == nil {
+		return nil, fmt.Errorf("%s connection not established", t.name)
+	}
+	return ReadF1Frame(conn)
+}
+
+// WriteF1Frame writes an F1AP message with its length prefix in a single write
+func WriteF1Frame(w io.Writer, data []byte) error {
+	if len(data) > F1_MAX_FRAME_LEN {
+		return fmt.Errorf("F1AP message too long: %d bytes", len(data))
+	}
+	frame := make([]byte, F1_FRAME_HEADER_LEN+len(data))
+	binary.BigEndian.PutUint32(frame, uint32(len(data)))
+	copy(frame[F1_FRAME_HEADER_LEN:], data)
+	if _, err := w.Write(frame); err != nil {
+		return fmt.Errorf("write F1 frame: %w", err)
+	}
+	return nil
+}
+
+// ReadF1Frame reads one length prefixed F1AP message
+func ReadF1Frame(r io.Reader) ([]byte, error) {
+	var header [F1_FRAME_HEADER_LEN]byte
+	if _, err := io.ReadFull(r, header[:]); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:])
+	if length > F1_MAX_FRAME_LEN {
+		return nil, fmt.Errorf("F1 frame too long: %d bytes", length)
+	}
+	data := make([]byte, length)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.ErrUnexpectedEOF {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	return data, nil
+}
@@ -0,0 +1,179 @@
+package du
+
+import (
+	"du_ue/internal/common/logger"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/ishidawataru/sctp"
+)
+
+const (
+	DEFAULT_SCTP_STREAMS = 2
+)
+
+// sctpTransport carries F1AP over an SCTP association (TS 38.472), optionally multi-homed
+type sctpTransport struct {
+	*logger.Logger
+
+	cuAddrs    []string
+	cuPort     int
+	localAddrs []string
+	localPort  int
+	streams    int                           // requested number of streams
+	ostreams   atomic.Uint32                 // outbound streams negotiated with CU-CP
+	conn       atomic.Pointer[sctp.SCTPConn] // replaced by Connect on reconnect while Write and Read use it
+}
+
+func newSctpTransport(cuAddrs []string, cuPort int, localAddrs []string, localPort int, streams int, log *logger.Logger) *sctpTransport {
+	if streams == 0 {
+		streams = DEFAULT_SCTP_STREAMS
+	}
+	return &sctpTransport{
+		Logger:     log,
+		cuAddrs:    cuAddrs,
+		cuPort:     cuPort,
+		localAddrs: localAddrs,
+		localPort:  localPort,
+		streams:    streams,
+	}
+}
+
+// Connect establishes the SCTP association with CU-CP
+func (t *sctpTransport) Connect() error {
+	remoteAddr, err := resolveSCTPAddr(t.cuAddrs, t.cuPort)
+	if err != nil {
+		return fmt.Errorf("resolve remote SCTP addr: %w", err)
+	}
+
+	var localAddr *sctp.SCTPAddr
+	if len(t.localAddrs) > 0 || t.localPort > 0 {
+		localAddr, err = resolveSCTPAddr(t.localAddrs, t.localPort)
+		if err != nil {
+			return fmt.Errorf("resolve local SCTP addr: %w", err)
+		}
+		t.Info("Binding SCTP to local %s", localAddr)
+	}
+
+	socketCfg := sctp.SocketConfig{
+		InitMsg: sctp.InitMsg{
+			NumOstreams:    uint16(t.streams),
+			MaxInstreams:   uint16(t.streams),
+			MaxAttempts:    2,
+			MaxInitTimeout: 2,
+		},
+		NotificationHandler: t.handleNotification,
+	}
+	conn, err := socketCfg.Dial("sctp", localAddr, remoteAddr)
+	if err != nil {
+		return fmt.Errorf("dial SCTP %s: %w", remoteAddr, err)
+	}
+
+	events := sctp.SCTP_EVENT_DATA_IO | sctp.SCTP_EVENT_SHUTDOWN | sctp.SCTP_EVENT_ASSOCIATION | sctp.SCTP_EVENT_ADDRESS
+	if err := conn.SubscribeEvents(events); err != nil {
+		return fmt.Errorf("subscribe events: %w", err)
+	}
+
+	info := &sctp.SndRcvInfo{PPID: F1AP_PPID}
+	if err := conn.SetDefaultSentParam(info); err != nil {
+		return fmt.Errorf("set default sent param: %w", err)
+	}
+
+	if err := conn.SetReadBuffer(8192); err != nil {
+		return fmt.Errorf("set read buffer: %w", err)
+	}
+
+	t.Info("Connection configured with PPID=%d", F1AP_PPID)
+
+	t.ostreams.Store(uint32(t.streams))
+	if status, err := conn.GetStatus(); err == nil && status.Ostreams > 0 {
+		t.ostreams.Store(uint32(status.Ostreams))
+	}
+
+	t.conn.Store(conn)
+	t.Info("SCTP connection established to CU-CP %s (%d outbound streams, %d requested)", remoteAddr, t.ostreams.Load(), t.streams)
+	if primary, err := conn.SCTPGetPrimaryPeerAddr(); err == nil {
+		t.Info("Primary CU-CP path: %s", primary)
+	}
+	return nil
+}
+
+// resolveSCTPAddr resolves a list of host addresses sharing one SCTP port
+func resolveSCTPAddr(addrs []string, port int) (*sctp.SCTPAddr, error) {
+	sctpAddr := &sctp.SCTPAddr{Port: port}
+	for _, addr := range addrs {
+		ipAddr, err := net.ResolveIPAddr("ip", addr)
+		if err != nil {
+			return nil, fmt.Errorf("resolve %s: %w", addr, err)
+		}
+		sctpAddr.IPAddrs = append(sctpAddr.IPAddrs, *ipAddr)
+	}
+	return sctpAddr, nil
+}
+
+// Close closes the SCTP association
+func (t *sctpTransport) Close() error {
+	if conn := t.conn.Load(); conn != nil {
+		return conn.Close()
+	}
+	return nil
+}
+
+// Streams returns the number of outbound streams negotiated with CU-CP
+func (t *sctpTransport) Streams() uint16 {
+	return uint16(t.ostreams.Load())
+}
+
+// Write sends an F1AP message on the given stream
+func (t *sctpTransport) Write(data []byte, stream uint16) error {
+	conn := t.conn.Load()
+	if conn == nil {
+		return fmt.Errorf("SCTP connection not established")
+	}
+
+	info := &sctp.SndRcvInfo{
+		PPID:   F1AP_PPID,
+		Stream: stream,
+	}
+	if _, err := conn.SCTPWrite(data, info); err != nil {
+		return fmt.Errorf("SCTP write: %w", err)
+	}
+	return nil
+}
+
+// Read returns the next F1AP message received from CU-CP. The buffer is not shared, the read loop
+// of a closed association may still be running when the next one starts.
+func (t *sctpTransport) Read() ([]byte, error) {
+	conn := t.conn.Load()
+	if conn == nil {
+		return nil, fmt.Errorf("SCTP connection not established")
+	}
+	buf := make([]byte, 8192)
+	for {
+		n, info, err := conn.SCTPRead(buf)
+		if err != nil {
+			if err == io.ErrUnexpectedEOF {
+				return nil, io.EOF
+			}
+			if err == syscall.EAGAIN || err == syscall.EINTR {
+				continue
+			}
+			return nil, err
+		}
+
+		if info == nil {
+			t.Error("Received nil info")
+			continue
+		}
+
+		if info.PPID != F1AP_PPID {
+			t.Error("Wrong PPID: %d", info.PPID)
+			continue
+		}
+
+		return buf[:n], nil
+	}
+}
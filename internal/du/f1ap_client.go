@@ -2,14 +2,16 @@ package du
 
 import (
 	"du_ue/internal/common/logger"
+	"du_ue/pkg/config"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
-	"syscall"
+	"sync/atomic"
+	"time"
 
 	f1ap "github.com/JocelynWS/f1-gen"
 	"github.com/JocelynWS/f1-gen/ies"
-	"github.com/ishidawataru/sctp"
 	"github.com/lvdund/ngap/aper"
 )
 
@@ -26,148 +28,155 @@ type F1Client interface {
 	ReadLoop()
 }
 
-// F1APClient handles SCTP connection and F1AP messaging with CU-CP
+// F1QueueStatsClient is implemented by F1 clients that queue inbound F1AP messages on workers
+type F1QueueStatsClient interface {
+	QueueStats() []F1apQueueStats
+}
+
+// F1UeClient is implemented by F1 clients that send UE-associated signalling on the stream of the UE
+type F1UeClient interface {
+	SendUe(data []byte, duUeId int64) error
+}
+
+// F1APClient handles the F1 connection and F1AP messaging with CU-CP
 type F1APClient struct {
 	*logger.Logger
 
-	cuAddr    string
-	cuPort    int
-	localAddr string
-	localPort int
-	conn      *sctp.SCTPConn
+	transport F1Transport
+	workers   atomic.Pointer[f1apWorkerPool] // started by Connect, stopped by Close
+	ueWorkers int
+	queueSize int
 	du        *DU
 }
 
-// NewF1APClient creates a new F1AP client
-func NewF1APClient(remoteAddr string, remotePort int, localAddr string, localPort int, du *DU) (*F1APClient, error) {
-	return &F1APClient{
-		cuAddr:    remoteAddr,
-		cuPort:    remotePort,
-		localAddr: localAddr,
-		localPort: localPort,
-		du:        du,
+// NewF1APClient creates a new F1AP client over the transport selected in the DU config
+func NewF1APClient(cfg *config.DUConfig, du *DU) (*F1APClient, error) {
+	c := &F1APClient{
+		du: du,
 		Logger: logger.InitLogger("info", map[string]string{
 			"mod":   "f1ap_client",
 			"du_id": fmt.Sprintf("%d", du.ID),
 		}),
-	}, nil
-}
-
-// Connect establishes SCTP connection to CU-CP
-func (c *F1APClient) Connect() error {
-	remoteAddr, err := sctp.ResolveSCTPAddr("sctp", fmt.Sprintf("%s:%d", c.cuAddr, c.cuPort))
-	if err != nil {
-		return fmt.Errorf("resolve remote SCTP addr: %w", err)
 	}
 
-	// var localAddr *sctp.SCTPAddr
-	// if c.localAddr != "" && c.localPort > 0 {
-	// 	localAddr, err = sctp.ResolveSCTPAddr("sctp", fmt.Sprintf("%s:%d", c.localAddr, c.localPort))
-	// 	if err != nil {
-	// 		return fmt.Errorf("resolve local SCTP addr: %w", err)
-	// 	}
-	// }
-
-	conn, err := sctp.DialSCTPExt("sctp", nil, remoteAddr, sctp.InitMsg{
-		NumOstreams:    2,
-		MaxInstreams:   2,
-		MaxAttempts:    2,
-		MaxInitTimeout: 2,
-	})
+	transport, err := NewF1Transport(cfg, c.Logger)
 	if err != nil {
-		return fmt.Errorf("dial SCTP: %w", err)
+		return nil, err
 	}
+	c.transport = transport
+	c.ueWorkers = cfg.F1apWorkers
+	c.queueSize = cfg.F1apQueueSize
+	return c, nil
+}
 
-	events := sctp.SCTP_EVENT_DATA_IO | sctp.SCTP_EVENT_SHUTDOWN | sctp.SCTP_EVENT_ASSOCIATION
-	if err := conn.SubscribeEvents(events); err != nil {
-		return fmt.Errorf("subscribe events: %w", err)
+// Connect establishes the F1 connection to CU-CP and starts the F1AP workers of the connection
+func (c *F1APClient) Connect() error {
+	if err := c.transport.Connect(); err != nil {
+		return err
 	}
-
-	info := &sctp.SndRcvInfo{PPID: 62}
-	if err := conn.SetDefaultSentParam(info); err != nil {
-		return fmt.Errorf("set default sent param: %w", err)
+	workers := newF1apWorkerPool(c.ueWorkers, c.queueSize, c.handlePdu, c.Logger)
+	if old := c.workers.Swap(workers); old != nil {
+		old.stop()
 	}
+	return nil
+}
 
-	if err := conn.SetReadBuffer(8192); err != nil {
-		return fmt.Errorf("set read buffer: %w", err)
+// ueStream returns the SCTP stream of the UE-associated signalling of a UE (TS 38.472 7), derived
+// from its gNB-DU UE F1AP ID. Stream 0 is kept for non UE-associated signalling.
+func (c *F1APClient) ueStream(duUeId int64) uint16 {
+	streams := c.transport.Streams()
+	if streams < 2 {
+		return 0
 	}
-
-	c.Info("Connection configured with PPID=%d", 62)
-
-	c.conn = conn
-	c.Info("SCTP connection established to CU-CP")
-	return nil
+	return uint16(1 + duUeId%int64(streams-1))
 }
 
-// Close closes the SCTP connection
+// Close closes the F1 connection and stops the F1AP workers
 func (c *F1APClient) Close() error {
-	if c.conn != nil {
-		return c.conn.Close()
+	if workers := c.workers.Swap(nil); workers != nil {
+		workers.stop()
 	}
-	return nil
+	return c.transport.Close()
 }
 
-// Send sends F1AP message to CU-CP
+// Send sends a non UE-associated F1AP message to CU-CP on stream 0
 func (c *F1APClient) Send(data []byte) error {
-	if c.conn == nil {
-		return fmt.Errorf("SCTP connection not established")
-	}
+	return c.write(data, 0)
+}
 
-	info := &sctp.SndRcvInfo{
-		PPID:   62,
-		Stream: 0,
-	}
+// SendUe sends a UE-associated F1AP message to CU-CP on the stream of the UE
+func (c *F1APClient) SendUe(data []byte, duUeId int64) error {
+	return c.write(data, c.ueStream(duUeId))
+}
 
-	_, err := c.conn.SCTPWrite(data, info)
-	if err != nil {
-		return fmt.Errorf("SCTP write: %w", err)
-	}
+func (c *F1APClient) write(data []byte, stream uint16) error {
+	c.du.traceF1ap(TRACE_UL, data)
+	c.du.countF1apMessage()
 
-	return nil
+	return c.transport.Write(data, stream)
 }
 
 // ReadLoop reads messages from CU-CP
 func (c *F1APClient) ReadLoop() {
-	buf := make([]byte, 8192)
-
+	// the workers of this connection, a reconnect starts new ones
+	workers := c.workers.Load()
+	if workers == nil {
+		c.Error("F1 connection not established")
+		return
+	}
 	for {
-		// Read using SCTPRead
-		n, info, err := c.conn.SCTPRead(buf)
+		data, err := c.transport.Read()
+		if errors.Is(err, ErrF1PeerRestarted) {
+			workers.resetUeIds()
+			c.du.OnF1PeerRestart()
+			continue
+		}
 		if err != nil {
-			if err == io.EOF || err == io.ErrUnexpectedEOF {
-				c.Error("Connection closed by server")
+			if c.workers.Load() != workers {
+				// closed by Close, not lost
 				return
 			}
-			if err == syscall.EAGAIN || err == syscall.EINTR {
-				continue
+			if err == io.EOF {
+				c.Error("Connection closed by server")
+			} else {
+				c.Error("Read error: %v", err)
 			}
-			c.Error("Read error: %v", err)
+			workers.resetUeIds()
+			c.du.OnF1ConnectionLost()
 			return
 		}
 
-		if info == nil {
-			c.Error("Received nil info")
-			continue
-		}
-
-		if info.PPID != 62 {
-			c.Error("Wrong PPID: %d", info.PPID)
-			continue
+		if err := c.handleMessage(workers, data); err != nil {
+			c.Error("Failed to handle F1AP message: %v", err)
 		}
+	}
+}
 
-		// c.Info("Received %d bytes (PPID=%d, Stream=%d)", n, info.PPID, info.Stream)
-		go c.handleMessage(buf[:n])
+// QueueStats returns the queue depth statistics of the F1AP workers
+func (c *F1APClient) QueueStats() []F1apQueueStats {
+	workers := c.workers.Load()
+	if workers == nil {
+		return nil
 	}
+	return workers.stats()
 }
 
-// handleMessage decodes and handles incoming F1AP messages
-func (c *F1APClient) handleMessage(data []byte) error {
-	c.Info("Handling F1AP message, length: %d", len(data))
+// handleMessage decodes an incoming F1AP message and queues it on the worker of its UE
+func (c *F1APClient) handleMessage(workers *f1apWorkerPool, data []byte) error {
 	pdu, err, _ := f1ap.F1apDecode(data)
 	if err != nil {
-		c.Fatal("Failed to decode F1AP PDU: %v", err)
+		// dropped, the messages of the other UEs go on
 		return fmt.Errorf("decode F1AP PDU: %w", err)
 	}
+	workers.dispatch(data, &pdu)
+	return nil
+}
+
+// handlePdu handles a decoded F1AP message
+func (c *F1APClient) handlePdu(data []byte, pdu *f1ap.F1apPdu) {
+	c.Info("Handling F1AP message, length: %d", len(data))
+	c.du.traceF1ap(TRACE_DL, data)
+	c.du.countF1apMessage()
 
 	switch pdu.Present {
 	case ies.F1apPduSuccessfulOutcome:
@@ -179,10 +188,10 @@ func (c *F1APClient) handleMessage(data []byte) error {
 			}
 		case ies.ProcedureCode_UEContextSetup:
 			c.Info("Received UE Context Setup Response")
-			c.du.HandleUeContextSetupResponse(&pdu)
+			c.du.HandleUeContextSetupResponse(pdu)
 		case ies.ProcedureCode_UEContextModificationRequired:
 			c.Info("Received UE Context Modification Confirm (Handover Response)")
-			if err := c.du.HandleUeContextModificationConfirm(&pdu); err != nil {
+			if err := c.du.HandleUeContextModificationConfirm(pdu); err != nil {
 				c.Error("Failed to handle UE Context Modification Confirm: %v", err)
 			}
 		default:
@@ -192,21 +201,89 @@ func (c *F1APClient) handleMessage(data []byte) error {
 		switch pdu.Message.ProcedureCode.Value {
 		case ies.ProcedureCode_DLRRCMessageTransfer:
 			c.Info("Received DL RRC Message Transfer")
-			if err := c.du.HandleDlRrcMessageTransfer(&pdu); err != nil {
+			if err := c.du.HandleDlRrcMessageTransfer(pdu); err != nil {
 				c.Error("Failed to handle DL RRC Message Transfer: %v", err)
 			}
 		case ies.ProcedureCode_UEContextSetup:
 			c.Info("Received UE Context Setup Request")
-			if err := c.du.HandleUeContextSetupRequest(&pdu); err != nil {
+			if err := c.du.HandleUeContextSetupRequest(pdu); err != nil {
 				c.Error("Failed to handle UE Context Setup Request: %v", err)
 			}
+		case ies.ProcedureCode_ResourceStatusReportingInitiation:
+			c.Info("Received Resource Status Request")
+			if err := c.du.HandleResourceStatusRequest(pdu); err != nil {
+				c.Error("Failed to handle Resource Status Request: %v", err)
+			}
+		case ies.ProcedureCode_TraceStart:
+			c.Info("Received Trace Start")
+			if err := c.du.HandleTraceStart(pdu); err != nil {
+				c.Error("Failed to handle Trace Start: %v", err)
+			}
+		case ies.ProcedureCode_DeactivateTrace:
+			c.Info("Received Deactivate Trace")
+			if err := c.du.HandleDeactivateTrace(pdu); err != nil {
+				c.Error("Failed to handle Deactivate Trace: %v", err)
+			}
+		case ies.ProcedureCode_PositioningInformationExchange:
+			c.Info("Received Positioning Information Request")
+			if err := c.du.HandlePositioningInformationRequest(pdu); err != nil {
+				c.Error("Failed to handle Positioning Information Request: %v", err)
+			}
+		case ies.ProcedureCode_PositioningActivation:
+			c.Info("Received Positioning Activation Request")
+			if err := c.du.HandlePositioningActivationRequest(pdu); err != nil {
+				c.Error("Failed to handle Positioning Activation Request: %v", err)
+			}
+		case ies.ProcedureCode_PositioningDeactivation:
+			c.Info("Received Positioning Deactivation")
+			if err := c.du.HandlePositioningDeactivation(pdu); err != nil {
+				c.Error("Failed to handle Positioning Deactivation: %v", err)
+			}
+		case ies.ProcedureCode_PositioningMeasurementExchange:
+			c.Info("Received Positioning Measurement Request")
+			if err := c.du.HandlePositioningMeasurementRequest(pdu); err != nil {
+				c.Error("Failed to handle Positioning Measurement Request: %v", err)
+			}
+		case ies.ProcedureCode_PositioningMeasurementAbort:
+			c.Info("Received Positioning Measurement Abort")
+			if err := c.du.HandlePositioningMeasurementAbort(pdu); err != nil {
+				c.Error("Failed to handle Positioning Measurement Abort: %v", err)
+			}
+		case ies.ProcedureCode_TRPInformationExchange:
+			c.Info("Received TRP Information Request")
+			if err := c.du.HandleTrpInformationRequest(pdu); err != nil {
+				c.Error("Failed to handle TRP Information Request: %v", err)
+			}
 		default:
 			c.Info("Received initiating message %d", pdu.Message.ProcedureCode.Value)
 		}
 	case ies.F1apPduUnsuccessfulOutcome:
-		c.Warn("Received unsuccessful outcome %d", pdu.Message.ProcedureCode.Value)
+		switch pdu.Message.ProcedureCode.Value {
+		case ies.ProcedureCode_F1Setup:
+			c.Warn("Received F1 Setup Failure")
+			if failure, ok := pdu.Message.Msg.(*ies.F1SetupFailure); ok {
+				c.handleF1SetupFailure(failure)
+			}
+		default:
+			c.Warn("Received unsuccessful outcome %d", pdu.Message.ProcedureCode.Value)
+		}
 	}
+}
 
+// sendUe sends a UE-associated F1AP message to CU-CP, on the stream of the UE if the F1 client
+// has streams
+func (du *DU) sendUe(data []byte, duUeId int64) error {
+	if c, ok := du.f1Client.(F1UeClient); ok {
+		return c.SendUe(data, duUeId)
+	}
+	return du.f1Client.Send(data)
+}
+
+// F1apQueueStats returns the queue statistics of the F1AP workers, nil when the F1 client has none
+func (du *DU) F1apQueueStats() []F1apQueueStats {
+	if c, ok := du.f1Client.(F1QueueStatsClient); ok {
+		return c.QueueStats()
+	}
 	return nil
 }
 
@@ -217,6 +294,43 @@ func (c *F1APClient) handleF1SetupResponse(response *ies.F1SetupResponse) {
 	c.du.OnF1SetupResponse()
 }
 
+// handleF1SetupFailure processes F1 Setup Failure, F1 Setup is retried after its TimeToWait
+func (c *F1APClient) handleF1SetupFailure(failure *ies.F1SetupFailure) {
+	var timeToWait time.Duration
+	if failure.TimeToWait != nil {
+		timeToWait = timeToWaitDuration(failure.TimeToWait.Value)
+	}
+	c.Warn("F1 Setup Failure received %d, time to wait %v", failure.TransactionID, timeToWait)
+	c.du.OnF1SetupFailure(timeToWait)
+}
+
+// timeToWaitDuration returns the duration of a TimeToWait value
+func timeToWaitDuration(value aper.Enumerated) time.Duration {
+	switch value {
+	case ies.TimeToWaitV1S:
+		return time.Second
+	case ies.TimeToWaitV2S:
+		return 2 * time.Second
+	case ies.TimeToWaitV5S:
+		return 5 * time.Second
+	case ies.TimeToWaitV10S:
+		return 10 * time.Second
+	case ies.TimeToWaitV20S:
+		return 20 * time.Second
+	default:
+		return 60 * time.Second
+	}
+}
+
+// servedCellNRCGI returns the NR CGI of the served cell, as announced in F1 Setup and reported by
+// the other cell level procedures
+func (du *DU) servedCellNRCGI() ies.NRCGI {
+	return ies.NRCGI{
+		PLMNIdentity:   []byte{152, 249, 225}, // 99970
+		NRCellIdentity: aper.BitString{Bytes: []byte{0x0, 0x0, 0x01, 0x0, 0x0}, NumBits: 36},
+	}
+}
+
 // FIX: there are many fixed value
 // SendF1SetupRequest encodes and sends F1 Setup Request
 func (c *F1APClient) SendF1SetupRequest() error {
@@ -233,14 +347,12 @@ func (c *F1APClient) SendF1SetupRequest() error {
 		},
 	}
 
-	tac := []byte{0x0, 0x0, 0x01}
+	tacValue, _ := cfg.Cell.TACValue() // checked by config validation
+	tac := []byte{byte(tacValue >> 16), byte(tacValue >> 8), byte(tacValue)}
 
 	// Create served cell information
 	servedCellInfo := ies.ServedCellInformation{
-		NRCGI: ies.NRCGI{
-			PLMNIdentity:   []byte{152, 249, 225}, // 99970
-			NRCellIdentity: aper.BitString{Bytes: []byte{0x0, 0x0, 0x01, 0x0, 0x0}, NumBits: 36},
-		},
+		NRCGI: c.du.servedCellNRCGI(),
 		NRPCI: ies.NRPCI{
 			Value: int64(cfg.Cell.PCI),
 		},
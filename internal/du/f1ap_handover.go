@@ -30,6 +30,7 @@ func (du *DU) HandleUeContextModificationRequest(f1apPdu *f1ap.F1apPdu) error {
 		du.Info("Contains RRC Reconfiguration for Handover, forwarding to UE")
 		// Forward RRC Reconfiguration to UE
 		if du.ue != nil && du.ue.SendToUeChannel != nil {
+			du.traceRrc(TRACE_DL, msg.RRCContainer)
 			du.ue.SendToUeChannel <- msg.RRCContainer
 		} else {
 			return fmt.Errorf("UE channel not initialized")
@@ -66,7 +67,7 @@ func (du *DU) sendUeContextModificationResponse(cuUeId, duUeId int64) error {
 
 	// Send only if f1Client is available (for testing)
 	if du.f1Client != nil {
-		return du.f1Client.Send(f1apBytes)
+		return du.sendUe(f1apBytes, duUeId)
 	}
 
 	du.Info("F1 client not available, skipping send (test mode)")
@@ -93,8 +94,19 @@ func (du *DU) HandleUeContextReleaseCommand(f1apPdu *f1ap.F1apPdu) error {
 
 	// Release UE context and resources
 	du.Info("Releasing UE context and resources")
+	du.deactivateTrace()
+	du.releaseDrbs(du.ue)
 	// TODO: Actual resource release logic
 
+	// RRCRelease for the UE
+	if len(msg.RRCContainer) > 0 && du.ue != nil && du.ue.SendToUeChannel != nil {
+		du.Info("Forwarding RRCRelease to UE, length: %d", len(msg.RRCContainer))
+		du.ue.rrcReleased.Store(true)
+		du.ue.rrcConnected.Store(false)
+		du.traceRrc(TRACE_DL, msg.RRCContainer)
+		du.ue.SendToUeChannel <- msg.RRCContainer
+	}
+
 	// Send UE Context Release Complete
 	return du.sendUeContextReleaseComplete(msg.GNBCUUEF1APID, msg.GNBDUUEF1APID)
 }
@@ -115,7 +127,7 @@ func (du *DU) sendUeContextReleaseComplete(cuUeId, duUeId int64) error {
 
 	// Send only if f1Client is available (for testing)
 	if du.f1Client != nil {
-		return du.f1Client.Send(f1apBytes)
+		return du.sendUe(f1apBytes, duUeId)
 	}
 
 	du.Info("F1 client not available, skipping send (test mode)")
@@ -178,7 +190,7 @@ func (du *DU) sendUeContextModificationRequired(targetPci int64) error {
 
 	// Send
 	if du.f1Client != nil {
-		return du.f1Client.Send(f1apBytes)
+		return du.sendUe(f1apBytes, fixedDuUeId)
 	}
 
 	du.Info("F1 client not available, skipping send (test mode)")
@@ -210,6 +222,7 @@ func (du *DU) HandleUeContextModificationConfirm(f1apPdu *f1ap.F1apPdu) error {
 		du.Info("Received RRC Container (Handover Command), forwarding to UE")
 
 		if du.ue != nil && du.ue.SendToUeChannel != nil {
+			du.traceRrc(TRACE_DL, msg.RRCContainer)
 			du.ue.SendToUeChannel <- msg.RRCContainer
 			du.Info("Handover Command forwarded to UE")
 		} else {
@@ -0,0 +1,968 @@
+package du
+
+import (
+	"bytes"
+	"du_ue/pkg/config"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+// positioningMeasurement is one periodic positioning measurement requested by LMF
+type positioningMeasurement struct {
+	transId    int64
+	lmfMeasId  int64
+	ranMeasId  int64
+	trpIds     []int64
+	quantities []aper.Enumerated
+	period     time.Duration
+	stop       chan struct{}
+}
+
+// PositioningContext keeps the SRS state and the ongoing positioning measurements
+type PositioningContext struct {
+	model        *PositioningModel
+	measurements map[int64]*positioningMeasurement // key: LMF Measurement ID
+	srsActive    bool
+	mutex        sync.Mutex
+}
+
+func (du *DU) InitPositioningContext() {
+	du.posCtx = &PositioningContext{
+		model:        NewPositioningModel(du.Config.Positioning),
+		measurements: make(map[int64]*positioningMeasurement),
+	}
+}
+
+// HandlePositioningInformationRequest handles Positioning Information Request from CU-CP
+func (du *DU) HandlePositioningInformationRequest(f1apPdu *f1ap.F1apPdu) error {
+	if f1apPdu.Present != ies.F1apPduInitiatingMessage {
+		du.Error("Invalid F1AP PDU present type for Positioning Information Request")
+		return fmt.Errorf("invalid PDU type")
+	}
+
+	msg, ok := f1apPdu.Message.Msg.(*ies.PositioningInformationRequest)
+	if !ok {
+		du.Error("Failed to cast message to PositioningInformationRequest")
+		return fmt.Errorf("invalid message type")
+	}
+
+	du.Info("Positioning Information Request: CU-UE-ID=%d, DU-UE-ID=%d", msg.GNBCUUEF1APID, msg.GNBDUUEF1APID)
+
+	if du.ue == nil || msg.GNBDUUEF1APID != DU_UE_F1AP_ID {
+		du.Warn("Unknown UE for Positioning Information Request")
+		return du.sendPositioningInformationFailure(msg.GNBCUUEF1APID, msg.GNBDUUEF1APID,
+			ies.CauseRadioNetworkUnknownoralreadyallocatedgnbduuef1Apid)
+	}
+
+	return du.sendPositioningInformationResponse(msg.GNBCUUEF1APID, msg.GNBDUUEF1APID)
+}
+
+// HandlePositioningActivationRequest handles Positioning Activation Request from CU-CP
+func (du *DU) HandlePositioningActivationRequest(f1apPdu *f1ap.F1apPdu) error {
+	if f1apPdu.Present != ies.F1apPduInitiatingMessage {
+		du.Error("Invalid F1AP PDU present type for Positioning Activation Request")
+		return fmt.Errorf("invalid PDU type")
+	}
+
+	msg, ok := f1apPdu.Message.Msg.(*ies.PositioningActivationRequest)
+	if !ok {
+		du.Error("Failed to cast message to PositioningActivationRequest")
+		return fmt.Errorf("invalid message type")
+	}
+
+	du.Info("Positioning Activation Request: CU-UE-ID=%d, DU-UE-ID=%d, SRS-Type=%d",
+		msg.GNBCUUEF1APID, msg.GNBDUUEF1APID, msg.SRSType.Choice)
+
+	if du.ue == nil || msg.GNBDUUEF1APID != DU_UE_F1AP_ID {
+		du.Warn("Unknown UE for Positioning Activation Request")
+		return du.sendPositioningActivationFailure(msg.GNBCUUEF1APID, msg.GNBDUUEF1APID,
+			ies.CauseRadioNetworkUnknownoralreadyallocatedgnbduuef1Apid)
+	}
+
+	du.posCtx.mutex.Lock()
+	du.posCtx.srsActive = true
+	du.posCtx.mutex.Unlock()
+
+	return du.sendPositioningActivationResponse(msg.GNBCUUEF1APID, msg.GNBDUUEF1APID)
+}
+
+// HandlePositioningDeactivation handles Positioning Deactivation from CU-CP
+func (du *DU) HandlePositioningDeactivation(f1apPdu *f1ap.F1apPdu) error {
+	if f1apPdu.Present != ies.F1apPduInitiatingMessage {
+		du.Error("Invalid F1AP PDU present type for Positioning Deactivation")
+		return fmt.Errorf("invalid PDU type")
+	}
+
+	msg, ok := f1apPdu.Message.Msg.(*ies.PositioningDeactivation)
+	if !ok {
+		du.Error("Failed to cast message to PositioningDeactivation")
+		return fmt.Errorf("invalid message type")
+	}
+
+	du.Info("Positioning Deactivation: CU-UE-ID=%d, DU-UE-ID=%d", msg.GNBCUUEF1APID, msg.GNBDUUEF1APID)
+
+	du.posCtx.mutex.Lock()
+	du.posCtx.srsActive = false
+	du.posCtx.mutex.Unlock()
+	return nil
+}
+
+// HandlePositioningMeasurementRequest handles Positioning Measurement Request from CU-CP
+func (du *DU) HandlePositioningMeasurementRequest(f1apPdu *f1ap.F1apPdu) error {
+	if f1apPdu.Present != ies.F1apPduInitiatingMessage {
+		du.Error("Invalid F1AP PDU present type for Positioning Measurement Request")
+		return fmt.Errorf("invalid PDU type")
+	}
+
+	msg, ok := f1apPdu.Message.Msg.(*ies.PositioningMeasurementRequest)
+	if !ok {
+		du.Error("Failed to cast message to PositioningMeasurementRequest")
+		return fmt.Errorf("invalid message type")
+	}
+
+	du.Info("Positioning Measurement Request: LMF-Meas-ID=%d, RAN-Meas-ID=%d, TRPs=%d, Quantities=%d",
+		msg.LMFMeasurementID, msg.RANMeasurementID, len(msg.TRPMeasurementRequestList), len(msg.PosMeasurementQuantities))
+
+	meas := &positioningMeasurement{
+		transId:   msg.TransactionID,
+		lmfMeasId: msg.LMFMeasurementID,
+		ranMeasId: msg.RANMeasurementID,
+	}
+	for _, item := range msg.TRPMeasurementRequestList {
+		if _, found := du.posCtx.model.TRP(item.TRPID); found {
+			meas.trpIds = append(meas.trpIds, item.TRPID)
+		} else {
+			du.Warn("TRP %d is not configured, skipping", item.TRPID)
+		}
+	}
+	for _, item := range msg.PosMeasurementQuantities {
+		meas.quantities = append(meas.quantities, item.PosMeasurementType.Value)
+	}
+
+	if len(meas.trpIds) == 0 {
+		return du.sendPositioningMeasurementFailure(meas, ies.CauseRadioNetworkMeasurementnotsupportedfortheobject)
+	}
+
+	periodic := msg.PosReportCharacteristics != nil &&
+		msg.PosReportCharacteristics.Value == ies.PosReportCharacteristicsPeriodic
+	if periodic {
+		du.posCtx.mutex.Lock()
+		if _, exists := du.posCtx.measurements[meas.lmfMeasId]; exists {
+			du.posCtx.mutex.Unlock()
+			return du.sendPositioningMeasurementFailure(meas, ies.CauseRadioNetworkExistingmeasurementid)
+		}
+		meas.period = posMeasurementPeriod(msg.PosMeasurementPeriodicity)
+		meas.stop = make(chan struct{})
+		du.posCtx.measurements[meas.lmfMeasId] = meas
+		du.posCtx.mutex.Unlock()
+	}
+
+	if err := du.sendPositioningMeasurementResponse(meas); err != nil {
+		return err
+	}
+
+	if periodic {
+		du.Info("Starting periodic positioning measurement %d every %v", meas.lmfMeasId, meas.period)
+		go du.runPositioningMeasurementReport(meas)
+	}
+	return nil
+}
+
+// HandlePositioningMeasurementAbort handles Positioning Measurement Abort from CU-CP
+func (du *DU) HandlePositioningMeasurementAbort(f1apPdu *f1ap.F1apPdu) error {
+	if f1apPdu.Present != ies.F1apPduInitiatingMessage {
+		du.Error("Invalid F1AP PDU present type for Positioning Measurement Abort")
+		return fmt.Errorf("invalid PDU type")
+	}
+
+	msg, ok := f1apPdu.Message.Msg.(*ies.PositioningMeasurementAbort)
+	if !ok {
+		du.Error("Failed to cast message to PositioningMeasurementAbort")
+		return fmt.Errorf("invalid message type")
+	}
+
+	du.Info("Positioning Measurement Abort: LMF-Meas-ID=%d, RAN-Meas-ID=%d", msg.LMFMeasurementID, msg.RANMeasurementID)
+
+	du.posCtx.mutex.Lock()
+	defer du.posCtx.mutex.Unlock()
+
+	meas, exists := du.posCtx.measurements[msg.LMFMeasurementID]
+	if !exists {
+		du.Warn("Unknown positioning measurement %d", msg.LMFMeasurementID)
+		return nil
+	}
+	close(meas.stop)
+	delete(du.posCtx.measurements, msg.LMFMeasurementID)
+	return nil
+}
+
+// HandleTrpInformationRequest handles TRP Information Request from CU-CP
+func (du *DU) HandleTrpInformationRequest(f1apPdu *f1ap.F1apPdu) error {
+	if f1apPdu.Present != ies.F1apPduInitiatingMessage {
+		du.Error("Invalid F1AP PDU present type for TRP Information Request")
+		return fmt.Errorf("invalid PDU type")
+	}
+
+	msg, ok := f1apPdu.Message.Msg.(*ies.TRPInformationRequest)
+	if !ok {
+		du.Error("Failed to cast message to TRPInformationRequest")
+		return fmt.Errorf("invalid message type")
+	}
+
+	du.Info("TRP Information Request: TransactionID=%d, TRPs=%d", msg.TransactionID, len(msg.TRPList))
+
+	// an absent TRP list requests all TRPs of the DU
+	var trps []config.TRPConfig
+	if len(msg.TRPList) == 0 {
+		trps = du.posCtx.model.TRPs()
+	}
+	for _, item := range msg.TRPList {
+		if trp, found := du.posCtx.model.TRP(item.TRPID); found {
+			trps = append(trps, trp)
+		} else {
+			du.Warn("TRP %d is not configured, skipping", item.TRPID)
+		}
+	}
+
+	if len(trps) == 0 {
+		return du.sendTrpInformationFailure(msg.TransactionID, ies.CauseRadioNetworkUnspecified)
+	}
+	return du.sendTrpInformationResponse(msg.TransactionID, trps)
+}
+
+// StopPositioningMeasurements stops all periodic positioning measurements
+func (du *DU) StopPositioningMeasurements() {
+	if du.posCtx == nil {
+		return
+	}
+
+	du.posCtx.mutex.Lock()
+	defer du.posCtx.mutex.Unlock()
+
+	for id, meas := range du.posCtx.measurements {
+		close(meas.stop)
+		delete(du.posCtx.measurements, id)
+	}
+}
+
+func (du *DU) runPositioningMeasurementReport(meas *positioningMeasurement) {
+	ticker := time.NewTicker(meas.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-meas.stop:
+			return
+		case <-ticker.C:
+			if err := du.sendPositioningMeasurementReport(meas); err != nil {
+				du.Error("Failed to send Positioning Measurement Report: %v", err)
+			}
+		}
+	}
+}
+
+// positioningResults computes the requested measurement quantities of every TRP of a measurement
+func (du *DU) positioningResults(meas *positioningMeasurement) []ies.PosMeasurementResultListItem {
+	now := time.Now()
+	sfn, slot := du.posCtx.model.SFNAndSlot(now)
+	timeStamp := ies.TimeStamp{
+		SystemFrameNumber: sfn,
+		SlotIndex: ies.TimeStampSlotIndex{
+			Choice: ies.TimeStampSlotIndexPresentSCS30,
+			SCS30:  &slot,
+		},
+	}
+
+	var results []ies.PosMeasurementResultListItem
+	for _, trpId := range meas.trpIds {
+		trp, found := du.posCtx.model.TRP(trpId)
+		if !found {
+			continue
+		}
+		m := du.posCtx.model.Measure(trp, now)
+		du.Debug("TRP %d: distance=%.1fm, RTOA=%d, Rx-Tx=%d, AoA=%d/%d, RSRP=%d",
+			trpId, m.Distance, m.RTOA, m.GNBRxTx, m.AzimuthAoA, m.ZenithAoA, m.SRSRSRP)
+
+		var items []ies.PosMeasurementResultItem
+		for _, quantity := range meas.quantities {
+			value, ok := measuredResultsValue(quantity, m)
+			if !ok {
+				du.Warn("Unsupported positioning measurement type %d", quantity)
+				continue
+			}
+			items = append(items, ies.PosMeasurementResultItem{
+				MeasuredResultsValue: value,
+				TimeStamp:            timeStamp,
+			})
+		}
+		if len(items) > 0 {
+			results = append(results, ies.PosMeasurementResultListItem{
+				PosMeasurementResult: items,
+				TRPID:                trpId,
+			})
+		}
+	}
+	return results
+}
+
+func measuredResultsValue(quantity aper.Enumerated, m TRPMeasurement) (ies.MeasuredResultsValue, bool) {
+	switch quantity {
+	case ies.PosMeasurementTypeGnbrxtx:
+		return ies.MeasuredResultsValue{
+			Choice: ies.MeasuredResultsValuePresentGNBRxTxTimeDiff,
+			GNBRxTxTimeDiff: &ies.GNBRxTxTimeDiff{
+				RxTxTimeDiff: ies.GNBRxTxTimeDiffMeas{
+					Choice: ies.GNBRxTxTimeDiffMeasPresentK0,
+					K0:     &m.GNBRxTx,
+				},
+			},
+		}, true
+	case ies.PosMeasurementTypeUlsrsrsrp:
+		return ies.MeasuredResultsValue{
+			Choice:    ies.MeasuredResultsValuePresentULSRSRSRP,
+			ULSRSRSRP: &m.SRSRSRP,
+		}, true
+	case ies.PosMeasurementTypeUlaoa:
+		zenith := m.ZenithAoA
+		return ies.MeasuredResultsValue{
+			Choice:           ies.MeasuredResultsValuePresentULAngleOfArrival,
+			ULAngleOfArrival: &ies.ULAoA{AzimuthAoA: m.AzimuthAoA, ZenithAoA: &zenith},
+		}, true
+	case ies.PosMeasurementTypeUlrtoa:
+		return ies.MeasuredResultsValue{
+			Choice: ies.MeasuredResultsValuePresentULRTOA,
+			ULRTOA: &ies.ULRTOAMeasurement{
+				ULRTOAMeasurementItem: ies.ULRTOAMeasurementItem{
+					Choice: ies.ULRTOAMeasurementItemPresentK0,
+					K0:     &m.RTOA,
+				},
+			},
+		}, true
+	}
+	return ies.MeasuredResultsValue{}, false
+}
+
+// sendPositioningInformationResponse sends Positioning Information Response to CU-CP
+func (du *DU) sendPositioningInformationResponse(cuUeId, duUeId int64) error {
+	du.Info("Sending Positioning Information Response")
+
+	pci := ies.NRPCI{Value: int64(du.Config.Cell.PCI)}
+	msg := &ies.PositioningInformationResponse{
+		GNBCUUEF1APID: cuUeId,
+		GNBDUUEF1APID: duUeId,
+		SRSConfiguration: &ies.SRSConfiguration{
+			SRSCarrierList: []ies.SRSCarrierListItem{{
+				PointA: 633333, // NR-ARFCN of 3.5 GHz
+				UplinkChannelBWPerSCSList: []ies.SCSSpecificCarrier{{
+					OffsetToCarrier:   0,
+					SubcarrierSpacing: ies.SubcarrierSpacingSCS{Value: ies.SubcarrierSpacingSCSKHz30},
+					CarrierBandwidth:  106,
+				}},
+				ActiveULBWP: ies.ActiveULBWP{
+					LocationAndBandwidth:    1099, // 106 PRBs starting at PRB 0
+					SubcarrierSpacing:       ies.SubcarrierSpacing{Value: ies.SubcarrierSpacingKHz30},
+					CyclicPrefix:            ies.CyclicPrefix{Value: ies.CyclicPrefixNormal},
+					TxDirectCurrentLocation: 3300,
+				},
+				Pci: &pci,
+			}},
+		},
+		SFNInitialisationTime: du.posCtx.model.SFNInitialisationTime(),
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Positioning Information Response: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.sendUe(f1apBytes, duUeId)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendPositioningInformationFailure sends Positioning Information Failure to CU-CP
+func (du *DU) sendPositioningInformationFailure(cuUeId, duUeId int64, cause aper.Enumerated) error {
+	du.Warn("Sending Positioning Information Failure")
+
+	msg := &ies.PositioningInformationFailure{
+		GNBCUUEF1APID: cuUeId,
+		GNBDUUEF1APID: duUeId,
+		Cause:         radioNetworkCause(cause),
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Positioning Information Failure: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.sendUe(f1apBytes, duUeId)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendPositioningActivationResponse sends Positioning Activation Response to CU-CP
+func (du *DU) sendPositioningActivationResponse(cuUeId, duUeId int64) error {
+	du.Info("Sending Positioning Activation Response")
+
+	sfn, slot := du.posCtx.model.SFNAndSlot(time.Now())
+	msg := &ies.PositioningActivationResponse{
+		GNBCUUEF1APID:     cuUeId,
+		GNBDUUEF1APID:     duUeId,
+		SystemFrameNumber: &sfn,
+		SlotNumber:        &slot,
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(positioningActivationResponse{msg})
+	if err != nil {
+		return fmt.Errorf("encode Positioning Activation Response: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.sendUe(f1apBytes, duUeId)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendPositioningActivationFailure sends Positioning Activation Failure to CU-CP
+func (du *DU) sendPositioningActivationFailure(cuUeId, duUeId int64, cause aper.Enumerated) error {
+	du.Warn("Sending Positioning Activation Failure")
+
+	msg := &ies.PositioningActivationFailure{
+		GNBCUUEF1APID: cuUeId,
+		GNBDUUEF1APID: duUeId,
+		Cause:         radioNetworkCause(cause),
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Positioning Activation Failure: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.sendUe(f1apBytes, duUeId)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendPositioningMeasurementResponse sends Positioning Measurement Response with the first results
+func (du *DU) sendPositioningMeasurementResponse(meas *positioningMeasurement) error {
+	du.Info("Sending Positioning Measurement Response")
+
+	msg := positioningMeasurementResults{
+		present:     ies.F1apPduSuccessfulOutcome,
+		procedure:   ies.ProcedureCode_PositioningMeasurementExchange,
+		criticality: ies.Criticality_PresentReject,
+		meas:        meas,
+		results:     du.positioningResults(meas),
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Positioning Measurement Response: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.f1Client.Send(f1apBytes)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendPositioningMeasurementFailure sends Positioning Measurement Failure to CU-CP
+func (du *DU) sendPositioningMeasurementFailure(meas *positioningMeasurement, cause aper.Enumerated) error {
+	du.Warn("Sending Positioning Measurement Failure")
+
+	msg := &ies.PositioningMeasurementFailure{
+		TransactionID:    meas.transId,
+		LMFMeasurementID: meas.lmfMeasId,
+		RANMeasurementID: meas.ranMeasId,
+		Cause:            radioNetworkCause(cause),
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Positioning Measurement Failure: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.f1Client.Send(f1apBytes)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendPositioningMeasurementReport sends one periodic Positioning Measurement Report
+func (du *DU) sendPositioningMeasurementReport(meas *positioningMeasurement) error {
+	results := du.positioningResults(meas)
+	if len(results) == 0 {
+		return nil
+	}
+
+	msg := positioningMeasurementResults{
+		present:     ies.F1apPduInitiatingMessage,
+		procedure:   ies.ProcedureCode_PositioningMeasurementReport,
+		criticality: ies.Criticality_PresentIgnore,
+		meas:        meas,
+		results:     results,
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Positioning Measurement Report: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.f1Client.Send(f1apBytes)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendTrpInformationResponse sends TRP Information Response to CU-CP
+func (du *DU) sendTrpInformationResponse(transId int64, trps []config.TRPConfig) error {
+	du.Info("Sending TRP Information Response with %d TRPs", len(trps))
+
+	var infoList []ies.TRPInformationItem
+	positions := make(map[int64]ies.AccessPointPosition)
+	for _, trp := range trps {
+		pci := int64(du.Config.Cell.PCI)
+		cgi := du.servedCellNRCGI()
+		infoList = append(infoList, ies.TRPInformationItem{
+			TRPInformation: ies.TRPInformation{
+				TRPID: trp.ID,
+				TRPInformationTypeResponseList: []ies.TRPInformationTypeResponseItem{
+					{Choice: ies.TRPInformationTypeResponseItemPresentPCINR, PCINR: &pci},
+					{Choice: ies.TRPInformationTypeResponseItemPresentNGRANCGI, NGRANCGI: &cgi},
+				},
+			},
+		})
+		positions[trp.ID] = du.posCtx.model.TRPPosition(trp)
+	}
+
+	msg := &ies.TRPInformationResponse{
+		TransactionID:             transId,
+		TRPInformationListTRPResp: infoList,
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(trpInformationResponse{msg, positions})
+	if err != nil {
+		return fmt.Errorf("encode TRP Information Response: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.f1Client.Send(f1apBytes)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendTrpInformationFailure sends TRP Information Failure to CU-CP
+func (du *DU) sendTrpInformationFailure(transId int64, cause aper.Enumerated) error {
+	du.Warn("Sending TRP Information Failure")
+
+	msg := &ies.TRPInformationFailure{
+		TransactionID: transId,
+		Cause:         radioNetworkCause(cause),
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode TRP Information Failure: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.f1Client.Send(f1apBytes)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// positioningActivationResponse provides the Encode method that f1-gen does not generate
+// for PositioningActivationResponse
+type positioningActivationResponse struct {
+	*ies.PositioningActivationResponse
+}
+
+func (msg positioningActivationResponse) Encode(w io.Writer) (err error) {
+	integerIe := func(id int64, criticality aper.Enumerated, value int64, ub int64) ies.F1apMessageIE {
+		v := ies.NewINTEGER(value, aper.Constraint{Lb: 0, Ub: ub}, false)
+		return ies.F1apMessageIE{
+			Id:          ies.ProtocolIEID{Value: aper.Integer(id)},
+			Criticality: ies.Criticality{Value: criticality},
+			Value:       &v,
+		}
+	}
+
+	ieList := []ies.F1apMessageIE{
+		integerIe(ies.ProtocolIEID_GNBCUUEF1APID, ies.Criticality_PresentReject, msg.GNBCUUEF1APID, 4294967295),
+		integerIe(ies.ProtocolIEID_GNBDUUEF1APID, ies.Criticality_PresentReject, msg.GNBDUUEF1APID, 4294967295),
+	}
+	if msg.SystemFrameNumber != nil {
+		ieList = append(ieList, integerIe(ies.ProtocolIEID_SystemFrameNumber, ies.Criticality_PresentIgnore, *msg.SystemFrameNumber, 1023))
+	}
+	if msg.SlotNumber != nil {
+		ieList = append(ieList, integerIe(ies.ProtocolIEID_SlotNumber, ies.Criticality_PresentIgnore, *msg.SlotNumber, 79))
+	}
+
+	return encodeSuccessfulOutcome(w, ies.ProcedureCode_PositioningActivation, ieList)
+}
+
+// trpInformationResponse encodes TRP Information Response with the geographical coordinates of
+// each TRP, which f1-gen cannot encode: its aper integer encoder sizes the longitude from the value
+// instead of the offset to the negative lower bound, and TRPPositionDirect and AccessPointPosition
+// lack their extension and optional bits
+type trpInformationResponse struct {
+	*ies.TRPInformationResponse
+	positions map[int64]ies.AccessPointPosition // by TRP ID
+}
+
+func (msg trpInformationResponse) Encode(w io.Writer) error {
+	transactionId := ies.NewINTEGER(msg.TransactionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	list := &trpInformationList{positions: msg.positions}
+	for i := range msg.TRPInformationListTRPResp {
+		list.items = append(list.items, &msg.TRPInformationListTRPResp[i])
+	}
+	ieList := []ies.F1apMessageIE{
+		{
+			Id:          ies.ProtocolIEID{Value: aper.Integer(ies.ProtocolIEID_TransactionID)},
+			Criticality: ies.Criticality{Value: ies.Criticality_PresentReject},
+			Value:       &transactionId,
+		},
+		{
+			Id:          ies.ProtocolIEID{Value: aper.Integer(ies.ProtocolIEID_TRPInformationListTRPResp)},
+			Criticality: ies.Criticality{Value: ies.Criticality_PresentIgnore},
+			Value:       list,
+		},
+	}
+	return encodeSuccessfulOutcome(w, ies.ProcedureCode_TRPInformationExchange, ieList)
+}
+
+// trpInformationList is the TRP information list of TRP Information Response, the geographical
+// coordinates of a TRP are appended to its information types
+type trpInformationList struct {
+	items     []*ies.TRPInformationItem
+	positions map[int64]ies.AccessPointPosition
+}
+
+func (l *trpInformationList) Encode(w *aper.AperWriter) error {
+	var items []aper.AperMarshaller
+	for _, item := range l.items {
+		items = append(items, trpInformationItem{item, l.positions})
+	}
+	return aper.WriteSequenceOf(items, w, &aper.Constraint{Lb: 1, Ub: 65535}, true)
+}
+
+func (l *trpInformationList) Decode(r *aper.AperReader) error {
+	return fmt.Errorf("TRP information list decoding not supported")
+}
+
+type trpInformationItem struct {
+	*ies.TRPInformationItem
+	positions map[int64]ies.AccessPointPosition
+}
+
+func (item trpInformationItem) Encode(w *aper.AperWriter) (err error) {
+	info := item.TRPInformation
+	// TRPInformationItem and TRPInformation: extension bit and absent iE-Extensions
+	for i := 0; i < 2; i++ {
+		if err = w.WriteBool(aper.Zero); err != nil {
+			return
+		}
+		if err = w.WriteBits([]byte{0x0}, 1); err != nil {
+			return
+		}
+	}
+	trpId := ies.NewINTEGER(info.TRPID, aper.Constraint{Lb: 0, Ub: 4095}, true)
+	if err = trpId.Encode(w); err != nil {
+		return
+	}
+
+	var types []aper.AperMarshaller
+	for i := range info.TRPInformationTypeResponseList {
+		types = append(types, &info.TRPInformationTypeResponseList[i])
+	}
+	if pos, ok := item.positions[info.TRPID]; ok {
+		types = append(types, geographicalCoordinates(pos))
+	}
+	return aper.WriteSequenceOf(types, w, &aper.Constraint{Lb: 1, Ub: 64}, true)
+}
+
+// geographicalCoordinates is the TRP information type of a TRP position given directly as an access
+// point position (TS 38.473 9.3.1.185)
+type geographicalCoordinates ies.AccessPointPosition
+
+func (pos geographicalCoordinates) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteChoice(ies.TRPInformationTypeResponseItemPresentGeographicalCoordinates, 8, false); err != nil {
+		return
+	}
+	// GeographicalCoordinates: no DL-PRS resource coordinates nor iE-Extensions
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	if err = w.WriteBits([]byte{0x0}, 2); err != nil {
+		return
+	}
+	if err = w.WriteChoice(ies.TRPPositionDefinitionTypePresentDirect, 2, false); err != nil {
+		return
+	}
+	// TRPPositionDirect
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	if err = w.WriteBits([]byte{0x0}, 1); err != nil {
+		return
+	}
+	if err = w.WriteChoice(ies.TRPPositionDirectAccuracyPresentTRPPosition, 2, false); err != nil {
+		return
+	}
+	// AccessPointPosition
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	if err = w.WriteBits([]byte{0x0}, 1); err != nil {
+		return
+	}
+	if err = pos.LatitudeSign.Encode(w); err != nil {
+		return
+	}
+	fields := []struct {
+		value, lb, ub int64
+	}{
+		{pos.Latitude, 0, 8388607},
+		{pos.Longitude, -8388608, 8388607},
+	}
+	for _, f := range fields {
+		// a constrained integer is encoded as its offset to the lower bound
+		v := ies.NewINTEGER(f.value-f.lb, aper.Constraint{Lb: 0, Ub: f.ub - f.lb}, false)
+		if err = v.Encode(w); err != nil {
+			return
+		}
+	}
+	if err = pos.DirectionOfAltitude.Encode(w); err != nil {
+		return
+	}
+	fields = []struct {
+		value, lb, ub int64
+	}{
+		{pos.Altitude, 0, 32767},
+		{pos.UncertaintySemiMajor, 0, 127},
+		{pos.UncertaintySemiMinor, 0, 127},
+		{pos.OrientationOfMajorAxis, 0, 179},
+		{pos.UncertaintyAltitude, 0, 127},
+		{pos.Confidence, 0, 100},
+	}
+	for _, f := range fields {
+		v := ies.NewINTEGER(f.value, aper.Constraint{Lb: f.lb, Ub: f.ub}, false)
+		if err = v.Encode(w); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// positioningMeasurementResults encodes Positioning Measurement Response and Report with the zenith
+// angle of UL-AoA results, which f1-gen cannot encode: its UL-AoA encoder never flags a present
+// zenith angle and lacks the iE-Extensions bit, so the angle would be read as the next fields
+type positioningMeasurementResults struct {
+	present     uint8
+	procedure   int64
+	criticality aper.Enumerated
+	meas        *positioningMeasurement
+	results     []ies.PosMeasurementResultListItem
+}
+
+func (msg positioningMeasurementResults) Encode(w io.Writer) error {
+	integerIe := func(id int64, value int64, c aper.Constraint, ext bool) ies.F1apMessageIE {
+		v := ies.NewINTEGER(value, c, ext)
+		return ies.F1apMessageIE{
+			Id:          ies.ProtocolIEID{Value: aper.Integer(id)},
+			Criticality: ies.Criticality{Value: ies.Criticality_PresentReject},
+			Value:       &v,
+		}
+	}
+
+	ieList := []ies.F1apMessageIE{
+		integerIe(ies.ProtocolIEID_TransactionID, msg.meas.transId, aper.Constraint{Lb: 0, Ub: 255}, false),
+		integerIe(ies.ProtocolIEID_LMFMeasurementID, msg.meas.lmfMeasId, aper.Constraint{Lb: 1, Ub: 65536}, true),
+		integerIe(ies.ProtocolIEID_RANMeasurementID, msg.meas.ranMeasId, aper.Constraint{Lb: 1, Ub: 65536}, true),
+	}
+	if len(msg.results) > 0 {
+		ieList = append(ieList, ies.F1apMessageIE{
+			Id:          ies.ProtocolIEID{Value: aper.Integer(ies.ProtocolIEID_PosMeasurementResultList)},
+			Criticality: ies.Criticality{Value: ies.Criticality_PresentReject},
+			Value:       &posMeasurementResultList{msg.results},
+		})
+	}
+	return encodeF1apMessage(w, msg.present, msg.procedure, msg.criticality, ieList)
+}
+
+// posMeasurementResultList is the positioning measurement result list of each TRP
+type posMeasurementResultList struct {
+	items []ies.PosMeasurementResultListItem
+}
+
+func (l *posMeasurementResultList) Encode(w *aper.AperWriter) error {
+	var items []aper.AperMarshaller
+	for i := range l.items {
+		items = append(items, posMeasurementResultListItem{&l.items[i]})
+	}
+	return aper.WriteSequenceOf(items, w, &aper.Constraint{Lb: 1, Ub: 64}, true)
+}
+
+func (l *posMeasurementResultList) Decode(r *aper.AperReader) error {
+	return fmt.Errorf("positioning measurement result list decoding not supported")
+}
+
+type posMeasurementResultListItem struct {
+	*ies.PosMeasurementResultListItem
+}
+
+func (item posMeasurementResultListItem) Encode(w *aper.AperWriter) (err error) {
+	// extension bit and absent iE-Extensions
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	if err = w.WriteBits([]byte{0x0}, 1); err != nil {
+		return
+	}
+	var results []aper.AperMarshaller
+	for i := range item.PosMeasurementResult {
+		results = append(results, posMeasurementResultItem{&item.PosMeasurementResult[i]})
+	}
+	if err = aper.WriteSequenceOf(results, w, &aper.Constraint{Lb: 1, Ub: 16384}, true); err != nil {
+		return
+	}
+	trpId := ies.NewINTEGER(item.TRPID, aper.Constraint{Lb: 0, Ub: 4095}, true)
+	return trpId.Encode(w)
+}
+
+type posMeasurementResultItem struct {
+	*ies.PosMeasurementResultItem
+}
+
+func (item posMeasurementResultItem) Encode(w *aper.AperWriter) (err error) {
+	// extension bit, no measurement quality, beam information nor iE-Extensions
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	if err = w.WriteBits([]byte{0x0}, 3); err != nil {
+		return
+	}
+	value := item.MeasuredResultsValue
+	if value.Choice == ies.MeasuredResultsValuePresentULAngleOfArrival && value.ULAngleOfArrival != nil {
+		if err = encodeUlAoA(w, value.ULAngleOfArrival); err != nil {
+			return
+		}
+	} else if err = value.Encode(w); err != nil {
+		return
+	}
+	return item.TimeStamp.Encode(w)
+}
+
+// encodeUlAoA encodes a UL-AoA measured result (TS 38.473 9.3.1.168) with its zenith angle
+func encodeUlAoA(w *aper.AperWriter, aoa *ies.ULAoA) (err error) {
+	if err = w.WriteChoice(ies.MeasuredResultsValuePresentULAngleOfArrival, 4, false); err != nil {
+		return
+	}
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	// zenithAoA, angleCoordinateSystem and iE-Extensions presence
+	optionals := byte(0x0)
+	if aoa.ZenithAoA != nil {
+		optionals |= 0x80
+	}
+	if err = w.WriteBits([]byte{optionals}, 3); err != nil {
+		return
+	}
+	azimuth := ies.NewINTEGER(aoa.AzimuthAoA, aper.Constraint{Lb: 0, Ub: 3599}, false)
+	if err = azimuth.Encode(w); err != nil {
+		return
+	}
+	if aoa.ZenithAoA != nil {
+		zenith := ies.NewINTEGER(*aoa.ZenithAoA, aper.Constraint{Lb: 0, Ub: 1799}, false)
+		err = zenith.Encode(w)
+	}
+	return
+}
+
+// encodeSuccessfulOutcome encodes an F1AP successful outcome with its protocol IEs
+func encodeSuccessfulOutcome(w io.Writer, procedure int64, ieList []ies.F1apMessageIE) error {
+	return encodeF1apMessage(w, ies.F1apPduSuccessfulOutcome, procedure, ies.Criticality_PresentReject, ieList)
+}
+
+// encodeF1apMessage encodes an F1AP PDU with its protocol IEs
+func encodeF1apMessage(w io.Writer, present uint8, procedure int64, criticality aper.Enumerated, ieList []ies.F1apMessageIE) (err error) {
+	aw := aper.NewWriter(w)
+	if err = aw.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	if err = aw.WriteChoice(uint64(present), 2, true); err != nil {
+		return
+	}
+	procedureCode := ies.ProcedureCode{Value: aper.Integer(procedure)}
+	if err = procedureCode.Encode(aw); err != nil {
+		return
+	}
+	pduCriticality := ies.Criticality{Value: criticality}
+	if err = pduCriticality.Encode(aw); err != nil {
+		return
+	}
+
+	var buf bytes.Buffer
+	cw := aper.NewWriter(&buf)
+	cw.WriteBool(aper.Zero)
+	if err = aper.WriteSequenceOf[ies.F1apMessageIE](ieList, cw, &aper.Constraint{Lb: 0, Ub: int64(aper.POW_16 - 1)}, false); err != nil {
+		return
+	}
+	if err = cw.Close(); err != nil {
+		return
+	}
+	if err = aw.WriteOpenType(buf.Bytes()); err != nil {
+		return
+	}
+	return aw.Close()
+}
+
+func radioNetworkCause(cause aper.Enumerated) ies.Cause {
+	return ies.Cause{
+		Choice:       ies.CausePresentRadioNetwork,
+		RadioNetwork: &ies.CauseRadioNetwork{Value: cause},
+	}
+}
+
+func posMeasurementPeriod(p *ies.PosMeasurementPeriodicity) time.Duration {
+	if p == nil {
+		return time.Second
+	}
+	periods := []time.Duration{
+		120 * time.Millisecond, 240 * time.Millisecond, 480 * time.Millisecond, 640 * time.Millisecond,
+		1024 * time.Millisecond, 2048 * time.Millisecond, 5120 * time.Millisecond, 10240 * time.Millisecond,
+		time.Minute, 6 * time.Minute, 12 * time.Minute, 30 * time.Minute, 60 * time.Minute,
+	}
+	if *p < 0 || int(*p) >= len(periods) {
+		return time.Second
+	}
+	return periods[*p]
+}
@@ -0,0 +1,357 @@
+package du
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+// Report Characteristics bit positions (TS 38.473 9.3.1.x)
+const (
+	REPORT_PRB_PERIODIC uint = iota
+	REPORT_TNL_CAPACITY_PERIODIC
+	REPORT_COMPOSITE_CAPACITY_PERIODIC
+	REPORT_HW_LOAD_PERIODIC
+	REPORT_NUMBER_OF_ACTIVE_UES
+)
+
+// resourceStatusReport is one measurement requested by CU-CP
+type resourceStatusReport struct {
+	cuMeasId        int64
+	duMeasId        int64
+	characteristics []byte
+	cells           []ies.NRCGI
+	period          time.Duration
+	stop            chan struct{}
+}
+
+// ResourceStatusContext keeps the ongoing resource status measurements
+type ResourceStatusContext struct {
+	model        *LoadModel
+	reports      map[int64]*resourceStatusReport // key: gNB-CU Measurement ID
+	nextDuMeasId int64
+	mutex        sync.Mutex
+}
+
+func (du *DU) InitResourceStatusContext() {
+	du.resCtx = &ResourceStatusContext{
+		model:        NewLoadModel(du.Config.Load),
+		reports:      make(map[int64]*resourceStatusReport),
+		nextDuMeasId: 1,
+	}
+}
+
+// HandleResourceStatusRequest handles Resource Status Request from CU-CP
+func (du *DU) HandleResourceStatusRequest(f1apPdu *f1ap.F1apPdu) error {
+	if f1apPdu.Present != ies.F1apPduInitiatingMessage {
+		du.Error("Invalid F1AP PDU present type for Resource Status Request")
+		return fmt.Errorf("invalid PDU type")
+	}
+
+	msg, ok := f1apPdu.Message.Msg.(*ies.ResourceStatusRequest)
+	if !ok {
+		du.Error("Failed to cast message to ResourceStatusRequest")
+		return fmt.Errorf("invalid message type")
+	}
+
+	du.Info("Resource Status Request: CU-Meas-ID=%d, Registration=%d",
+		msg.GNBCUMeasurementID, msg.RegistrationRequest.Value)
+
+	switch msg.RegistrationRequest.Value {
+	case ies.RegistrationRequestStart:
+		return du.startResourceStatusReport(msg)
+	case ies.RegistrationRequestStop:
+		return du.stopResourceStatusReport(msg)
+	default:
+		du.Warn("Unsupported Registration Request %d", msg.RegistrationRequest.Value)
+		return du.sendResourceStatusFailure(msg.TransactionID, msg.GNBCUMeasurementID, 0,
+			ies.CauseRadioNetworkMeasurementnotsupportedfortheobject)
+	}
+}
+
+func (du *DU) startResourceStatusReport(msg *ies.ResourceStatusRequest) error {
+	ctx := du.resCtx
+
+	if msg.ReportCharacteristics == nil || isBitmapEmpty(msg.ReportCharacteristics.Bytes) {
+		du.Warn("Resource Status Request has empty Report Characteristics")
+		return du.sendResourceStatusFailure(msg.TransactionID, msg.GNBCUMeasurementID, 0,
+			ies.CauseRadioNetworkReportcharacteristicsempty)
+	}
+
+	ctx.mutex.Lock()
+	if _, exist := ctx.reports[msg.GNBCUMeasurementID]; exist {
+		ctx.mutex.Unlock()
+		du.Warn("Resource Status measurement %d already exists", msg.GNBCUMeasurementID)
+		return du.sendResourceStatusFailure(msg.TransactionID, msg.GNBCUMeasurementID, 0,
+			ies.CauseRadioNetworkExistingmeasurementid)
+	}
+
+	report := &resourceStatusReport{
+		cuMeasId:        msg.GNBCUMeasurementID,
+		duMeasId:        ctx.nextDuMeasId,
+		characteristics: msg.ReportCharacteristics.Bytes,
+		period:          reportingPeriod(msg.ReportingPeriodicity),
+		stop:            make(chan struct{}),
+	}
+	ctx.nextDuMeasId = ctx.nextDuMeasId%4095 + 1
+
+	for _, cell := range msg.CellToReportList {
+		report.cells = append(report.cells, cell.CellID)
+	}
+	if len(report.cells) == 0 {
+		report.cells = []ies.NRCGI{du.servedCellNRCGI()}
+	}
+
+	ctx.reports[report.cuMeasId] = report
+	ctx.mutex.Unlock()
+
+	if err := du.sendResourceStatusResponse(msg.TransactionID, report.cuMeasId, report.duMeasId); err != nil {
+		return err
+	}
+
+	du.Info("Start Resource Status reporting: CU-Meas-ID=%d, DU-Meas-ID=%d, period=%v",
+		report.cuMeasId, report.duMeasId, report.period)
+	go du.runResourceStatusReport(report)
+	return nil
+}
+
+func (du *DU) stopResourceStatusReport(msg *ies.ResourceStatusRequest) error {
+	ctx := du.resCtx
+
+	ctx.mutex.Lock()
+	report, exist := ctx.reports[msg.GNBCUMeasurementID]
+	if exist {
+		delete(ctx.reports, msg.GNBCUMeasurementID)
+	}
+	ctx.mutex.Unlock()
+
+	var duMeasId int64
+	if msg.GNBDUMeasurementID != nil {
+		duMeasId = *msg.GNBDUMeasurementID
+	}
+	if !exist {
+		// F1AP has no radio network cause for an unknown measurement ID, the stop does not
+		// match the state of the DU
+		du.Warn("Resource Status measurement %d not found", msg.GNBCUMeasurementID)
+		return du.sendResourceStatusFailureCause(msg.TransactionID, msg.GNBCUMeasurementID, duMeasId, ies.Cause{
+			Choice:   ies.CausePresentProtocol,
+			Protocol: &ies.CauseProtocol{Value: ies.CauseProtocolMessageNotCompatibleWithReceiverState},
+		})
+	}
+
+	close(report.stop)
+	du.Info("Stop Resource Status reporting: CU-Meas-ID=%d", report.cuMeasId)
+	return du.sendResourceStatusResponse(msg.TransactionID, report.cuMeasId, report.duMeasId)
+}
+
+// StopResourceStatusReporting stops all ongoing resource status measurements
+func (du *DU) StopResourceStatusReporting() {
+	if du.resCtx == nil {
+		return
+	}
+	ctx := du.resCtx
+
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+	for id, report := range ctx.reports {
+		close(report.stop)
+		delete(ctx.reports, id)
+	}
+}
+
+// runResourceStatusReport periodically sends Resource Status Update until stopped
+func (du *DU) runResourceStatusReport(report *resourceStatusReport) {
+	ticker := time.NewTicker(report.period)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-report.stop:
+			return
+		case <-ticker.C:
+			if err := du.sendResourceStatusUpdate(report); err != nil {
+				du.Error("Failed to send Resource Status Update: %v", err)
+			}
+		}
+	}
+}
+
+// sendResourceStatusUpdate samples the load model and reports it to CU-CP
+func (du *DU) sendResourceStatusUpdate(report *resourceStatusReport) error {
+	load := du.resCtx.model.Sample(du.activeUeCount())
+
+	msg := &ies.ResourceStatusUpdate{
+		TransactionID:      0,
+		GNBCUMeasurementID: report.cuMeasId,
+		GNBDUMeasurementID: report.duMeasId,
+	}
+
+	// Hardware load simply follows the PRB usage in this simulator
+	if isBitSet(report.characteristics, REPORT_HW_LOAD_PERIODIC) {
+		msg.HardwareLoadIndicator = &ies.HardwareLoadIndicator{
+			DLHardwareLoadIndicator: load.DLTotalPRB,
+			ULHardwareLoadIndicator: load.ULTotalPRB,
+		}
+	}
+
+	for _, cell := range report.cells {
+		item := ies.CellMeasurementResultItem{CellID: cell}
+
+		if isBitSet(report.characteristics, REPORT_PRB_PERIODIC) {
+			item.RadioResourceStatus = &ies.RadioResourceStatus{
+				SSBAreaRadioResourceStatusList: ies.SSBAreaRadioResourceStatusItem{
+					SSBIndex:                0,
+					SSBAreaDLGBRPRBusage:    load.DLGBRPRB,
+					SSBAreaULGBRPRBusage:    load.ULGBRPRB,
+					SSBAreaDLnonGBRPRBusage: load.DLTotalPRB - load.DLGBRPRB,
+					SSBAreaULnonGBRPRBusage: load.ULTotalPRB - load.ULGBRPRB,
+					SSBAreaDLTotalPRBusage:  load.DLTotalPRB,
+					SSBAreaULTotalPRBusage:  load.ULTotalPRB,
+				},
+			}
+		}
+
+		if isBitSet(report.characteristics, REPORT_COMPOSITE_CAPACITY_PERIODIC) {
+			group := &ies.CompositeAvailableCapacityGroup{
+				CompositeAvailableCapacityDownlink: ies.CompositeAvailableCapacity{
+					CapacityValue: ies.CapacityValue{CapacityValue: load.DLAvailable},
+				},
+				CompositeAvailableCapacityUplink: ies.CompositeAvailableCapacity{
+					CapacityValue: ies.CapacityValue{CapacityValue: load.ULAvailable},
+				},
+			}
+			if load.CapacityClass > 0 {
+				capacityClass := load.CapacityClass
+				group.CompositeAvailableCapacityDownlink.CellCapacityClassValue = &capacityClass
+				group.CompositeAvailableCapacityUplink.CellCapacityClassValue = &capacityClass
+			}
+			item.CompositeAvailableCapacityGroup = group
+		}
+
+		if isBitSet(report.characteristics, REPORT_NUMBER_OF_ACTIVE_UES) {
+			activeUes := load.ActiveUEs
+			item.NumberofActiveUEs = &activeUes
+		}
+
+		msg.CellMeasurementResultList = append(msg.CellMeasurementResultList, item)
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Resource Status Update: %w", err)
+	}
+
+	du.Debug("Sending Resource Status Update: DL PRB=%d%%, UL PRB=%d%%, UEs=%d",
+		load.DLTotalPRB, load.ULTotalPRB, load.ActiveUEs)
+	if du.f1Client != nil {
+		return du.f1Client.Send(f1apBytes)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendResourceStatusResponse sends Resource Status Response to CU-CP
+func (du *DU) sendResourceStatusResponse(transId, cuMeasId, duMeasId int64) error {
+	du.Info("Sending Resource Status Response")
+
+	msg := &ies.ResourceStatusResponse{
+		TransactionID:      transId,
+		GNBCUMeasurementID: cuMeasId,
+		GNBDUMeasurementID: duMeasId,
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Resource Status Response: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.f1Client.Send(f1apBytes)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendResourceStatusFailure sends Resource Status Failure to CU-CP with a radio network cause
+func (du *DU) sendResourceStatusFailure(transId, cuMeasId, duMeasId int64, cause aper.Enumerated) error {
+	return du.sendResourceStatusFailureCause(transId, cuMeasId, duMeasId, ies.Cause{
+		Choice:       ies.CausePresentRadioNetwork,
+		RadioNetwork: &ies.CauseRadioNetwork{Value: cause},
+	})
+}
+
+// sendResourceStatusFailureCause sends Resource Status Failure to CU-CP
+func (du *DU) sendResourceStatusFailureCause(transId, cuMeasId, duMeasId int64, cause ies.Cause) error {
+	du.Warn("Sending Resource Status Failure")
+
+	msg := &ies.ResourceStatusFailure{
+		TransactionID:      transId,
+		GNBCUMeasurementID: cuMeasId,
+		GNBDUMeasurementID: duMeasId,
+		Cause:              cause,
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Resource Status Failure: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.f1Client.Send(f1apBytes)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// activeUeCount returns the number of UEs with an RRC connection through the DU
+func (du *DU) activeUeCount() int {
+	du.mu.Lock()
+	ue := du.ue
+	du.mu.Unlock()
+	if ue != nil && ue.rrcConnected.Load() {
+		return 1
+	}
+	return 0
+}
+
+func reportingPeriod(p *ies.ReportingPeriodicity) time.Duration {
+	if p == nil {
+		return time.Second
+	}
+	switch p.Value {
+	case ies.ReportingPeriodicityMs500:
+		return 500 * time.Millisecond
+	case ies.ReportingPeriodicityMs2000:
+		return 2 * time.Second
+	case ies.ReportingPeriodicityMs5000:
+		return 5 * time.Second
+	case ies.ReportingPeriodicityMs10000:
+		return 10 * time.Second
+	default:
+		return time.Second
+	}
+}
+
+// isBitSet checks bit i of a bitmap, bit 0 being the leftmost one
+func isBitSet(bitmap []byte, i uint) bool {
+	if int(i/8) >= len(bitmap) {
+		return false
+	}
+	return bitmap[i/8]&(0x80>>(i%8)) != 0
+}
+
+func isBitmapEmpty(bitmap []byte) bool {
+	for _, b := range bitmap {
+		if b != 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,242 @@
+package du
+
+import (
+	"du_ue/internal/common/logger"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+)
+
+const (
+	DEFAULT_F1AP_UE_WORKERS = 8
+	DEFAULT_F1AP_QUEUE_SIZE = 256
+	F1AP_QUEUE_LOG_INTERVAL = 30 * time.Second
+)
+
+type f1apJob struct {
+	data []byte
+	pdu  *f1ap.F1apPdu
+}
+
+// f1apWorker handles the F1AP messages of its queue one at a time, in arrival order
+type f1apWorker struct {
+	name     string
+	queue    chan f1apJob
+	maxDepth atomic.Int64
+	handled  atomic.Int64
+	high     atomic.Bool // queue above the high watermark, warned once until drained
+}
+
+// F1apQueueStats is a snapshot of the queue of one F1AP worker
+type F1apQueueStats struct {
+	Worker   string
+	Depth    int   // messages waiting
+	MaxDepth int64 // highest depth seen
+	Handled  int64 // messages handled
+}
+
+// f1apWorkerPool dispatches inbound F1AP messages to workers sharded by UE F1AP ID, so
+// the messages of one UE are handled in order while different UEs proceed in parallel.
+// Non UE-associated procedures have a dedicated worker.
+type f1apWorkerPool struct {
+	*logger.Logger
+
+	nonUe    *f1apWorker
+	ue       []*f1apWorker
+	duUeIds  map[int64]int64 // gNB-DU UE F1AP ID by gNB-CU UE F1AP ID, only used by the reading goroutine
+	handle   func(data []byte, pdu *f1ap.F1apPdu)
+	done     chan struct{} // closed by stop, ends the workers and the stats logging
+	stopOnce sync.Once
+}
+
+func newF1apWorkerPool(ueWorkers, queueSize int, handle func([]byte, *f1ap.F1apPdu), log *logger.Logger) *f1apWorkerPool {
+	if ueWorkers <= 0 {
+		ueWorkers = DEFAULT_F1AP_UE_WORKERS
+	}
+	if queueSize <= 0 {
+		queueSize = DEFAULT_F1AP_QUEUE_SIZE
+	}
+
+	p := &f1apWorkerPool{
+		Logger:  log,
+		nonUe:   &f1apWorker{name: "non-ue", queue: make(chan f1apJob, queueSize)},
+		duUeIds: make(map[int64]int64),
+		handle:  handle,
+		done:    make(chan struct{}),
+	}
+	for i := 0; i < ueWorkers; i++ {
+		p.ue = append(p.ue, &f1apWorker{name: fmt.Sprintf("ue-%d", i), queue: make(chan f1apJob, queueSize)})
+	}
+
+	go p.run(p.nonUe)
+	for _, w := range p.ue {
+		go p.run(w)
+	}
+	go p.logStats(F1AP_QUEUE_LOG_INTERVAL)
+	return p
+}
+
+// dispatch queues a decoded message on the worker of its UE, blocking while that queue is full
+// and dropping it once the pool is stopped
+func (p *f1apWorkerPool) dispatch(data []byte, pdu *f1ap.F1apPdu) {
+	w := p.nonUe
+	if cuUeId, duUeId, ok := f1apUeIds(pdu.Message.Msg); ok {
+		w = p.ue[p.shardKey(cuUeId, duUeId)%int64(len(p.ue))]
+	}
+
+	switch pdu.Message.Msg.(type) {
+	case *ies.UEContextReleaseCommand:
+		// the UE context is gone, later messages of the gNB-CU UE F1AP ID are for a new one
+		cuUeId, _, _ := f1apUeIds(pdu.Message.Msg)
+		delete(p.duUeIds, cuUeId)
+	case *ies.Reset:
+		p.resetUeIds()
+	}
+
+	select {
+	case w.queue <- f1apJob{data: data, pdu: pdu}:
+	case <-p.done:
+		return
+	}
+
+	depth := int64(len(w.queue))
+	if depth > w.maxDepth.Load() {
+		w.maxDepth.Store(depth)
+	}
+	if depth > int64(cap(w.queue))*3/4 && !w.high.Swap(true) {
+		p.Warn("F1AP worker %s queue at %d/%d", w.name, depth, cap(w.queue))
+	}
+}
+
+// stop ends the workers and the stats logging of the pool, the messages still queued are dropped
+func (p *f1apWorkerPool) stop() {
+	p.stopOnce.Do(func() { close(p.done) })
+}
+
+func (p *f1apWorkerPool) run(w *f1apWorker) {
+	for {
+		var job f1apJob
+		select {
+		case job = <-w.queue:
+		case <-p.done:
+			return
+		}
+		p.handle(job.data, job.pdu)
+		w.handled.Add(1)
+		if w.high.Load() && len(w.queue) < cap(w.queue)/2 {
+			w.high.Store(false)
+			p.Info("F1AP worker %s queue drained to %d/%d", w.name, len(w.queue), cap(w.queue))
+		}
+	}
+}
+
+// shardKey returns the gNB-DU UE F1AP ID the messages of a UE are ordered on. Without it in the
+// message, it is the one learnt from earlier messages of the gNB-CU UE F1AP ID. A gNB-CU UE F1AP
+// ID not seen yet asks for a new UE context, which takes the gNB-DU UE F1AP ID of the single UE
+// context of the DU.
+func (p *f1apWorkerPool) shardKey(cuUeId int64, duUeId *int64) int64 {
+	if duUeId != nil {
+		p.duUeIds[cuUeId] = *duUeId
+		return *duUeId
+	}
+	if id, ok := p.duUeIds[cuUeId]; ok {
+		return id
+	}
+	return DU_UE_F1AP_ID
+}
+
+// resetUeIds forgets the UE F1AP IDs learnt from CU-CP, after an F1 Reset or a lost F1 connection
+func (p *f1apWorkerPool) resetUeIds() {
+	clear(p.duUeIds)
+}
+
+// logStats logs the queue statistics of the workers that handled messages since the last interval
+func (p *f1apWorkerPool) logStats(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	handled := make(map[string]int64)
+	for {
+		select {
+		case <-ticker.C:
+		case <-p.done:
+			return
+		}
+		for _, s := range p.stats() {
+			if s.Handled == handled[s.Worker] && s.Depth == 0 {
+				continue
+			}
+			handled[s.Worker] = s.Handled
+			p.Info("F1AP worker %s queue: depth %d, max depth %d, handled %d", s.Worker, s.Depth, s.MaxDepth, s.Handled)
+		}
+	}
+}
+
+// stats returns the queue statistics of all workers, the non UE worker first
+func (p *f1apWorkerPool) stats() []F1apQueueStats {
+	workers := append([]*f1apWorker{p.nonUe}, p.ue...)
+	stats := make([]F1apQueueStats, 0, len(workers))
+	for _, w := range workers {
+		stats = append(stats, F1apQueueStats{
+			Worker:   w.name,
+			Depth:    len(w.queue),
+			MaxDepth: w.maxDepth.Load(),
+			Handled:  w.handled.Load(),
+		})
+	}
+	return stats
+}
+
+// f1apUeIds returns the UE F1AP IDs of a UE-associated message received from CU-CP. The gNB-DU
+// UE F1AP ID is nil when CU-CP asks for a new UE context in UE Context Setup Request.
+func f1apUeIds(msg any) (cuUeId int64, duUeId *int64, ok bool) {
+	switch m := msg.(type) {
+	case *ies.DLRRCMessageTransfer:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.UEContextSetupRequest:
+		return m.GNBCUUEF1APID, m.GNBDUUEF1APID, true
+	case *ies.UEContextSetupResponse:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.UEContextSetupFailure:
+		return m.GNBCUUEF1APID, m.GNBDUUEF1APID, true
+	case *ies.UEContextModificationRequest:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.UEContextModificationConfirm:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.UEContextModificationRefuse:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.UEContextReleaseCommand:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.TraceStart:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.DeactivateTrace:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.PositioningInformationRequest:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.PositioningActivationRequest:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	case *ies.PositioningDeactivation:
+		return m.GNBCUUEF1APID, &m.GNBDUUEF1APID, true
+	}
+	return 0, nil, false
+}
+
+func NewF1apWorkerPoolForTest(ueWorkers, queueSize int, handle func([]byte, *f1ap.F1apPdu)) *f1apWorkerPool {
+	return newF1apWorkerPool(ueWorkers, queueSize, handle, logger.InitLogger("info", map[string]string{"mod": "f1ap_client"}))
+}
+
+func (p *f1apWorkerPool) DispatchForTest(pdu *f1ap.F1apPdu) {
+	p.dispatch(nil, pdu)
+}
+
+func (p *f1apWorkerPool) StatsForTest() []F1apQueueStats {
+	return p.stats()
+}
+
+func (p *f1apWorkerPool) StopForTest() {
+	p.stop()
+}
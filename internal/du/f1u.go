@@ -0,0 +1,631 @@
+package du
+
+import (
+	"du_ue/internal/uecontext"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+const (
+	F1U_FIRST_TEID          = 0x100
+	F1U_BUFFER_LEN          = 65536
+	DEFAULT_F1U_BUFFER_SIZE = 1 << 20 // bytes, desired buffer size of a DRB
+	F1U_MAX_LOST_RANGES     = 16      // lost NR-U SN ranges kept until the next report
+)
+
+// f1uTunnel bridges one DRB of a UE with its F1-U tunnel towards CU-UP
+type f1uTunnel struct {
+	drbId     int64
+	dlTeid    uint32       // allocated by the DU, CU-UP sends DL packets to it
+	ulTeid    uint32       // allocated by CU-UP
+	ulAddr    *net.UDPAddr // CU-UP F1-U endpoint
+	ue        *UeChannel
+	dlPackets atomic.Uint64
+	dlBytes   atomic.Uint64
+	ulPackets atomic.Uint64
+	dlDropped atomic.Uint64
+	dddsSent  atomic.Uint64
+	ulPdcpSn  atomic.Uint32
+
+	// NR-U state (TS 38.425), guarded by the mutex of F1uContext
+	dlReceived         bool // DL data seen, the DRB is reported in DL Data Delivery Status
+	nextNrUSn          uint32
+	lostRanges         []NruSnRange
+	hasTransmittedSn   bool
+	highestTransmitted uint32 // highest PDCP SN handed to the UE
+	hasDeliveredSn     bool
+	highestDelivered   uint32 // highest PDCP SN taken by the UE
+}
+
+// f1uDelivery is a DL PDCP PDU handed to the UE and not yet known to be taken by it
+type f1uDelivery struct {
+	tunnel *f1uTunnel
+	sn     uint32
+}
+
+// F1uContext is the GTP-U endpoint of the DU carrying the DRBs of its UEs (TS 38.474)
+type F1uContext struct {
+	localIP  net.IP
+	port     int
+	conn     *net.UDPConn
+	tunnels  map[uint32]*f1uTunnel // by DL TEID
+	nextTeid uint32
+	mutex    sync.Mutex
+
+	pdcpSnSize   int // PDCP SN length of the DRBs, 0 when CU-UP sends packets without PDCP header
+	bufferSize   uint64
+	dddsInterval time.Duration
+	inFlight     map[*UeChannel][]f1uDelivery // per UE, in the order handed to the UE
+	stop         chan struct{}
+}
+
+// InitF1uContext prepares the F1-U endpoint, the GTP-U socket is opened with the first DRB
+func (du *DU) InitF1uContext() {
+	localAddr := du.Config.F1U.Address
+	if localAddr == "" {
+		localAddr = du.Config.LocalAddr
+	}
+	port := du.Config.F1U.Port
+	if port == 0 {
+		port = GTPU_PORT
+	}
+	bufferSize := uint64(du.Config.F1U.BufferSize)
+	if bufferSize == 0 {
+		bufferSize = DEFAULT_F1U_BUFFER_SIZE
+	}
+	du.f1uCtx = &F1uContext{
+		localIP:      net.ParseIP(localAddr),
+		port:         port,
+		tunnels:      make(map[uint32]*f1uTunnel),
+		nextTeid:     F1U_FIRST_TEID,
+		pdcpSnSize:   du.Config.F1U.PdcpSnSize,
+		bufferSize:   bufferSize,
+		dddsInterval: time.Duration(du.Config.F1U.DddsInterval) * time.Millisecond,
+		inFlight:     make(map[*UeChannel][]f1uDelivery),
+	}
+}
+
+// startF1u opens the GTP-U socket on first use
+func (du *DU) startF1u() (*net.UDPConn, error) {
+	ctx := du.f1uCtx
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	if ctx.conn != nil {
+		return ctx.conn, nil
+	}
+	if ctx.localIP == nil {
+		return nil, fmt.Errorf("no F1-U address configured")
+	}
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: ctx.localIP, Port: ctx.port})
+	if err != nil {
+		return nil, fmt.Errorf("open F1-U socket: %w", err)
+	}
+	ctx.conn = conn
+	ctx.stop = make(chan struct{})
+	du.Info("F1-U GTP-U endpoint listening on %s", conn.LocalAddr())
+	go du.runF1uReceiver(conn)
+	if ctx.dddsInterval > 0 {
+		go du.runDddsTimer(ctx.dddsInterval, ctx.stop)
+	}
+	return conn, nil
+}
+
+// StopF1u closes the GTP-U socket and drops all tunnels
+func (du *DU) StopF1u() {
+	if du.f1uCtx == nil {
+		return
+	}
+	ctx := du.f1uCtx
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	if ctx.conn != nil {
+		ctx.conn.Close()
+		ctx.conn = nil
+		close(ctx.stop)
+	}
+	ctx.tunnels = make(map[uint32]*f1uTunnel)
+	ctx.inFlight = make(map[*UeChannel][]f1uDelivery)
+}
+
+// setupDrbs creates an F1-U tunnel for each DRB of UE Context Setup Request owned by the UE
+func (du *DU) setupDrbs(ue *UeChannel, items []ies.DRBsToBeSetupItem) ([]ies.DRBsSetupItem, []ies.DRBsFailedToBeSetupItem) {
+	var setup []ies.DRBsSetupItem
+	var failed []ies.DRBsFailedToBeSetupItem
+	if len(items) == 0 {
+		return nil, nil
+	}
+
+	fail := func(drbId int64, cause ies.Cause) {
+		failed = append(failed, ies.DRBsFailedToBeSetupItem{DRBID: drbId, Cause: &cause})
+	}
+
+	if ue == nil {
+		for _, item := range items {
+			fail(item.DRBID, radioNetworkCause(ies.CauseRadioNetworkUnknownorinconsistentpairofuef1Apid))
+		}
+		return nil, failed
+	}
+	if _, err := du.startF1u(); err != nil {
+		du.Error("Failed to start F1-U: %v", err)
+		for _, item := range items {
+			fail(item.DRBID, transportCause(ies.CauseTransportTransportresourceunavailable))
+		}
+		return nil, failed
+	}
+
+	for _, item := range items {
+		ulAddr, ulTeid, err := ulUpTnlInformation(item.ULUPTNLInformationToBeSetupList)
+		if err != nil {
+			du.Error("DRB %d: %v", item.DRBID, err)
+			fail(item.DRBID, transportCause(ies.CauseTransportUnspecified))
+			continue
+		}
+
+		tunnel := du.addF1uTunnel(ue, item.DRBID, ulAddr, ulTeid)
+		du.Info("DRB %d set up: DL TEID 0x%x, UL TEID 0x%x at %s", item.DRBID, tunnel.dlTeid, ulTeid, ulAddr)
+
+		lcid := min(item.DRBID+3, 32) // LCID 1..3 are used by SRB1..3
+		setup = append(setup, ies.DRBsSetupItem{
+			DRBID: item.DRBID,
+			LCID:  &lcid,
+			DLUPTNLInformationToBeSetupList: []ies.DLUPTNLInformationToBeSetupItem{
+				{DLUPTNLInformation: du.gtpTunnelInformation(tunnel.dlTeid)},
+			},
+		})
+	}
+	return setup, failed
+}
+
+// addF1uTunnel allocates a DL TEID for a DRB, replacing an earlier tunnel of the same DRB
+func (du *DU) addF1uTunnel(ue *UeChannel, drbId int64, ulAddr *net.UDPAddr, ulTeid uint32) *f1uTunnel {
+	ctx := du.f1uCtx
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	for teid, t := range ctx.tunnels {
+		if t.ue == ue && t.drbId == drbId {
+			delete(ctx.tunnels, teid)
+		}
+	}
+
+	for ctx.tunnels[ctx.nextTeid] != nil || ctx.nextTeid == 0 {
+		ctx.nextTeid++
+	}
+	tunnel := &f1uTunnel{
+		drbId:  drbId,
+		dlTeid: ctx.nextTeid,
+		ulTeid: ulTeid,
+		ulAddr: ulAddr,
+		ue:     ue,
+	}
+	ctx.tunnels[tunnel.dlTeid] = tunnel
+	ctx.nextTeid++
+	return tunnel
+}
+
+// releaseDrbs removes the F1-U tunnels of a UE, with a final DL Data Delivery Status on the DRBs
+// that carried DL data so that CU-UP can forward what the UE did not get
+func (du *DU) releaseDrbs(ue *UeChannel) {
+	if du.f1uCtx == nil || ue == nil {
+		return
+	}
+	ctx := du.f1uCtx
+	ctx.mutex.Lock()
+	var reported []*f1uTunnel
+	for teid, t := range ctx.tunnels {
+		if t.ue == ue {
+			du.Info("DRB %d released: DL packets=%d (dropped %d), UL packets=%d, DDDS sent=%d",
+				t.drbId, t.dlPackets.Load(), t.dlDropped.Load(), t.ulPackets.Load(), t.dddsSent.Load())
+			delete(ctx.tunnels, teid)
+			if t.dlReceived {
+				reported = append(reported, t)
+			}
+		}
+	}
+	ctx.mutex.Unlock()
+
+	for _, t := range reported {
+		du.sendDdds(t, true)
+	}
+
+	ctx.mutex.Lock()
+	delete(ctx.inFlight, ue)
+	ctx.mutex.Unlock()
+}
+
+// ueTunnel returns the F1-U tunnel of a DRB of the UE
+func (du *DU) ueTunnel(ue *UeChannel, drbId int64) *f1uTunnel {
+	ctx := du.f1uCtx
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	for _, t := range ctx.tunnels {
+		if t.ue == ue && t.drbId == drbId {
+			return t
+		}
+	}
+	return nil
+}
+
+// runF1uReceiver delivers DL G-PDUs to the UE owning the tunnel and answers GTP-U path management
+func (du *DU) runF1uReceiver(conn *net.UDPConn) {
+	buf := make([]byte, F1U_BUFFER_LEN)
+	for {
+		n, from, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			du.Info("F1-U receiver stopped: %v", err)
+			return
+		}
+
+		pkt, err := DecodeGtpu(buf[:n])
+		if err != nil {
+			du.Warn("Invalid GTP-U packet from %s: %v", from, err)
+			continue
+		}
+
+		switch pkt.MsgType {
+		case GTPU_G_PDU:
+			du.handleF1uGpdu(conn, from, pkt)
+		case GTPU_ECHO_REQUEST:
+			if rsp, err := gtpuEchoResponse(pkt); err == nil {
+				conn.WriteToUDP(rsp, from)
+			}
+		case GTPU_ERROR_INDICATION:
+			du.Warn("GTP-U Error Indication from %s", from)
+		case GTPU_END_MARKER:
+			du.Info("GTP-U End Marker on TEID 0x%x", pkt.TEID)
+		default:
+			du.Warn("Unhandled GTP-U message type %d from %s", pkt.MsgType, from)
+		}
+	}
+}
+
+func (du *DU) handleF1uGpdu(conn *net.UDPConn, from *net.UDPAddr, pkt *GtpuPacket) {
+	ctx := du.f1uCtx
+	ctx.mutex.Lock()
+	tunnel := ctx.tunnels[pkt.TEID]
+	ctx.mutex.Unlock()
+
+	if tunnel == nil {
+		du.Warn("G-PDU on unknown TEID 0x%x from %s", pkt.TEID, from)
+		if ind, err := gtpuErrorIndication(pkt.TEID, ctx.localIP.To4()); err == nil {
+			conn.WriteToUDP(ind, from)
+		}
+		return
+	}
+
+	var userData *NruDlUserData
+	if frame := nruFrame(pkt); frame != nil {
+		f, err := DecodeNruDlUserData(frame)
+		if err != nil {
+			du.Warn("DRB %d: invalid NR-U frame: %v", tunnel.drbId, err)
+		} else {
+			userData = f
+			du.trackNrUSn(tunnel, f)
+		}
+	}
+
+	if len(pkt.Payload) > 0 && tunnel.ue.SendDataToUeChannel != nil {
+		du.deliverDl(tunnel, pkt.Payload)
+	}
+
+	if userData != nil && userData.ReportPolling {
+		du.sendDdds(tunnel, false)
+	}
+}
+
+// trackNrUSn records the NR-U SN of a DL USER DATA frame, a jump forward is reported as lost
+func (du *DU) trackNrUSn(tunnel *f1uTunnel, f *NruDlUserData) {
+	ctx := du.f1uCtx
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+
+	if f.DlFlush {
+		du.Info("DRB %d: DL flush up to PDCP SN %d", tunnel.drbId, f.DlDiscardPdcpSn)
+	}
+	if f.RetransmissionFlag {
+		return
+	}
+	if tunnel.dlReceived && f.NrUSN != tunnel.nextNrUSn {
+		gap := (f.NrUSN + NRU_SN_MODULUS - tunnel.nextNrUSn) % NRU_SN_MODULUS
+		if gap >= NRU_SN_MODULUS/2 {
+			// late or duplicate frame
+			return
+		}
+		lost := NruSnRange{Start: tunnel.nextNrUSn, End: (f.NrUSN + NRU_SN_MODULUS - 1) % NRU_SN_MODULUS}
+		du.Warn("DRB %d: NR-U SN %d..%d lost", tunnel.drbId, lost.Start, lost.End)
+		tunnel.lostRanges = append(tunnel.lostRanges, lost)
+		if len(tunnel.lostRanges) > F1U_MAX_LOST_RANGES {
+			tunnel.lostRanges = tunnel.lostRanges[1:]
+		}
+	}
+	tunnel.dlReceived = true
+	tunnel.nextNrUSn = (f.NrUSN + 1) % NRU_SN_MODULUS
+}
+
+// deliverDl hands a DL packet to the UE, without its PDCP header as the simulated UE has no PDCP
+func (du *DU) deliverDl(tunnel *f1uTunnel, payload []byte) {
+	ctx := du.f1uCtx
+	var sn uint32
+	if ctx.pdcpSnSize > 0 {
+		pdcpSn, headerLen, err := parsePdcpDataHeader(payload, ctx.pdcpSnSize)
+		if err != nil {
+			du.Debug("DRB %d: %v, dropped", tunnel.drbId, err)
+			tunnel.dlDropped.Add(1)
+			return
+		}
+		sn = pdcpSn
+		payload = payload[headerLen:]
+	}
+
+	data := append([]byte(nil), payload...)
+	// locked so that the PDUs in flight match the channel content
+	ctx.mutex.Lock()
+	defer ctx.mutex.Unlock()
+	select {
+	case tunnel.ue.SendDataToUeChannel <- uecontext.DrbPdu{DrbId: tunnel.drbId, Data: data}:
+		tunnel.dlPackets.Add(1)
+		tunnel.dlBytes.Add(uint64(len(data)))
+	default:
+		tunnel.dlDropped.Add(1)
+		return
+	}
+	if ctx.pdcpSnSize == 0 {
+		return
+	}
+
+	tunnel.hasTransmittedSn = true
+	tunnel.highestTransmitted = sn
+	inFlight := append(ctx.inFlight[tunnel.ue], f1uDelivery{tunnel: tunnel, sn: sn})
+	// more than the channel holds has surely been taken by the UE
+	if excess := len(inFlight) - cap(tunnel.ue.SendDataToUeChannel); excess > 0 {
+		markDelivered(inFlight[:excess])
+		inFlight = inFlight[excess:]
+	}
+	ctx.inFlight[tunnel.ue] = inFlight
+}
+
+// updateDelivered marks the PDUs the UE has taken from its channel as delivered, F1uContext locked
+func (du *DU) updateDelivered(ue *UeChannel) {
+	ctx := du.f1uCtx
+	inFlight := ctx.inFlight[ue]
+	pending := min(len(ue.SendDataToUeChannel), len(inFlight))
+	markDelivered(inFlight[:len(inFlight)-pending])
+	ctx.inFlight[ue] = append([]f1uDelivery(nil), inFlight[len(inFlight)-pending:]...)
+}
+
+func markDelivered(deliveries []f1uDelivery) {
+	for _, d := range deliveries {
+		d.tunnel.hasDeliveredSn = true
+		d.tunnel.highestDelivered = d.sn
+	}
+}
+
+// desiredBufferSize is the configured buffer minus the data waiting for the UE, F1uContext locked
+func (du *DU) desiredBufferSize(tunnel *f1uTunnel) uint32 {
+	packets := tunnel.dlPackets.Load()
+	if packets == 0 {
+		return uint32(min(du.f1uCtx.bufferSize, 0xffffffff))
+	}
+	queued := uint64(len(tunnel.ue.SendDataToUeChannel)) * (tunnel.dlBytes.Load() / packets)
+	if queued >= du.f1uCtx.bufferSize {
+		return 0
+	}
+	return uint32(min(du.f1uCtx.bufferSize-queued, 0xffffffff))
+}
+
+// sendDdds sends DL Data Delivery Status of a DRB to CU-UP, final when the DU stops reporting it
+func (du *DU) sendDdds(tunnel *f1uTunnel, final bool) {
+	ctx := du.f1uCtx
+	ctx.mutex.Lock()
+	du.updateDelivered(tunnel.ue)
+	status := &NruDlDataDeliveryStatus{
+		FinalFrame:        final,
+		DesiredBufferSize: du.desiredBufferSize(tunnel),
+		LostNrUSnRanges:   tunnel.lostRanges,
+	}
+	tunnel.lostRanges = nil
+	if tunnel.hasDeliveredSn {
+		sn := tunnel.highestDelivered
+		status.HighestDeliveredPdcpSn = &sn
+	}
+	if tunnel.hasTransmittedSn {
+		sn := tunnel.highestTransmitted
+		status.HighestTransmittedPdcpSn = &sn
+	}
+	conn := ctx.conn
+	ctx.mutex.Unlock()
+	if conn == nil {
+		return
+	}
+
+	frame, err := EncodeNruDdds(status)
+	if err != nil {
+		du.Error("DRB %d: encode DL Data Delivery Status: %v", tunnel.drbId, err)
+		return
+	}
+	b, err := EncodeGtpu(&GtpuPacket{
+		MsgType:    GTPU_G_PDU,
+		TEID:       tunnel.ulTeid,
+		Extensions: []GtpuExtensionHeader{{Type: GTPU_EXT_NR_RAN_CONTAINER, Content: frame}},
+	})
+	if err != nil {
+		du.Error("DRB %d: encode DL Data Delivery Status: %v", tunnel.drbId, err)
+		return
+	}
+	if _, err := conn.WriteToUDP(b, tunnel.ulAddr); err != nil {
+		du.Warn("DRB %d: send DL Data Delivery Status: %v", tunnel.drbId, err)
+		return
+	}
+	tunnel.dddsSent.Add(1)
+}
+
+// runDddsTimer reports the DL delivery status of the DRBs carrying DL data periodically
+func (du *DU) runDddsTimer(interval time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		ctx := du.f1uCtx
+		ctx.mutex.Lock()
+		var reported []*f1uTunnel
+		for _, t := range ctx.tunnels {
+			if t.dlReceived {
+				reported = append(reported, t)
+			}
+		}
+		ctx.mutex.Unlock()
+		for _, t := range reported {
+			du.sendDdds(t, false)
+		}
+	}
+}
+
+// HandleDataFromUE forwards the UL packets of the UE's DRBs to CU-UP
+func (du *DU) HandleDataFromUE() {
+	ue := du.ue
+	if ue == nil || ue.ReceiveDataFromUeChannel == nil {
+		return
+	}
+	var released <-chan struct{}
+	if ue.ctx != nil {
+		released = ue.ctx.Done()
+	}
+
+	for {
+		select {
+		case <-released:
+			return
+		case pdu, ok := <-ue.ReceiveDataFromUeChannel:
+			if !ok {
+				return
+			}
+			if err := du.sendF1uUplink(ue, pdu); err != nil {
+				du.Warn("Failed to forward UL data of DRB %d: %v", pdu.DrbId, err)
+			}
+		}
+	}
+}
+
+func (du *DU) sendF1uUplink(ue *UeChannel, pdu uecontext.DrbPdu) error {
+	tunnel := du.ueTunnel(ue, pdu.DrbId)
+	if tunnel == nil {
+		return fmt.Errorf("DRB not set up")
+	}
+
+	du.f1uCtx.mutex.Lock()
+	conn := du.f1uCtx.conn
+	du.f1uCtx.mutex.Unlock()
+	if conn == nil {
+		return fmt.Errorf("F1-U not started")
+	}
+
+	data := pdu.Data
+	if size := du.f1uCtx.pdcpSnSize; size > 0 {
+		sn := (tunnel.ulPdcpSn.Add(1) - 1) % (1 << size)
+		data = append(pdcpDataHeader(sn, size), data...)
+	}
+	b, err := EncodeGtpu(&GtpuPacket{MsgType: GTPU_G_PDU, TEID: tunnel.ulTeid, Payload: data})
+	if err != nil {
+		return err
+	}
+	if _, err := conn.WriteToUDP(b, tunnel.ulAddr); err != nil {
+		return err
+	}
+	tunnel.ulPackets.Add(1)
+	return nil
+}
+
+// gtpTunnelInformation returns the UP TNL Information of a DL TEID of the DU
+func (du *DU) gtpTunnelInformation(teid uint32) ies.UPTransportLayerInformation {
+	teidBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(teidBytes, teid)
+
+	addr := du.f1uCtx.localIP.To4()
+	if addr == nil {
+		addr = du.f1uCtx.localIP.To16()
+	}
+	return ies.UPTransportLayerInformation{
+		Choice: ies.UPTransportLayerInformationPresentGTPTunnel,
+		GTPTunnel: &ies.GTPTunnel{
+			TransportLayerAddress: aper.BitString{Bytes: addr, NumBits: uint64(len(addr) * 8)},
+			GTPTEID:               teidBytes,
+		},
+	}
+}
+
+// ulUpTnlInformation returns the CU-UP endpoint of the first UL UP TNL Information of a DRB
+func ulUpTnlInformation(items []ies.ULUPTNLInformationToBeSetupItem) (*net.UDPAddr, uint32, error) {
+	if len(items) == 0 {
+		return nil, 0, fmt.Errorf("no UL UP TNL Information")
+	}
+	tunnel := items[0].ULUPTNLInformation.GTPTunnel
+	if items[0].ULUPTNLInformation.Choice != ies.UPTransportLayerInformationPresentGTPTunnel || tunnel == nil {
+		return nil, 0, fmt.Errorf("UL UP TNL Information is not a GTP tunnel")
+	}
+	if len(tunnel.GTPTEID) != 4 {
+		return nil, 0, fmt.Errorf("invalid GTP-TEID length %d", len(tunnel.GTPTEID))
+	}
+
+	// an IPv4 address, an IPv6 address, or both of them of which the IPv4 one is used
+	addrLen := 0
+	switch tunnel.TransportLayerAddress.NumBits {
+	case 32, 160:
+		addrLen = 4
+	case 128:
+		addrLen = 16
+	}
+	if addrLen == 0 || len(tunnel.TransportLayerAddress.Bytes) < addrLen {
+		return nil, 0, fmt.Errorf("invalid transport layer address of %d bits", tunnel.TransportLayerAddress.NumBits)
+	}
+	addr := tunnel.TransportLayerAddress.Bytes[:addrLen]
+	return &net.UDPAddr{IP: net.IP(addr), Port: GTPU_PORT}, binary.BigEndian.Uint32(tunnel.GTPTEID), nil
+}
+
+func transportCause(cause aper.Enumerated) ies.Cause {
+	return ies.Cause{
+		Choice:    ies.CausePresentTransport,
+		Transport: &ies.CauseTransport{Value: cause},
+	}
+}
+
+// parsePdcpDataHeader returns the SN and header length of a PDCP Data PDU of a DRB (TS 38.323)
+func parsePdcpDataHeader(b []byte, snSize int) (uint32, int, error) {
+	headerLen := 2
+	if snSize == 18 {
+		headerLen = 3
+	}
+	if len(b) < headerLen {
+		return 0, 0, fmt.Errorf("PDCP PDU too short")
+	}
+	if b[0]&0x80 == 0 {
+		return 0, 0, fmt.Errorf("PDCP control PDU")
+	}
+	if snSize == 18 {
+		return uint32(b[0]&0x03)<<16 | uint32(b[1])<<8 | uint32(b[2]), headerLen, nil
+	}
+	return uint32(b[0]&0x0f)<<8 | uint32(b[1]), headerLen, nil
+}
+
+// pdcpDataHeader builds the header of a PDCP Data PDU of a DRB
+func pdcpDataHeader(sn uint32, snSize int) []byte {
+	if snSize == 18 {
+		return []byte{0x80 | byte(sn>>16)&0x03, byte(sn >> 8), byte(sn)}
+	}
+	return []byte{0x80 | byte(sn>>8)&0x0f, byte(sn)}
+}
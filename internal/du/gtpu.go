@@ -0,0 +1,158 @@
+package du
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// GTP-U (TS 29.281)
+const (
+	GTPU_PORT = 2152
+
+	GTPU_ECHO_REQUEST      = 1
+	GTPU_ECHO_RESPONSE     = 2
+	GTPU_ERROR_INDICATION  = 26
+	GTPU_END_MARKER        = 254
+	GTPU_G_PDU             = 255
+	GTPU_IE_RECOVERY       = 14
+	GTPU_IE_TEID_DATA_I    = 16
+	GTPU_IE_GSN_ADDRESS    = 133
+	gtpuHeaderLen          = 8
+	gtpuOptionalHeaderLen  = 4
+	gtpuFlagsVersion1PT    = 0x30
+	gtpuFlagExtension      = 0x04
+	gtpuFlagSequenceNumber = 0x02
+	gtpuFlagNPDU           = 0x01
+)
+
+// GtpuExtensionHeader is a GTP-U extension header, Content excludes the length and next type octets
+type GtpuExtensionHeader struct {
+	Type    uint8
+	Content []byte
+}
+
+// GtpuPacket is a decoded GTP-U message
+type GtpuPacket struct {
+	MsgType    uint8
+	TEID       uint32
+	SeqNum     *uint16
+	Extensions []GtpuExtensionHeader
+	Payload    []byte
+}
+
+// EncodeGtpu encodes a GTP-U message, the optional header fields are present with a sequence
+// number or extension headers
+func EncodeGtpu(p *GtpuPacket) ([]byte, error) {
+	flags := byte(gtpuFlagsVersion1PT)
+	var opt []byte
+	if p.SeqNum != nil || len(p.Extensions) > 0 {
+		opt = make([]byte, gtpuOptionalHeaderLen)
+		if p.SeqNum != nil {
+			flags |= gtpuFlagSequenceNumber
+			binary.BigEndian.PutUint16(opt, *p.SeqNum)
+		}
+		if len(p.Extensions) > 0 {
+			flags |= gtpuFlagExtension
+			opt[3] = p.Extensions[0].Type
+		}
+		for i, ext := range p.Extensions {
+			// length in 4 octet units, counting the length and next type octets
+			total := len(ext.Content) + 2
+			if total%4 != 0 {
+				return nil, fmt.Errorf("GTP-U extension header 0x%x length %d not a multiple of 4", ext.Type, total)
+			}
+			next := byte(0)
+			if i+1 < len(p.Extensions) {
+				next = p.Extensions[i+1].Type
+			}
+			opt = append(opt, byte(total/4))
+			opt = append(opt, ext.Content...)
+			opt = append(opt, next)
+		}
+	}
+
+	length := len(opt) + len(p.Payload)
+	if length > 0xffff {
+		return nil, fmt.Errorf("GTP-U message too long: %d bytes", length)
+	}
+	b := make([]byte, gtpuHeaderLen, gtpuHeaderLen+length)
+	b[0] = flags
+	b[1] = p.MsgType
+	binary.BigEndian.PutUint16(b[2:], uint16(length))
+	binary.BigEndian.PutUint32(b[4:], p.TEID)
+	b = append(b, opt...)
+	return append(b, p.Payload...), nil
+}
+
+// DecodeGtpu decodes a GTP-U message
+func DecodeGtpu(b []byte) (*GtpuPacket, error) {
+	if len(b) < gtpuHeaderLen {
+		return nil, fmt.Errorf("GTP-U message too short: %d bytes", len(b))
+	}
+	if b[0]>>5 != 1 {
+		return nil, fmt.Errorf("unsupported GTP version %d", b[0]>>5)
+	}
+	length := int(binary.BigEndian.Uint16(b[2:]))
+	if gtpuHeaderLen+length > len(b) {
+		return nil, fmt.Errorf("GTP-U length %d exceeds message of %d bytes", length, len(b))
+	}
+
+	p := &GtpuPacket{
+		MsgType: b[1],
+		TEID:    binary.BigEndian.Uint32(b[4:]),
+	}
+	body := b[gtpuHeaderLen : gtpuHeaderLen+length]
+	flags := b[0]
+	if flags&(gtpuFlagExtension|gtpuFlagSequenceNumber|gtpuFlagNPDU) == 0 {
+		p.Payload = body
+		return p, nil
+	}
+
+	if len(body) < gtpuOptionalHeaderLen {
+		return nil, fmt.Errorf("GTP-U optional header truncated")
+	}
+	if flags&gtpuFlagSequenceNumber != 0 {
+		seq := binary.BigEndian.Uint16(body)
+		p.SeqNum = &seq
+	}
+	next := body[3]
+	body = body[gtpuOptionalHeaderLen:]
+	if flags&gtpuFlagExtension == 0 {
+		next = 0
+	}
+	for next != 0 {
+		if len(body) < 1 || int(body[0])*4 > len(body) || body[0] == 0 {
+			return nil, fmt.Errorf("GTP-U extension header 0x%x truncated", next)
+		}
+		total := int(body[0]) * 4
+		p.Extensions = append(p.Extensions, GtpuExtensionHeader{Type: next, Content: body[1 : total-1]})
+		next = body[total-1]
+		body = body[total:]
+	}
+	p.Payload = body
+	return p, nil
+}
+
+// gtpuEchoResponse builds the Echo Response to an Echo Request
+func gtpuEchoResponse(req *GtpuPacket) ([]byte, error) {
+	seq := uint16(0)
+	if req.SeqNum != nil {
+		seq = *req.SeqNum
+	}
+	return EncodeGtpu(&GtpuPacket{
+		MsgType: GTPU_ECHO_RESPONSE,
+		SeqNum:  &seq,
+		Payload: []byte{GTPU_IE_RECOVERY, 0},
+	})
+}
+
+// gtpuErrorIndication builds the Error Indication for a G-PDU received on an unknown TEID
+func gtpuErrorIndication(teid uint32, localIP []byte) ([]byte, error) {
+	ie := make([]byte, 5, 5+3+len(localIP))
+	ie[0] = GTPU_IE_TEID_DATA_I
+	binary.BigEndian.PutUint32(ie[1:], teid)
+	ie = append(ie, GTPU_IE_GSN_ADDRESS, 0, byte(len(localIP)))
+	ie = append(ie, localIP...)
+	seq := uint16(0)
+	return EncodeGtpu(&GtpuPacket{MsgType: GTPU_ERROR_INDICATION, SeqNum: &seq, Payload: ie})
+}
@@ -0,0 +1,62 @@
+package du
+
+import (
+	"du_ue/pkg/config"
+	"math/rand"
+)
+
+// CellLoad is one sample of the simulated load of the served cell
+type CellLoad struct {
+	DLTotalPRB    int64 // DL PRB usage (%)
+	ULTotalPRB    int64 // UL PRB usage (%)
+	DLGBRPRB      int64 // DL PRB usage for GBR traffic (%)
+	ULGBRPRB      int64 // UL PRB usage for GBR traffic (%)
+	DLAvailable   int64 // DL composite available capacity (%)
+	ULAvailable   int64 // UL composite available capacity (%)
+	ActiveUEs     int64
+	CapacityClass int64 // 0 when not configured
+}
+
+// LoadModel derives cell load samples from the number of active UEs
+type LoadModel struct {
+	cfg config.LoadConfig
+}
+
+func NewLoadModel(cfg config.LoadConfig) *LoadModel {
+	return &LoadModel{cfg: cfg}
+}
+
+// Sample computes the current cell load for the given number of active UEs
+func (m *LoadModel) Sample(activeUes int) CellLoad {
+	base := m.cfg.BasePRBUsage + m.cfg.PRBPerUE*int64(activeUes)
+
+	load := CellLoad{
+		DLTotalPRB:    m.applyJitter(base),
+		ULTotalPRB:    m.applyJitter(base),
+		ActiveUEs:     int64(activeUes),
+		CapacityClass: m.cfg.CapacityClass,
+	}
+	load.DLGBRPRB = load.DLTotalPRB * m.cfg.GBRShare / 100
+	load.ULGBRPRB = load.ULTotalPRB * m.cfg.GBRShare / 100
+	load.DLAvailable = 100 - load.DLTotalPRB
+	load.ULAvailable = 100 - load.ULTotalPRB
+	return load
+}
+
+// applyJitter adds the configured random variation and clamps to 0..100
+func (m *LoadModel) applyJitter(v int64) int64 {
+	if m.cfg.Jitter > 0 {
+		v += rand.Int63n(2*m.cfg.Jitter+1) - m.cfg.Jitter
+	}
+	return clampPercent(v)
+}
+
+func clampPercent(v int64) int64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
+}
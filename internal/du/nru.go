@@ -0,0 +1,211 @@
+package du
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// NR user plane protocol (TS 38.425), carried in the NR RAN Container extension header of GTP-U
+const (
+	GTPU_EXT_NR_RAN_CONTAINER = 0x84
+
+	NRU_DL_USER_DATA            = 0
+	NRU_DL_DATA_DELIVERY_STATUS = 1
+
+	NRU_SN_MODULUS = 1 << 24
+)
+
+// NruDlUserData is the DL USER DATA frame (PDU type 0) sent by CU-UP with each DL packet
+type NruDlUserData struct {
+	ReportPolling         bool
+	DlFlush               bool
+	DlDiscardBlocks       bool
+	RetransmissionFlag    bool
+	AssistanceInfoPolling bool
+	UserDataExistence     bool
+	ReportDelivered       bool
+	RequestOutOfSeqReport bool
+	NrUSN                 uint32
+	DlDiscardPdcpSn       uint32 // with DlFlush, discard the PDCP PDUs up to this SN
+}
+
+// NruSnRange is a range of NR-U sequence numbers, both ends included
+type NruSnRange struct {
+	Start uint32
+	End   uint32
+}
+
+// NruDlDataDeliveryStatus is the DL DATA DELIVERY STATUS frame (PDU type 1) sent by the DU
+type NruDlDataDeliveryStatus struct {
+	FinalFrame               bool
+	DesiredBufferSize        uint32
+	LostNrUSnRanges          []NruSnRange
+	HighestDeliveredPdcpSn   *uint32
+	HighestTransmittedPdcpSn *uint32
+	Cause                    *uint8
+}
+
+// EncodeNruDlUserData encodes a DL USER DATA frame padded for the NR RAN Container
+func EncodeNruDlUserData(f *NruDlUserData) []byte {
+	b := make([]byte, 2, 8)
+	b[0] = NRU_DL_USER_DATA<<4 | boolBit(f.DlDiscardBlocks)<<2 | boolBit(f.DlFlush)<<1 | boolBit(f.ReportPolling)
+	b[1] = boolBit(f.RequestOutOfSeqReport)<<4 | boolBit(f.ReportDelivered)<<3 | boolBit(f.UserDataExistence)<<2 |
+		boolBit(f.AssistanceInfoPolling)<<1 | boolBit(f.RetransmissionFlag)
+	b = appendUint24(b, f.NrUSN)
+	if f.DlFlush {
+		b = appendUint24(b, f.DlDiscardPdcpSn)
+	}
+	return nruPad(b)
+}
+
+// DecodeNruDlUserData decodes a DL USER DATA frame, discard blocks are not kept
+func DecodeNruDlUserData(b []byte) (*NruDlUserData, error) {
+	if len(b) < 5 || b[0]>>4 != NRU_DL_USER_DATA {
+		return nil, fmt.Errorf("not a DL USER DATA frame")
+	}
+	f := &NruDlUserData{
+		DlDiscardBlocks:       b[0]&0x04 != 0,
+		DlFlush:               b[0]&0x02 != 0,
+		ReportPolling:         b[0]&0x01 != 0,
+		RequestOutOfSeqReport: b[1]&0x10 != 0,
+		ReportDelivered:       b[1]&0x08 != 0,
+		UserDataExistence:     b[1]&0x04 != 0,
+		AssistanceInfoPolling: b[1]&0x02 != 0,
+		RetransmissionFlag:    b[1]&0x01 != 0,
+		NrUSN:                 uint24(b[2:]),
+	}
+	if f.DlFlush {
+		if len(b) < 8 {
+			return nil, fmt.Errorf("DL USER DATA frame truncated")
+		}
+		f.DlDiscardPdcpSn = uint24(b[5:])
+	}
+	return f, nil
+}
+
+// EncodeNruDdds encodes a DL DATA DELIVERY STATUS frame padded for the NR RAN Container
+func EncodeNruDdds(f *NruDlDataDeliveryStatus) ([]byte, error) {
+	if len(f.LostNrUSnRanges) > 0xff {
+		return nil, fmt.Errorf("too many lost NR-U SN ranges: %d", len(f.LostNrUSnRanges))
+	}
+	b := make([]byte, 6, 32)
+	b[0] = NRU_DL_DATA_DELIVERY_STATUS<<4 | boolBit(f.HighestTransmittedPdcpSn != nil)<<3 |
+		boolBit(f.HighestDeliveredPdcpSn != nil)<<2 | boolBit(f.FinalFrame)<<1 | boolBit(len(f.LostNrUSnRanges) > 0)
+	b[1] = boolBit(f.Cause != nil)
+	binary.BigEndian.PutUint32(b[2:], f.DesiredBufferSize)
+	if len(f.LostNrUSnRanges) > 0 {
+		b = append(b, byte(len(f.LostNrUSnRanges)))
+		for _, r := range f.LostNrUSnRanges {
+			b = appendUint24(b, r.Start)
+			b = appendUint24(b, r.End)
+		}
+	}
+	if f.HighestDeliveredPdcpSn != nil {
+		b = appendUint24(b, *f.HighestDeliveredPdcpSn)
+	}
+	if f.HighestTransmittedPdcpSn != nil {
+		b = appendUint24(b, *f.HighestTransmittedPdcpSn)
+	}
+	if f.Cause != nil {
+		b = append(b, *f.Cause)
+	}
+	return nruPad(b), nil
+}
+
+// DecodeNruDdds decodes a DL DATA DELIVERY STATUS frame, fields not produced by the DU are skipped
+func DecodeNruDdds(b []byte) (*NruDlDataDeliveryStatus, error) {
+	if len(b) < 6 || b[0]>>4 != NRU_DL_DATA_DELIVERY_STATUS {
+		return nil, fmt.Errorf("not a DL DATA DELIVERY STATUS frame")
+	}
+	f := &NruDlDataDeliveryStatus{
+		FinalFrame:        b[0]&0x02 != 0,
+		DesiredBufferSize: binary.BigEndian.Uint32(b[2:]),
+	}
+	flags1, flags2 := b[0], b[1]
+	r := b[6:]
+	next := func(n int) ([]byte, error) {
+		if len(r) < n {
+			return nil, fmt.Errorf("DL DATA DELIVERY STATUS frame truncated")
+		}
+		v := r[:n]
+		r = r[n:]
+		return v, nil
+	}
+
+	// desired data rate
+	if flags2&0x08 != 0 {
+		if _, err := next(4); err != nil {
+			return nil, err
+		}
+	}
+	if flags1&0x01 != 0 {
+		n, err := next(1)
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < int(n[0]); i++ {
+			v, err := next(6)
+			if err != nil {
+				return nil, err
+			}
+			f.LostNrUSnRanges = append(f.LostNrUSnRanges, NruSnRange{Start: uint24(v), End: uint24(v[3:])})
+		}
+	}
+	if flags1&0x04 != 0 {
+		v, err := next(3)
+		if err != nil {
+			return nil, err
+		}
+		sn := uint24(v)
+		f.HighestDeliveredPdcpSn = &sn
+	}
+	if flags1&0x08 != 0 {
+		v, err := next(3)
+		if err != nil {
+			return nil, err
+		}
+		sn := uint24(v)
+		f.HighestTransmittedPdcpSn = &sn
+	}
+	if flags2&0x01 != 0 {
+		v, err := next(1)
+		if err != nil {
+			return nil, err
+		}
+		f.Cause = &v[0]
+	}
+	return f, nil
+}
+
+// nruFrame returns the NR-U frame of the NR RAN Container of a GTP-U packet, nil if it has none
+func nruFrame(pkt *GtpuPacket) []byte {
+	for _, ext := range pkt.Extensions {
+		if ext.Type == GTPU_EXT_NR_RAN_CONTAINER && len(ext.Content) > 0 {
+			return ext.Content
+		}
+	}
+	return nil
+}
+
+// nruPad pads an NR-U frame so that it fills a GTP-U extension header of 4 octet units
+func nruPad(b []byte) []byte {
+	for (len(b)+2)%4 != 0 {
+		b = append(b, 0)
+	}
+	return b
+}
+
+func appendUint24(b []byte, v uint32) []byte {
+	return append(b, byte(v>>16), byte(v>>8), byte(v))
+}
+
+func uint24(b []byte) uint32 {
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2])
+}
+
+func boolBit(v bool) byte {
+	if v {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,169 @@
+package du
+
+import (
+	"du_ue/pkg/config"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+const (
+	OVERLOAD_CHECK_INTERVAL  = time.Second
+	DEFAULT_REJECT_WAIT_TIME = 10 // seconds
+)
+
+// OverloadContext tracks the simulated overload state of the DU
+type OverloadContext struct {
+	cfg        config.OverloadConfig
+	msgCount   atomic.Int64 // F1AP messages sent and received in the current interval
+	overloaded atomic.Bool
+	transId    int64
+	stop       chan struct{}
+}
+
+func (du *DU) InitOverloadContext() {
+	du.ovlCtx = &OverloadContext{cfg: du.Config.Overload}
+}
+
+// StartOverloadMonitor starts the periodic overload evaluation if a trigger is configured
+func (du *DU) StartOverloadMonitor() {
+	if du.ovlCtx == nil || du.ovlCtx.stop != nil {
+		return
+	}
+	if du.ovlCtx.cfg.MaxUEs == 0 && du.ovlCtx.cfg.MaxMessageRate == 0 {
+		return
+	}
+
+	du.Info("Starting overload monitor: max UEs=%d, max message rate=%d/s",
+		du.ovlCtx.cfg.MaxUEs, du.ovlCtx.cfg.MaxMessageRate)
+	du.ovlCtx.stop = make(chan struct{})
+	go du.runOverloadMonitor(du.ovlCtx.stop)
+}
+
+// StopOverloadMonitor stops the overload evaluation
+func (du *DU) StopOverloadMonitor() {
+	if du.ovlCtx == nil || du.ovlCtx.stop == nil {
+		return
+	}
+	close(du.ovlCtx.stop)
+	du.ovlCtx.stop = nil
+}
+
+// IsOverloaded reports whether the DU is currently overloaded
+func (du *DU) IsOverloaded() bool {
+	return du.ovlCtx != nil && du.ovlCtx.overloaded.Load()
+}
+
+// countF1apMessage accounts one F1AP message for the message rate trigger
+func (du *DU) countF1apMessage() {
+	if du.ovlCtx != nil {
+		du.ovlCtx.msgCount.Add(1)
+	}
+}
+
+func (du *DU) runOverloadMonitor(stop chan struct{}) {
+	ticker := time.NewTicker(OVERLOAD_CHECK_INTERVAL)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			du.evaluateOverload()
+		}
+	}
+}
+
+// evaluateOverload checks the triggers and sends gNB-DU Status Indication on a change
+func (du *DU) evaluateOverload() {
+	cfg := du.ovlCtx.cfg
+	rate := du.ovlCtx.msgCount.Swap(0) * int64(time.Second/OVERLOAD_CHECK_INTERVAL)
+	ues := du.activeUeCount()
+
+	overloaded := (cfg.MaxUEs > 0 && ues >= cfg.MaxUEs) ||
+		(cfg.MaxMessageRate > 0 && rate >= int64(cfg.MaxMessageRate))
+	if du.ovlCtx.overloaded.Swap(overloaded) == overloaded {
+		return
+	}
+
+	if overloaded {
+		du.Warn("DU overloaded: active UEs=%d, message rate=%d/s", ues, rate)
+	} else {
+		du.Info("DU no longer overloaded: active UEs=%d, message rate=%d/s", ues, rate)
+	}
+	if err := du.sendGnbDuStatusIndication(overloaded); err != nil {
+		du.Error("Failed to send gNB-DU Status Indication: %v", err)
+	}
+}
+
+// sendGnbDuStatusIndication sends gNB-DU Status Indication to CU-CP
+func (du *DU) sendGnbDuStatusIndication(overloaded bool) error {
+	du.Info("Sending gNB-DU Status Indication (overloaded=%v)", overloaded)
+
+	info := ies.GNBDUOverloadInformationNotOverloaded
+	if overloaded {
+		info = ies.GNBDUOverloadInformationOverloaded
+	}
+
+	msg := &ies.GNBDUStatusIndication{
+		TransactionID:            du.ovlCtx.transId,
+		GNBDUOverloadInformation: ies.GNBDUOverloadInformation{Value: info},
+	}
+	du.ovlCtx.transId = (du.ovlCtx.transId + 1) % 256
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode gNB-DU Status Indication: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.f1Client.Send(f1apBytes)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// sendRrcReject rejects an RRC setup of the UE while the DU is overloaded
+func (du *DU) sendRrcReject() error {
+	waitTime := du.ovlCtx.cfg.WaitTime
+	if waitTime == 0 {
+		waitTime = DEFAULT_REJECT_WAIT_TIME
+	}
+	du.Warn("DU overloaded, sending RRCReject with wait time %ds", waitTime)
+
+	dlCcchMsg := rrcies.DL_CCCH_Message{
+		Message: rrcies.DL_CCCH_MessageType{
+			Choice: rrcies.DL_CCCH_MessageType_Choice_C1,
+			C1: &rrcies.DL_CCCH_MessageType_C1{
+				Choice: rrcies.DL_CCCH_MessageType_C1_Choice_RrcReject,
+				RrcReject: &rrcies.RRCReject{
+					CriticalExtensions: rrcies.RRCReject_CriticalExtensions{
+						Choice: rrcies.RRCReject_CriticalExtensions_Choice_RrcReject,
+						RrcReject: &rrcies.RRCReject_IEs{
+							WaitTime: &rrcies.RejectWaitTime{Value: uint64(waitTime)},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	encoded, err := rrc.Encode(&dlCcchMsg)
+	if err != nil {
+		return fmt.Errorf("encode RRCReject: %w", err)
+	}
+
+	if du.ue == nil || du.ue.SendToUeChannel == nil {
+		return fmt.Errorf("UE channel not initialized")
+	}
+	du.traceRrc(TRACE_DL, encoded)
+	du.ue.SendToUeChannel <- encoded
+	return nil
+}
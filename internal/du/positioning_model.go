@@ -0,0 +1,179 @@
+package du
+
+import (
+	"du_ue/pkg/config"
+	"encoding/binary"
+	"math"
+	"time"
+
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+const (
+	speedOfLight   = 299792458.0               // m/s
+	basicTimeUnit  = 1.0 / (480000.0 * 4096.0) // Tc (s), TS 38.211 4.1
+	timingOffsetK0 = 985024                    // reported value of a zero time difference with k=0 (TS 38.133)
+	timingMaxK0    = 1970049
+	ntpEpochOffset = 2208988800 // seconds between 1900-01-01 and 1970-01-01
+	srsCarrierMHz  = 3500.0
+	ueTxPowerDbm   = 23.0
+	frameDuration  = 10 * time.Millisecond
+	slotDuration   = 500 * time.Microsecond // 30 kHz SCS
+	earthRadius    = 6378137.0              // WGS 84 semi-major axis (m)
+
+	// TRP position uncertainty (TS 23.032 6.2): 1 m horizontal and about 1 m vertical, 68% confidence
+	trpUncertaintyCode         = 1
+	trpAltitudeUncertaintyCode = 1
+	trpConfidence              = 68
+)
+
+type vec3 struct {
+	x, y, z float64
+}
+
+// TRPMeasurement is the set of synthetic measurement results of one TRP
+type TRPMeasurement struct {
+	Distance   float64 // m
+	RTOA       int64   // UL RTOA reported value (k=0)
+	GNBRxTx    int64   // gNB Rx-Tx time difference reported value (k=0)
+	AzimuthAoA int64   // 0.1 degree, counter-clockwise from north
+	ZenithAoA  int64   // 0.1 degree, from the zenith
+	SRSRSRP    int64   // UL SRS-RSRP reported value (0..126)
+}
+
+// PositioningModel derives positioning measurements from the configured TRP and UE geometry
+type PositioningModel struct {
+	cfg   config.PositioningConfig
+	start time.Time // SFN initialisation time, also the origin of the UE movement
+}
+
+func NewPositioningModel(cfg config.PositioningConfig) *PositioningModel {
+	return &PositioningModel{cfg: cfg, start: time.Now()}
+}
+
+// TRP returns the configured TRP with the given ID
+func (m *PositioningModel) TRP(id int64) (config.TRPConfig, bool) {
+	for _, trp := range m.cfg.TRPs {
+		if trp.ID == id {
+			return trp, true
+		}
+	}
+	return config.TRPConfig{}, false
+}
+
+// TRPs returns all configured TRPs
+func (m *PositioningModel) TRPs() []config.TRPConfig {
+	return m.cfg.TRPs
+}
+
+// TRPPosition returns the geographical coordinates of a TRP (TS 23.032 ellipsoid point with altitude
+// and uncertainty ellipsoid). The local frame is projected around the origin, which is accurate
+// enough for TRPs a few km apart.
+func (m *PositioningModel) TRPPosition(trp config.TRPConfig) ies.AccessPointPosition {
+	origin := m.cfg.Origin
+	lat := origin.Latitude + trp.Y/earthRadius*180/math.Pi
+	lon := origin.Longitude + trp.X/(earthRadius*math.Cos(origin.Latitude*math.Pi/180))*180/math.Pi
+	lat = math.Max(-90, math.Min(90, lat))
+	lon = math.Mod(lon+540, 360) - 180
+	alt := origin.Altitude + trp.Z
+
+	pos := ies.AccessPointPosition{
+		Latitude:             clampInt(int64(math.Floor(math.Abs(lat)/90*(1<<23))), 0, 1<<23-1),
+		Longitude:            clampInt(int64(math.Floor(lon/360*(1<<24))), -(1 << 23), 1<<23-1),
+		Altitude:             clampInt(int64(math.Round(math.Abs(alt))), 0, 1<<15-1),
+		UncertaintySemiMajor: trpUncertaintyCode,
+		UncertaintySemiMinor: trpUncertaintyCode,
+		UncertaintyAltitude:  trpAltitudeUncertaintyCode,
+		Confidence:           trpConfidence,
+	}
+	if lat < 0 {
+		pos.LatitudeSign.Value = ies.LatitudeSignSouth
+	}
+	if alt < 0 {
+		pos.DirectionOfAltitude.Value = ies.DirectionOfAltitudeDepth
+	}
+	return pos
+}
+
+// UEPosition returns the UE position at the given time
+func (m *PositioningModel) UEPosition(now time.Time) vec3 {
+	t := now.Sub(m.start).Seconds()
+	ue := m.cfg.UE
+	return vec3{
+		x: ue.X + ue.VX*t,
+		y: ue.Y + ue.VY*t,
+		z: ue.Z + ue.VZ*t,
+	}
+}
+
+// Measure computes the measurement results of a TRP for the UE position at the given time
+func (m *PositioningModel) Measure(trp config.TRPConfig, now time.Time) TRPMeasurement {
+	ue := m.UEPosition(now)
+	dx, dy, dz := ue.x-trp.X, ue.y-trp.Y, ue.z-trp.Z
+	d := math.Sqrt(dx*dx + dy*dy + dz*dz)
+
+	// one way propagation delay, the UE is assumed to transmit SRS aligned with the SFN
+	delay := d / speedOfLight
+
+	meas := TRPMeasurement{
+		Distance: d,
+		RTOA:     timingReport(delay),
+		// UE Rx-Tx time difference is assumed zero, the gNB sees the full round trip
+		GNBRxTx: timingReport(2 * delay),
+		SRSRSRP: rsrpReport(d),
+	}
+
+	// arrival direction at the TRP, azimuth counter-clockwise from north in GCS
+	azimuth := math.Atan2(-dx, dy) * 180 / math.Pi
+	if azimuth < 0 {
+		azimuth += 360
+	}
+	meas.AzimuthAoA = clampInt(int64(math.Round(azimuth*10))%3600, 0, 3599)
+	zenith := 90.0
+	if d > 0 {
+		zenith = math.Acos(dz/d) * 180 / math.Pi
+	}
+	meas.ZenithAoA = clampInt(int64(math.Round(zenith*10)), 0, 1799)
+	return meas
+}
+
+// SFNAndSlot returns the system frame number and the 30 kHz slot index at the given time
+func (m *PositioningModel) SFNAndSlot(now time.Time) (int64, int64) {
+	elapsed := now.Sub(m.start)
+	sfn := int64(elapsed/frameDuration) % 1024
+	slot := int64((elapsed % frameDuration) / slotDuration)
+	return sfn, slot
+}
+
+// SFNInitialisationTime returns the time of SFN 0 as a 64 bit NTP timestamp
+func (m *PositioningModel) SFNInitialisationTime() aper.BitString {
+	secs := uint64(m.start.Unix() + ntpEpochOffset)
+	frac := uint64(m.start.Nanosecond()) << 32 / 1e9
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, secs<<32|frac)
+	return aper.BitString{Bytes: b, NumBits: 64}
+}
+
+// timingReport maps a time difference to the k=0 reported value
+func timingReport(seconds float64) int64 {
+	return clampInt(int64(math.Round(seconds/basicTimeUnit))+timingOffsetK0, 0, timingMaxK0)
+}
+
+// rsrpReport maps the free space received power at distance d to the SRS-RSRP reported value
+func rsrpReport(d float64) int64 {
+	d = math.Max(d, 1)
+	pathLoss := 20*math.Log10(d/1000) + 20*math.Log10(srsCarrierMHz) + 32.45
+	rsrp := ueTxPowerDbm - pathLoss
+	return clampInt(int64(math.Floor(rsrp))+157, 0, 126)
+}
+
+func clampInt(v, lb, ub int64) int64 {
+	if v < lb {
+		return lb
+	}
+	if v > ub {
+		return ub
+	}
+	return v
+}
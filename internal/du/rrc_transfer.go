@@ -13,7 +13,6 @@ import (
 var (
 	//FIX: now UE IDs (for single UE simulation)
 	DU_UE_F1AP_ID int64 = 0
-	CU_UE_F1AP_ID int64 = 0
 	C_RNTI        int64 = 1
 )
 
@@ -60,7 +59,7 @@ func (du *DU) sendInitialULRRCMessageTransfer(rrcBytes []byte) error {
 	}
 
 	// Send via SCTP (PPID=62 is already set in Send method)
-	if err := du.f1Client.Send(f1apBytes); err != nil {
+	if err := du.sendUe(f1apBytes, DU_UE_F1AP_ID); err != nil {
 		du.Error("Failed to send Initial UL RRC Message Transfer: %v", err)
 		return err
 	}
@@ -79,7 +78,7 @@ func (du *DU) sendULRRCMessageTransfer(rrcBytes []byte) error {
 
 	// Create UL RRC Message Transfer
 	msg := ies.ULRRCMessageTransfer{
-		GNBCUUEF1APID: CU_UE_F1AP_ID,
+		GNBCUUEF1APID: du.cuUeId.Load(),
 		GNBDUUEF1APID: DU_UE_F1AP_ID,
 		SRBID:         srbID,
 		RRCContainer:  rrcBytes,
@@ -92,5 +91,5 @@ func (du *DU) sendULRRCMessageTransfer(rrcBytes []byte) error {
 	}
 
 	// Send via SCTP (PPID=62 is already set in Send method)
-	return du.f1Client.Send(f1apBytes)
+	return du.sendUe(f1apBytes, DU_UE_F1AP_ID)
 }
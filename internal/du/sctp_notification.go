@@ -0,0 +1,150 @@
+package du
+
+import (
+	"du_ue/internal/common/logger"
+	"encoding/binary"
+	"fmt"
+	"net"
+	"strconv"
+	"syscall"
+
+	"github.com/ishidawataru/sctp"
+)
+
+// Association states of SCTP_ASSOC_CHANGE (RFC 6458 6.1.1)
+const (
+	SCTP_COMM_UP = iota
+	SCTP_COMM_LOST
+	SCTP_RESTART
+	SCTP_SHUTDOWN_COMP
+	SCTP_CANT_STR_ASSOC
+)
+
+// Address states of SCTP_PEER_ADDR_CHANGE (RFC 6458 6.1.2)
+const (
+	SCTP_ADDR_AVAILABLE = iota
+	SCTP_ADDR_UNREACHABLE
+	SCTP_ADDR_REMOVED
+	SCTP_ADDR_ADDED
+	SCTP_ADDR_MADE_PRIM
+	SCTP_ADDR_CONFIRMED
+	SCTP_ADDR_POTENTIALLY_FAILED
+)
+
+const (
+	sctpNotificationHeaderLen = 8   // sn_type, sn_flags, sn_length
+	sockaddrStorageLen        = 128 // struct sockaddr_storage
+)
+
+var peerAddrStates = map[uint32]string{
+	SCTP_ADDR_AVAILABLE:          "available",
+	SCTP_ADDR_UNREACHABLE:        "unreachable",
+	SCTP_ADDR_REMOVED:            "removed",
+	SCTP_ADDR_ADDED:              "added",
+	SCTP_ADDR_MADE_PRIM:          "made primary",
+	SCTP_ADDR_CONFIRMED:          "confirmed",
+	SCTP_ADDR_POTENTIALLY_FAILED: "potentially failed",
+}
+
+// handleNotification handles SCTP notifications received on the F1 association
+func (t *sctpTransport) handleNotification(data []byte) error {
+	if len(data) < sctpNotificationHeaderLen {
+		t.Warn("Short SCTP notification: %d bytes", len(data))
+		return nil
+	}
+
+	switch sctp.SCTPNotificationType(binary.NativeEndian.Uint16(data)) {
+	case sctp.SCTP_ASSOC_CHANGE:
+		return t.handleAssocChange(data)
+	case sctp.SCTP_PEER_ADDR_CHANGE:
+		t.handlePeerAddrChange(data)
+	case sctp.SCTP_SHUTDOWN_EVENT:
+		t.Warn("CU-CP shut down the SCTP association")
+		return fmt.Errorf("%w: peer shutdown", ErrF1ConnectionLost)
+	default:
+		t.Debug("SCTP notification type 0x%x", binary.NativeEndian.Uint16(data))
+	}
+	return nil
+}
+
+// handleAssocChange follows the state of the association. A lost association or a
+// restarted CU-CP is reported to the read loop as an error.
+func (t *sctpTransport) handleAssocChange(data []byte) error {
+	// struct sctp_assoc_change: header, state, error, outbound streams, inbound streams, assoc id
+	if len(data) < sctpNotificationHeaderLen+8 {
+		t.Warn("Short SCTP association change notification: %d bytes", len(data))
+		return nil
+	}
+	state := binary.NativeEndian.Uint16(data[sctpNotificationHeaderLen:])
+	errCode := binary.NativeEndian.Uint16(data[sctpNotificationHeaderLen+2:])
+	ostreams := binary.NativeEndian.Uint16(data[sctpNotificationHeaderLen+4:])
+	istreams := binary.NativeEndian.Uint16(data[sctpNotificationHeaderLen+6:])
+
+	switch state {
+	case SCTP_COMM_UP:
+		t.Info("SCTP association up: %d outbound, %d inbound streams", ostreams, istreams)
+		t.ostreams.Store(uint32(ostreams))
+	case SCTP_RESTART:
+		t.Warn("CU-CP restarted the SCTP association: %d outbound, %d inbound streams", ostreams, istreams)
+		t.ostreams.Store(uint32(ostreams))
+		return ErrF1PeerRestarted
+	case SCTP_COMM_LOST:
+		t.Error("SCTP association lost (error %d)", errCode)
+		return fmt.Errorf("%w: communication lost", ErrF1ConnectionLost)
+	case SCTP_SHUTDOWN_COMP:
+		t.Info("SCTP association shutdown complete")
+		return fmt.Errorf("%w: shutdown complete", ErrF1ConnectionLost)
+	case SCTP_CANT_STR_ASSOC:
+		t.Error("SCTP association could not be started (error %d)", errCode)
+		return fmt.Errorf("%w: cannot start association", ErrF1ConnectionLost)
+	default:
+		t.Warn("SCTP association change state %d", state)
+	}
+	return nil
+}
+
+// handlePeerAddrChange logs path failover events of a multi-homed association
+func (t *sctpTransport) handlePeerAddrChange(data []byte) {
+	// struct sctp_paddr_change: header, sockaddr_storage, state, error, assoc id
+	if len(data) < sctpNotificationHeaderLen+sockaddrStorageLen+8 {
+		t.Warn("Short SCTP peer address change notification: %d bytes", len(data))
+		return
+	}
+	addr := parseSockaddr(data[sctpNotificationHeaderLen : sctpNotificationHeaderLen+sockaddrStorageLen])
+	state := binary.NativeEndian.Uint32(data[sctpNotificationHeaderLen+sockaddrStorageLen:])
+	errCode := binary.NativeEndian.Uint32(data[sctpNotificationHeaderLen+sockaddrStorageLen+4:])
+
+	name, ok := peerAddrStates[state]
+	if !ok {
+		name = "state " + strconv.Itoa(int(state))
+	}
+
+	switch state {
+	case SCTP_ADDR_UNREACHABLE, SCTP_ADDR_POTENTIALLY_FAILED, SCTP_ADDR_REMOVED:
+		t.Warn("CU-CP path %s %s (error %d)", addr, name, errCode)
+	default:
+		t.Info("CU-CP path %s %s", addr, name)
+	}
+}
+
+// parseSockaddr formats an IPv4 or IPv6 sockaddr as host:port
+func parseSockaddr(b []byte) string {
+	family := binary.NativeEndian.Uint16(b)
+	port := int(binary.BigEndian.Uint16(b[2:]))
+
+	switch family {
+	case syscall.AF_INET:
+		return net.JoinHostPort(net.IP(b[4:8]).String(), strconv.Itoa(port))
+	case syscall.AF_INET6:
+		return net.JoinHostPort(net.IP(b[8:24]).String(), strconv.Itoa(port))
+	}
+	return "unknown address family " + strconv.Itoa(int(family))
+}
+
+func NewSctpTransportForTest(streams int) *sctpTransport {
+	return newSctpTransport(nil, 0, nil, 0, streams, logger.InitLogger("info", map[string]string{"mod": "f1ap_client"}))
+}
+
+func (t *sctpTransport) HandleNotificationForTest(data []byte) error {
+	return t.handleNotification(data)
+}
@@ -0,0 +1,343 @@
+package du
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+const (
+	TRACE_UL = "UL"
+	TRACE_DL = "DL"
+)
+
+// TraceContext is the trace activation state of a UE context in the DU
+type TraceContext struct {
+	traceId    []byte
+	depth      int64
+	tceAddress aper.BitString // Trace Collection Entity IP address
+	file       *os.File
+	mutex      sync.Mutex
+}
+
+// record writes one traced message into the trace file
+func (t *TraceContext) record(direction, protocol, name string, data []byte) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.file == nil {
+		return
+	}
+
+	line := fmt.Sprintf("%s %s %-4s %-40s len=%d",
+		time.Now().Format(time.RFC3339Nano), direction, protocol, name, len(data))
+	// minimum trace depth records message names only
+	if t.depth != int64(ies.TraceDepthMinimum) && t.depth != int64(ies.TraceDepthMinimumwithoutvendorspecificextension) {
+		line += " " + hex.EncodeToString(data)
+	}
+	fmt.Fprintln(t.file, line)
+}
+
+func (t *TraceContext) close() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	if t.file != nil {
+		t.file.Close()
+		t.file = nil
+	}
+}
+
+// HandleTraceStart handles Trace Start from CU-CP
+func (du *DU) HandleTraceStart(f1apPdu *f1ap.F1apPdu) error {
+	if f1apPdu.Present != ies.F1apPduInitiatingMessage {
+		du.Error("Invalid F1AP PDU present type for Trace Start")
+		return fmt.Errorf("invalid PDU type")
+	}
+
+	msg, ok := f1apPdu.Message.Msg.(*ies.TraceStart)
+	if !ok {
+		du.Error("Failed to cast message to TraceStart")
+		return fmt.Errorf("invalid message type")
+	}
+
+	du.Info("Trace Start: CU-UE-ID=%d, DU-UE-ID=%d, Trace-ID=%x",
+		msg.GNBCUUEF1APID, msg.GNBDUUEF1APID, msg.TraceActivation.TraceID)
+
+	return du.activateTrace(msg.GNBCUUEF1APID, msg.GNBDUUEF1APID, &msg.TraceActivation)
+}
+
+// HandleDeactivateTrace handles Deactivate Trace from CU-CP
+func (du *DU) HandleDeactivateTrace(f1apPdu *f1ap.F1apPdu) error {
+	if f1apPdu.Present != ies.F1apPduInitiatingMessage {
+		du.Error("Invalid F1AP PDU present type for Deactivate Trace")
+		return fmt.Errorf("invalid PDU type")
+	}
+
+	msg, ok := f1apPdu.Message.Msg.(*ies.DeactivateTrace)
+	if !ok {
+		du.Error("Failed to cast message to DeactivateTrace")
+		return fmt.Errorf("invalid message type")
+	}
+
+	du.Info("Deactivate Trace: CU-UE-ID=%d, DU-UE-ID=%d, Trace-ID=%x",
+		msg.GNBCUUEF1APID, msg.GNBDUUEF1APID, msg.TraceID)
+
+	if du.ue == nil {
+		du.Warn("No active trace for UE")
+		return nil
+	}
+	trace := du.ue.trace.Load()
+	if trace == nil {
+		du.Warn("No active trace for UE")
+		return nil
+	}
+	if !bytes.Equal(trace.traceId, msg.TraceID) {
+		du.Warn("Deactivate Trace for unknown Trace-ID %x", msg.TraceID)
+		return nil
+	}
+
+	du.deactivateTrace()
+	return nil
+}
+
+// activateTrace opens the trace file of a UE and optionally sends Cell Traffic Trace
+func (du *DU) activateTrace(cuUeId, duUeId int64, activation *ies.TraceActivation) error {
+	if du.ue == nil {
+		return fmt.Errorf("UE context not found for trace activation")
+	}
+
+	if du.ue.trace.Load() != nil {
+		du.Warn("Trace already active for UE, replacing it")
+		du.deactivateTrace()
+	}
+
+	dir := du.Config.Trace.Dir
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create trace directory: %w", err)
+	}
+
+	path := filepath.Join(dir, fmt.Sprintf("trace_%x.log", activation.TraceID))
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open trace file: %w", err)
+	}
+
+	trace := &TraceContext{
+		traceId:    activation.TraceID,
+		depth:      int64(activation.TraceDepth.Value),
+		tceAddress: activation.TraceCollectionEntityIPAddress,
+		file:       file,
+	}
+	fmt.Fprintf(file, "# trace %x started, CU-UE-ID=%d, DU-UE-ID=%d, interfaces=%08b, depth=%d\n",
+		activation.TraceID, cuUeId, duUeId, firstByte(activation.InterfacesToTrace.Bytes), activation.TraceDepth.Value)
+	du.ue.trace.Store(trace)
+	du.Info("Trace activated, recording into %s", path)
+
+	if du.Config.Trace.CellTrafficTrace {
+		return du.sendCellTrafficTrace(cuUeId, duUeId, trace)
+	}
+	return nil
+}
+
+// deactivateTrace closes the trace of the UE if any. The trace is detached before it is closed,
+// messages recorded meanwhile by the RRC and F1AP goroutines are dropped by the closed file.
+func (du *DU) deactivateTrace() {
+	if du.ue == nil {
+		return
+	}
+	trace := du.ue.trace.Swap(nil)
+	if trace == nil {
+		return
+	}
+	du.Info("Trace %x deactivated", trace.traceId)
+	trace.close()
+}
+
+// sendCellTrafficTrace sends Cell Traffic Trace to CU-CP
+func (du *DU) sendCellTrafficTrace(cuUeId, duUeId int64, trace *TraceContext) error {
+	du.Info("Sending Cell Traffic Trace")
+
+	msg := cellTrafficTrace{
+		CellTrafficTrace: &ies.CellTrafficTrace{
+			GNBCUUEF1APID: cuUeId,
+			GNBDUUEF1APID: duUeId,
+			TraceID:       ies.TraceID{Value: trace.traceId},
+		},
+		tceAddress: trace.tceAddress,
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Cell Traffic Trace: %w", err)
+	}
+
+	if du.f1Client != nil {
+		return du.sendUe(f1apBytes, duUeId)
+	}
+
+	du.Info("F1 client not available, skipping send (test mode)")
+	return nil
+}
+
+// cellTrafficTrace encodes Cell Traffic Trace with the Trace Collection Entity IP address, which
+// f1-gen cannot encode: its TransportLayerAddress encoder writes nothing
+type cellTrafficTrace struct {
+	*ies.CellTrafficTrace
+	tceAddress aper.BitString
+}
+
+func (msg cellTrafficTrace) Encode(w io.Writer) error {
+	cuUeId := ies.NewINTEGER(msg.GNBCUUEF1APID, aper.Constraint{Lb: 0, Ub: 4294967295}, false)
+	duUeId := ies.NewINTEGER(msg.GNBDUUEF1APID, aper.Constraint{Lb: 0, Ub: 4294967295}, false)
+	tceAddress := ies.NewBITSTRING(msg.tceAddress, aper.Constraint{Lb: 1, Ub: 160}, true)
+	ieList := []ies.F1apMessageIE{
+		{
+			Id:          ies.ProtocolIEID{Value: aper.Integer(ies.ProtocolIEID_GNBCUUEF1APID)},
+			Criticality: ies.Criticality{Value: ies.Criticality_PresentReject},
+			Value:       &cuUeId,
+		},
+		{
+			Id:          ies.ProtocolIEID{Value: aper.Integer(ies.ProtocolIEID_GNBDUUEF1APID)},
+			Criticality: ies.Criticality{Value: ies.Criticality_PresentReject},
+			Value:       &duUeId,
+		},
+		{
+			Id:          ies.ProtocolIEID{Value: aper.Integer(ies.ProtocolIEID_TraceID)},
+			Criticality: ies.Criticality{Value: ies.Criticality_PresentIgnore},
+			Value:       &msg.TraceID,
+		},
+		{
+			Id:          ies.ProtocolIEID{Value: aper.Integer(ies.ProtocolIEID_TraceCollectionEntityIPAddress)},
+			Criticality: ies.Criticality{Value: ies.Criticality_PresentIgnore},
+			Value:       &tceAddress,
+		},
+	}
+	return encodeF1apMessage(w, ies.F1apPduInitiatingMessage, ies.ProcedureCode_CellTrafficTrace, ies.Criticality_PresentIgnore, ieList)
+}
+
+// traceF1ap records a UE associated F1AP message when a trace is active
+func (du *DU) traceF1ap(direction string, data []byte) {
+	if du.ue == nil {
+		return
+	}
+	trace := du.ue.trace.Load()
+	if trace == nil {
+		return
+	}
+
+	pdu, err, _ := f1ap.F1apDecode(data)
+	if err != nil || !isUeAssociatedProcedure(int64(pdu.Message.ProcedureCode.Value)) {
+		return
+	}
+	name := strings.TrimPrefix(fmt.Sprintf("%T", pdu.Message.Msg), "*ies.")
+	trace.record(direction, "F1AP", name, data)
+}
+
+// traceRrc records an RRC message and the NAS messages it carries when a trace is active
+func (du *DU) traceRrc(direction string, rrcBytes []byte) {
+	if du.ue == nil {
+		return
+	}
+	trace := du.ue.trace.Load()
+	if trace == nil {
+		return
+	}
+
+	name := "RRC"
+	if decoded, err := rrc.DecodeAny(rrcBytes); err == nil {
+		name = decoded.Type.String()
+	}
+	trace.record(direction, "RRC", name, rrcBytes)
+
+	for _, nasPdu := range extractNasPdus(direction, rrcBytes) {
+		trace.record(direction, "NAS", "DedicatedNAS-Message", nasPdu)
+	}
+}
+
+// extractNasPdus returns the NAS PDUs embedded in an UL/DL-DCCH message
+func extractNasPdus(direction string, rrcBytes []byte) (pdus [][]byte) {
+	if direction == TRACE_UL {
+		var msg rrcies.UL_DCCH_Message
+		if err := rrc.Decode(rrcBytes, &msg); err != nil || msg.Message.C1 == nil {
+			return
+		}
+		c1 := msg.Message.C1
+		switch c1.Choice {
+		case rrcies.UL_DCCH_MessageType_C1_Choice_RrcSetupComplete:
+			if c1.RrcSetupComplete != nil && c1.RrcSetupComplete.CriticalExtensions.RrcSetupComplete != nil {
+				pdus = append(pdus, c1.RrcSetupComplete.CriticalExtensions.RrcSetupComplete.DedicatedNAS_Message.Value)
+			}
+		case rrcies.UL_DCCH_MessageType_C1_Choice_UlInformationTransfer:
+			if c1.UlInformationTransfer != nil && c1.UlInformationTransfer.CriticalExtensions.UlInformationTransfer != nil &&
+				c1.UlInformationTransfer.CriticalExtensions.UlInformationTransfer.DedicatedNAS_Message != nil {
+				pdus = append(pdus, c1.UlInformationTransfer.CriticalExtensions.UlInformationTransfer.DedicatedNAS_Message.Value)
+			}
+		}
+		return
+	}
+
+	var msg rrcies.DL_DCCH_Message
+	if err := rrc.Decode(rrcBytes, &msg); err != nil || msg.Message.C1 == nil {
+		return
+	}
+	c1 := msg.Message.C1
+	switch c1.Choice {
+	case rrcies.DL_DCCH_MessageType_C1_Choice_DlInformationTransfer:
+		if c1.DlInformationTransfer != nil && c1.DlInformationTransfer.CriticalExtensions.DlInformationTransfer != nil &&
+			c1.DlInformationTransfer.CriticalExtensions.DlInformationTransfer.DedicatedNAS_Message != nil {
+			pdus = append(pdus, c1.DlInformationTransfer.CriticalExtensions.DlInformationTransfer.DedicatedNAS_Message.Value)
+		}
+	case rrcies.DL_DCCH_MessageType_C1_Choice_RrcReconfiguration:
+		if c1.RrcReconfiguration != nil && c1.RrcReconfiguration.CriticalExtensions.RrcReconfiguration != nil &&
+			c1.RrcReconfiguration.CriticalExtensions.RrcReconfiguration.NonCriticalExtension != nil {
+			for _, nasMsg := range c1.RrcReconfiguration.CriticalExtensions.RrcReconfiguration.NonCriticalExtension.DedicatedNAS_MessageList {
+				pdus = append(pdus, nasMsg.Value)
+			}
+		}
+	}
+	return
+}
+
+func isUeAssociatedProcedure(code int64) bool {
+	switch code {
+	case ies.ProcedureCode_UEContextSetup,
+		ies.ProcedureCode_UEContextRelease,
+		ies.ProcedureCode_UEContextModification,
+		ies.ProcedureCode_UEContextModificationRequired,
+		ies.ProcedureCode_UEContextReleaseRequest,
+		ies.ProcedureCode_InitialULRRCMessageTransfer,
+		ies.ProcedureCode_DLRRCMessageTransfer,
+		ies.ProcedureCode_ULRRCMessageTransfer,
+		ies.ProcedureCode_UEInactivityNotification,
+		ies.ProcedureCode_RRCDeliveryReport,
+		ies.ProcedureCode_Notify,
+		ies.ProcedureCode_TraceStart,
+		ies.ProcedureCode_DeactivateTrace,
+		ies.ProcedureCode_CellTrafficTrace:
+		return true
+	}
+	return false
+}
+
+func firstByte(b []byte) byte {
+	if len(b) == 0 {
+		return 0
+	}
+	return b[0]
+}
@@ -1,6 +1,7 @@
 package du
 
 import (
+	"du_ue/internal/uecontext"
 	"fmt"
 
 	f1ap "github.com/JocelynWS/f1-gen"
@@ -32,20 +33,31 @@ func (du *DU) HandleUeContextSetupRequest(f1apPdu *f1ap.F1apPdu) error {
 	du.Info("UE Context Setup Request: CU-UE-ID=%d, DU-UE-ID=%d",
 		msg.GNBCUUEF1APID, msg.GNBDUUEF1APID)
 
+	// Activate trace if requested along with the UE context
+	if msg.TraceActivation != nil {
+		if err := du.activateTrace(msg.GNBCUUEF1APID, DU_UE_F1AP_ID, msg.TraceActivation); err != nil {
+			du.Error("Failed to activate trace: %v", err)
+		}
+	}
+
 	// Extract RRC container if present (RRCReconfiguration)
 	if len(msg.RRCContainer) > 0 {
 		du.Info("UE Context Setup Request contains RRC container, forwarding to UE")
 		if du.ue != nil && du.ue.SendToUeChannel != nil {
+			du.traceRrc(TRACE_DL, msg.RRCContainer)
 			du.ue.SendToUeChannel <- msg.RRCContainer
 		}
 	}
 
+	// Set up the F1-U tunnels of the requested DRBs
+	drbs, failed := du.setupDrbs(du.ue, msg.DRBsToBeSetupList)
+
 	// Send UE Context Setup Response
 	duUeId := int64(DU_UE_F1AP_ID)
 	if msg.GNBDUUEF1APID != nil {
 		duUeId = *msg.GNBDUUEF1APID
 	}
-	return du.sendUeContextSetupResponse(msg.GNBCUUEF1APID, duUeId)
+	return du.sendUeContextSetupResponse(msg.GNBCUUEF1APID, duUeId, drbs, failed)
 }
 
 // handleTargetHandoverSetup handles handover preparation at Target DU
@@ -56,7 +68,7 @@ func (du *DU) handleTargetHandoverSetup(msg *ies.UEContextSetupRequest) error {
 	if msg.GNBDUUEF1APID != nil {
 		DU_UE_F1AP_ID = *msg.GNBDUUEF1APID
 	}
-	CU_UE_F1AP_ID = msg.GNBCUUEF1APID
+	du.cuUeId.Store(msg.GNBCUUEF1APID)
 
 	// Allocate resources
 	du.Info("[TARGET DU] Allocating resources for handover UE")
@@ -68,11 +80,14 @@ func (du *DU) handleTargetHandoverSetup(msg *ies.UEContextSetupRequest) error {
 	// Create UE context
 	du.createTargetHandoverUeContext()
 
+	// Set up the F1-U tunnels of the DRBs to hand over
+	drbs, failed := du.setupDrbs(du.ue, msg.DRBsToBeSetupList)
+
 	// Start RACH monitoring
 	du.StartRachMonitoring()
 
 	// Send response
-	return du.sendUeContextSetupResponse(msg.GNBCUUEF1APID, DU_UE_F1AP_ID)
+	return du.sendUeContextSetupResponse(msg.GNBCUUEF1APID, DU_UE_F1AP_ID, drbs, failed)
 }
 
 // allocateHandoverResources allocates resources for handover UE
@@ -91,11 +106,14 @@ func (du *DU) createTargetHandoverUeContext() {
 		fromUE := make(chan []byte, 100)
 
 		du.ue = &UeChannel{
-			ReceiveFromUeChannel: fromUE,
-			SendToUeChannel:      toUE,
+			ReceiveFromUeChannel:     fromUE,
+			SendToUeChannel:          toUE,
+			ReceiveDataFromUeChannel: make(chan uecontext.DrbPdu, 1000),
+			SendDataToUeChannel:      make(chan uecontext.DrbPdu, 1000),
 		}
 
 		go du.HandleRrcFromUE()
+		go du.HandleDataFromUE()
 	}
 
 	du.Info("[TARGET DU] Ready, waiting for UE RACH")
@@ -117,15 +135,15 @@ func (du *DU) sendUeContextSetupFailure(cuUeId, duUeId int64) error {
 
 	// Send only if f1Client is available (for testing)
 	if du.f1Client != nil {
-		return du.f1Client.Send(f1apBytes)
+		return du.sendUe(f1apBytes, duUeId)
 	}
 
 	du.Info("F1 client not available, skipping send (test mode)")
 	return nil
 }
 
-// sendUeContextSetupResponse sends UE Context Setup Response to CU-CP
-func (du *DU) sendUeContextSetupResponse(cuUeId, duUeId int64) error {
+// sendUeContextSetupResponse sends UE Context Setup Response to CU-CP with the DRBs set up and failed
+func (du *DU) sendUeContextSetupResponse(cuUeId, duUeId int64, drbs []ies.DRBsSetupItem, failed []ies.DRBsFailedToBeSetupItem) error {
 	du.Info("Sending UE Context Setup Response")
 
 	// Build PLMN Identity (3 bytes)
@@ -182,6 +200,8 @@ func (du *DU) sendUeContextSetupResponse(cuUeId, duUeId int64) error {
 		DUtoCURRCInformation:        duToCuRrcInfo,
 		CRNTI:                       &crnti,
 		RequestedTargetCellGlobalID: nrcgi,
+		DRBsSetupList:               drbs,
+		DRBsFailedToBeSetupList:     failed,
 	}
 
 	// Encode the message
@@ -192,7 +212,7 @@ func (du *DU) sendUeContextSetupResponse(cuUeId, duUeId int64) error {
 
 	// Send only if f1Client is available (for testing)
 	if du.f1Client != nil {
-		return du.f1Client.Send(f1apBytes)
+		return du.sendUe(f1apBytes, duUeId)
 	}
 
 	du.Info("F1 client not available, skipping send (test mode)")
@@ -28,6 +28,8 @@ func (du *DU) HandleDlRrcMessageTransfer(f1apPdu *f1ap.F1apPdu) error {
 
 	du.Info("DL RRC Message Transfer: CU-UE-ID=%d, DU-UE-ID=%d, SRB-ID=%d",
 		msg.GNBCUUEF1APID, msg.GNBDUUEF1APID, msg.SRBID)
+	// the CU-CP allocates its UE ID with the first DL message of each RRC connection
+	du.cuUeId.Store(msg.GNBCUUEF1APID)
 
 	// Extract RRC container and forward to UE
 	if len(msg.RRCContainer) == 0 {
@@ -38,6 +40,7 @@ func (du *DU) HandleDlRrcMessageTransfer(f1apPdu *f1ap.F1apPdu) error {
 	// Forward RRC message to UE via channel
 	if du.ue != nil && du.ue.SendToUeChannel != nil {
 		du.Info("Forwarding RRC message to UE, length: %d", len(msg.RRCContainer))
+		du.traceRrc(TRACE_DL, msg.RRCContainer)
 		du.ue.SendToUeChannel <- msg.RRCContainer
 	} else {
 		du.Error("UE channel not initialized")
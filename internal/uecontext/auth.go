@@ -12,8 +12,35 @@ const (
 	AUTH_SUCCESS uint8 = iota
 	AUTH_MAC_FAILURE
 	AUTH_SYNC_FAILURE
+	AUTH_NON_5G_FAILURE
 )
 
+// AuthErrorStats counts the authentication requests of the network a UE did not accept
+type AuthErrorStats struct {
+	MalformedRequests uint64 // missing or invalid mandatory IE, answered by 5GMM Status #96
+	NgKsiInUse        uint64 // Authentication Failure #71
+	Non5GAuth         uint64 // Authentication Failure #26, separation bit of the AMF field not set
+	MacFailures       uint64 // Authentication Failure #20
+	SynchFailures     uint64 // Authentication Failure #21
+}
+
+// AuthErrors returns the authentication error counters of the UE
+func (ue *UeContext) AuthErrors() AuthErrorStats {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	return ue.authErrors
+}
+
+// countAuthError updates the authentication error counters and logs them
+func (ue *UeContext) countAuthError(count func(stats *AuthErrorStats)) {
+	ue.mutex.Lock()
+	count(&ue.authErrors)
+	stats := ue.authErrors
+	ue.mutex.Unlock()
+	ue.Warn("Authentication errors: malformed %d, ngKSI in use %d, non-5G %d, MAC failure %d, synch failure %d",
+		stats.MalformedRequests, stats.NgKsiInUse, stats.Non5GAuth, stats.MacFailures, stats.SynchFailures)
+}
+
 type AuthContext struct {
 	supi     string
 	snn      []byte
@@ -36,20 +63,26 @@ func (auth *AuthContext) processAuthenticationInfo(autn, abba []byte) (errCode u
 
 	//2.derive netSqn, netMacA from autn
 	netSqn := make([]byte, 6)
-	//netAmf := autn[6:8]
+	netAmf := autn[6:8]
 	sqnXorAk := autn[0:6]
 	netMacA := autn[8:]
 	for i := range 6 {
 		netSqn[i] = sqnXorAk[i] ^ ak[i]
 	}
 
-	//3. calculate MacA and verify
-	macA, _, _ := auth.milenage.F1(netSqn, auth.amf)
+	//3. calculate MacA over the AMF field of the network and verify
+	macA, _, _ := auth.milenage.F1(netSqn, netAmf)
 	if !bytes.Equal(macA, netMacA) {
 		errCode = AUTH_MAC_FAILURE
 		return
 	}
 
+	//3.1 check the separation bit of the AMF field for 5G (TS 33.501 6.1.3.2)
+	if netAmf[0]&0x80 == 0 {
+		errCode = AUTH_NON_5G_FAILURE
+		return
+	}
+
 	//4. check for sqn sync
 	tmpSqn := new(sec.Sqn)
 	tmpSqn.Set(netSqn)                                 //calculate net sqn in int64
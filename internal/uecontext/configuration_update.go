@@ -0,0 +1,259 @@
+package uecontext
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/reogac/nas"
+)
+
+const (
+	// Configuration update indication bits (TS 24.501 9.11.3.18)
+	CONFIGURATION_UPDATE_ACK = 0x01 // acknowledgement requested
+	CONFIGURATION_UPDATE_RED = 0x02 // registration requested
+
+	// Network slicing indication bits (TS 24.501 9.11.3.36)
+	NETWORK_SLICING_NSSCI = 0x01 // network slicing subscription changed
+)
+
+// ladn is a local area data network of the LADN information, with the tracking areas it is available in
+type ladn struct {
+	dnn     string
+	taiList *nas.TrackingAreaIdentityList
+}
+
+// handleConfigurationUpdateCommand applies the parameters of a generic UE configuration update
+// (TS 24.501 5.4.4): 5G-GUTI, TAI list, allowed and configured NSSAI, network name and LADN
+// information. Configuration Update Complete is sent when the network asks for it, and with
+// "registration requested" a mobility registration update follows the release of the RRC connection.
+func (ue *UeContext) handleConfigurationUpdateCommand(message *nas.ConfigurationUpdateCommand) {
+	var indication uint8
+	if message.ConfigurationUpdateIndication != nil {
+		indication = *message.ConfigurationUpdateIndication
+	}
+
+	if message.Guti != nil {
+		ue.set5gGuti(message.Guti)
+		ue.Info("New 5G-GUTI: %s", ue.guti.String())
+	}
+	ue.setTaiList(message.TaiList)
+
+	ue.mutex.Lock()
+	if message.AllowedNssai != nil {
+		ue.allowedNssai = message.AllowedNssai
+	}
+	if message.ConfiguredNssai != nil {
+		ue.configuredNssai = message.ConfiguredNssai
+	}
+	ue.mutex.Unlock()
+	if message.AllowedNssai != nil {
+		ue.Info("Allowed NSSAI: %s", nssaiString(message.AllowedNssai))
+	}
+	if message.ConfiguredNssai != nil {
+		ue.Info("Configured NSSAI: %s", nssaiString(message.ConfiguredNssai))
+	}
+	if message.NetworkSlicingIndication != nil && *message.NetworkSlicingIndication&NETWORK_SLICING_NSSCI != 0 {
+		ue.Info("Network slicing subscription changed")
+	}
+
+	ue.setNetworkName(message.FullNameForNetwork, message.ShortNameForNetwork)
+	if message.LadnInformation != nil {
+		ladns, err := decodeLadnInformation(message.LadnInformation.Bytes)
+		if err != nil {
+			ue.Warn("Invalid LADN information: %v", err)
+		} else {
+			ue.mutex.Lock()
+			ue.ladns = ladns
+			ue.mutex.Unlock()
+			for _, l := range ladns {
+				ue.Info("LADN %s in %d partial TAI lists", l.dnn, len(l.taiList.Lists))
+			}
+		}
+	}
+	ue.SaveState()
+
+	if indication&CONFIGURATION_UPDATE_ACK != 0 {
+		response := &nas.ConfigurationUpdateComplete{}
+		response.SetSecurityHeader(nas.NasSecBoth)
+		if responsePdu, err := nas.EncodeMm(ue.getNasContext(), response); err != nil {
+			ue.Error("Error encoding configuration update complete: %v", err)
+		} else {
+			ue.Send_UlInformationTransfer_To_Du(responsePdu)
+			ue.Info("Configuration Update Complete sent")
+		}
+	}
+
+	if indication&CONFIGURATION_UPDATE_RED != 0 {
+		ue.updateRegistrationAfterRelease()
+	}
+}
+
+// setNetworkName stores the full and short network names, the previous ones are kept when absent
+func (ue *UeContext) setNetworkName(full, short *nas.NetworkName) {
+	if full != nil {
+		if name, err := decodeNetworkName(full.Bytes); err != nil {
+			ue.Warn("Invalid full network name: %v", err)
+		} else {
+			ue.mutex.Lock()
+			ue.fullNetworkName = name
+			ue.mutex.Unlock()
+			ue.Info("Full network name: %s", name)
+		}
+	}
+	if short != nil {
+		if name, err := decodeNetworkName(short.Bytes); err != nil {
+			ue.Warn("Invalid short network name: %v", err)
+		} else {
+			ue.mutex.Lock()
+			ue.shortNetworkName = name
+			ue.mutex.Unlock()
+			ue.Info("Short network name: %s", name)
+		}
+	}
+}
+
+// decodeNetworkName returns the text of a network name (TS 24.501 9.11.3.35), coded in the GSM
+// default alphabet or in UCS2
+func decodeNetworkName(value []byte) (string, error) {
+	if len(value) < 1 {
+		return "", fmt.Errorf("network name is empty")
+	}
+	coding := value[0] >> 4 & 0x07
+	spareBits := int(value[0] & 0x07)
+	text := value[1:]
+	switch coding {
+	case 0:
+		return decodeGsm7(text, (len(text)*8-spareBits)/7), nil
+	case 1:
+		if len(text)%2 != 0 {
+			return "", fmt.Errorf("odd UCS2 length %d", len(text))
+		}
+		chars := make([]uint16, len(text)/2)
+		for i := range chars {
+			chars[i] = uint16(text[2*i])<<8 | uint16(text[2*i+1])
+		}
+		return string(utf16.Decode(chars)), nil
+	}
+	return "", fmt.Errorf("unknown coding scheme %d", coding)
+}
+
+// decodeGsm7 unpacks n characters of the GSM 7 bit default alphabet (TS 23.038). Letters, digits
+// and most punctuation have their ASCII codes; other characters are replaced by '?'.
+func decodeGsm7(packed []byte, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		bit := i * 7
+		c := packed[bit/8] >> (bit % 8)
+		if bit%8 > 1 && bit/8+1 < len(packed) {
+			c |= packed[bit/8+1] << (8 - bit%8)
+		}
+		c &= 0x7f
+		switch {
+		case c == 0x00:
+			b.WriteByte('@')
+		case c == 0x02:
+			b.WriteByte('$')
+		case c == 0x11:
+			b.WriteByte('_')
+		case c == 0x0a || c == 0x0d:
+			b.WriteByte(' ')
+		case c >= 0x20 && c != 0x24 && c != 0x40 && c < 0x5b, c >= 0x61 && c < 0x7b:
+			b.WriteByte(c)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
+
+// decodeLadnInformation returns the LADNs of LADN information (TS 24.501 9.11.3.30): each one is
+// a DNN followed by a 5GS tracking area identity list
+func decodeLadnInformation(value []byte) ([]ladn, error) {
+	var ladns []ladn
+	for offset := 0; offset < len(value); {
+		dnnLen := int(value[offset])
+		offset++
+		if offset+dnnLen >= len(value) {
+			return nil, fmt.Errorf("DNN of LADN %d incomplete", len(ladns)+1)
+		}
+		dnn := decodeDnn(value[offset : offset+dnnLen])
+		offset += dnnLen
+
+		taiLen := int(value[offset])
+		offset++
+		if offset+taiLen > len(value) {
+			return nil, fmt.Errorf("TAI list of LADN %s incomplete", dnn)
+		}
+		taiList, err := decodeTaiList(value[offset : offset+taiLen])
+		if err != nil {
+			return nil, fmt.Errorf("TAI list of LADN %s: %w", dnn, err)
+		}
+		offset += taiLen
+		ladns = append(ladns, ladn{dnn: dnn, taiList: taiList})
+	}
+	return ladns, nil
+}
+
+// decodeDnn returns the DNN of a sequence of length prefixed labels
+func decodeDnn(value []byte) string {
+	var labels []string
+	for offset := 0; offset < len(value); {
+		n := int(value[offset])
+		offset++
+		if offset+n > len(value) {
+			n = len(value) - offset
+		}
+		labels = append(labels, string(value[offset:offset+n]))
+		offset += n
+	}
+	return strings.Join(labels, ".")
+}
+
+// decodeTaiList decodes the value of a 5GS tracking area identity list. The nas package only
+// decodes the list inside a message, so it is wrapped in a plain Configuration Update Command.
+func decodeTaiList(value []byte) (*nas.TrackingAreaIdentityList, error) {
+	wire := []byte{nas.EPD_5GMM, nas.NasSecNone, nas.ConfigurationUpdateCommandMsgType, 0x54, uint8(len(value))}
+	msg, err := nas.Decode(nil, append(wire, value...))
+	if err != nil {
+		return nil, err
+	}
+	if msg.Gmm == nil || msg.Gmm.ConfigurationUpdateCommand == nil || msg.Gmm.ConfigurationUpdateCommand.TaiList == nil {
+		return nil, fmt.Errorf("no TAI list")
+	}
+	return msg.Gmm.ConfigurationUpdateCommand.TaiList, nil
+}
+
+func nssaiString(nssai *nas.Nssai) string {
+	var list []string
+	for _, snssai := range nssai.List {
+		if sd := snssai.GetSd(); sd != "" {
+			list = append(list, fmt.Sprintf("%d/%s", snssai.Sst, sd))
+		} else {
+			list = append(list, fmt.Sprintf("%d", snssai.Sst))
+		}
+	}
+	return "[" + strings.Join(list, " ") + "]"
+}
+
+func (ue *UeContext) NetworkNameForTest() (full, short string) {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	return ue.fullNetworkName, ue.shortNetworkName
+}
+
+func (ue *UeContext) LadnsForTest() map[string][]uint32 {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	ladns := make(map[string][]uint32)
+	for _, l := range ue.ladns {
+		var tacs []uint32
+		for _, partial := range l.taiList.Lists {
+			if list, ok := partial.(*nas.TaiListType0); ok {
+				tacs = append(tacs, list.TacList...)
+			}
+		}
+		ladns[l.dnn] = tacs
+	}
+	return ladns
+}
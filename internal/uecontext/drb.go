@@ -0,0 +1,247 @@
+package uecontext
+
+import (
+	"fmt"
+
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// DrbPdu is a user plane packet carried on a data radio bearer between the UE and the DU
+type DrbPdu struct {
+	DrbId int64
+	Data  []byte
+}
+
+// sdapDrb is the SDAP configuration of a DRB (TS 37.324): the PDU session it carries, the QoS
+// flows mapped to it and whether SDAP headers are used
+type sdapDrb struct {
+	sessionId  uint8
+	headerUL   bool
+	headerDL   bool
+	defaultDrb bool
+	qfis       map[uint8]bool
+}
+
+const sdapHeaderLen = 1
+
+// applyRadioBearerConfig records the SDAP configuration of the DRBs of an RRCReconfiguration
+func (ue *UeContext) applyRadioBearerConfig(cfg *rrcies.RadioBearerConfig) {
+	if cfg == nil {
+		return
+	}
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+
+	if ue.drbs == nil {
+		ue.drbs = make(map[int64]*sdapDrb)
+	}
+	if cfg.Drb_ToReleaseList != nil {
+		for _, drbId := range cfg.Drb_ToReleaseList.Value {
+			ue.Info("DRB %d released", drbId.Value)
+			delete(ue.drbs, int64(drbId.Value))
+		}
+	}
+	if cfg.Drb_ToAddModList != nil {
+		for _, drb := range cfg.Drb_ToAddModList.Value {
+			cn := drb.CnAssociation
+			if cn == nil || cn.Choice != rrcies.DRB_ToAddMod_cnAssociation_Choice_Sdap_Config || cn.Sdap_Config == nil {
+				continue
+			}
+			ue.applySdapConfig(int64(drb.Drb_Identity.Value), cn.Sdap_Config)
+		}
+	}
+}
+
+// applySdapConfig adds or modifies the SDAP configuration of a DRB, ue.mutex locked
+func (ue *UeContext) applySdapConfig(drbId int64, cfg *rrcies.SDAP_Config) {
+	sessionId := uint8(cfg.Pdu_Session.Value)
+	drb := ue.drbs[drbId]
+	if drb == nil {
+		drb = &sdapDrb{qfis: make(map[uint8]bool)}
+		ue.drbs[drbId] = drb
+		ue.Info("DRB %d added for PDU session %d", drbId, sessionId)
+	}
+	drb.sessionId = sessionId
+	drb.headerUL = cfg.Sdap_HeaderUL.Value == rrcies.SDAP_Config_sdap_HeaderUL_Enum_present
+	drb.headerDL = cfg.Sdap_HeaderDL.Value == rrcies.SDAP_Config_sdap_HeaderDL_Enum_present
+	drb.defaultDrb = cfg.DefaultDRB
+
+	for _, qfi := range cfg.MappedQoS_FlowsToRelease {
+		delete(drb.qfis, uint8(qfi.Value))
+	}
+	for _, qfi := range cfg.MappedQoS_FlowsToAdd {
+		// a QoS flow is mapped to one DRB of the session
+		for _, other := range ue.drbs {
+			if other.sessionId == sessionId {
+				delete(other.qfis, uint8(qfi.Value))
+			}
+		}
+		drb.qfis[uint8(qfi.Value)] = true
+	}
+
+	// another default DRB of the session stops being the default
+	if drb.defaultDrb {
+		for id, other := range ue.drbs {
+			if id != drbId && other.sessionId == sessionId {
+				other.defaultDrb = false
+			}
+		}
+	}
+	ue.Info("DRB %d SDAP: QFIs %v, default %v, UL header %v, DL header %v",
+		drbId, sortedQfis(drb.qfis), drb.defaultDrb, drb.headerUL, drb.headerDL)
+}
+
+// drbOfSession returns a DRB carrying a PDU session
+func (ue *UeContext) drbOfSession(sessionId uint8) (int64, bool) {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	for drbId, drb := range ue.drbs {
+		if drb.sessionId == sessionId {
+			return drbId, true
+		}
+	}
+	return 0, false
+}
+
+// drbOfFlow returns the DRB a QoS flow of a PDU session is mapped to: the DRB of the QFI, else
+// the default DRB of the session, else the only DRB of the session
+func (ue *UeContext) drbOfFlow(sessionId, qfi uint8) (int64, *sdapDrb, bool) {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+
+	var defaultId, onlyId int64
+	var defaultDrb, onlyDrb *sdapDrb
+	count := 0
+	for drbId, drb := range ue.drbs {
+		if drb.sessionId != sessionId {
+			continue
+		}
+		if drb.qfis[qfi] {
+			return drbId, drb, true
+		}
+		if drb.defaultDrb {
+			defaultId, defaultDrb = drbId, drb
+		}
+		onlyId, onlyDrb = drbId, drb
+		count++
+	}
+	if defaultDrb != nil {
+		return defaultId, defaultDrb, true
+	}
+	if count == 1 {
+		return onlyId, onlyDrb, true
+	}
+	return 0, nil, false
+}
+
+// sessionOfDrb returns the PDU session carried by a DRB and whether its DL PDUs have an SDAP header
+func (ue *UeContext) sessionOfDrb(drbId int64) (*PduSession, bool) {
+	ue.mutex.Lock()
+	drb, ok := ue.drbs[drbId]
+	ue.mutex.Unlock()
+	if !ok {
+		return nil, false
+	}
+	return ue.getPduSession(drb.sessionId), drb.headerDL
+}
+
+// listenForDrbData runs in a goroutine to deliver DL user plane packets to their PDU session
+func (ue *UeContext) listenForDrbData() {
+	for {
+		select {
+		case pdu, ok := <-ue.ReceiveDataFromDuChannel:
+			if !ok {
+				return
+			}
+			session, headerDL := ue.sessionOfDrb(pdu.DrbId)
+			if session == nil {
+				ue.Warn("DL data on DRB %d without PDU session, dropped", pdu.DrbId)
+				continue
+			}
+			data := pdu.Data
+			if headerDL {
+				if len(data) < sdapHeaderLen {
+					continue
+				}
+				// RDI, RQI and QFI of the SDAP data PDU
+				ue.Debug("DL SDAP PDU on DRB %d: QFI %d", pdu.DrbId, data[0]&0x3f)
+				data = data[sdapHeaderLen:]
+			}
+			session.receive(data)
+		case <-ue.ctx.Done():
+			return
+		}
+	}
+}
+
+// sendUlData sends a UL user plane packet of a PDU session on the DRB of its QoS flow
+func (ue *UeContext) sendUlData(sessionId uint8, data []byte) error {
+	session := ue.getPduSession(sessionId)
+	if session == nil {
+		return fmt.Errorf("no PDU session %d", sessionId)
+	}
+	if ue.isRrcIdle() {
+		// UL data from RRC idle needs the user plane back first
+		ue.requestUserPlane()
+		return fmt.Errorf("UE in RRC idle, service request started")
+	}
+	qfi, ok := session.classifyUl(data)
+	if !ok {
+		return fmt.Errorf("no QoS rule matches the packet")
+	}
+	drbId, drb, ok := ue.drbOfFlow(sessionId, qfi)
+	if !ok {
+		return fmt.Errorf("no DRB for QFI %d of PDU session %d", qfi, sessionId)
+	}
+	if ue.SendDataToDuChannel == nil {
+		return fmt.Errorf("no user plane channel to DU")
+	}
+
+	if drb.headerUL {
+		// D/C set for a data PDU, R, QFI
+		data = append([]byte{0x80 | qfi&0x3f}, data...)
+	}
+	select {
+	case ue.SendDataToDuChannel <- DrbPdu{DrbId: drbId, Data: data}:
+		return nil
+	case <-ue.ctx.Done():
+		return ue.ctx.Err()
+	}
+}
+
+func sortedQfis(qfis map[uint8]bool) []uint8 {
+	var list []uint8
+	for qfi := uint8(0); qfi < 64; qfi++ {
+		if qfis[qfi] {
+			list = append(list, qfi)
+		}
+	}
+	return list
+}
+
+func (ue *UeContext) AddPduSessionForTest(sessionId uint8, ip []byte, drbId int64) *PduSession {
+	ps := NewPduSession(ue, sessionId)
+	ps.setIp(ip)
+	ps.state = PDUSessionActive
+
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	ue.sessions[sessionId] = ps
+	if ue.drbs == nil {
+		ue.drbs = make(map[int64]*sdapDrb)
+	}
+	ue.drbs[drbId] = &sdapDrb{sessionId: sessionId, defaultDrb: true, qfis: make(map[uint8]bool)}
+	return ps
+}
+
+func (ue *UeContext) StartUserPlaneForTest() {
+	go ue.listenForDrbData()
+}
+
+func (ue *UeContext) PduSessionForTest(sessionId uint8) *PduSession {
+	return ue.getPduSession(sessionId)
+}
+
+func (ue *UeContext) SendUlDataForTest(sessionId uint8, data []byte) error {
+	return ue.sendUlData(sessionId, data)
+}
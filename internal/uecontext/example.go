@@ -2,6 +2,7 @@ package uecontext
 
 import (
 	"du_ue/pkg/config"
+	"fmt"
 	"time"
 )
 
@@ -14,13 +15,65 @@ func CreateUEs(cfg *config.UEConfig) []*UeContext {
 
 type EventType string
 
+const (
+	EVENT_CELL_RESELECTION EventType = "cell_reselection"
+	EVENT_DEREGISTRATION   EventType = "deregistration"
+)
+
 type EventInfo struct {
 	EventType EventType
 	Delay     time.Duration
+	Pci       uint16 // target cell of a cell reselection
+	SwitchOff bool   // switch off de-registration
+}
+
+// eventsOf returns the events of the UE configuration
+func eventsOf(events []config.EventConfig) []EventInfo {
+	infos := make([]EventInfo, 0, len(events))
+	for _, event := range events {
+		infos = append(infos, EventInfo{
+			EventType: EventType(event.Type),
+			Delay:     time.Duration(event.Delay) * time.Millisecond,
+			Pci:       event.PCI,
+			SwitchOff: event.SwitchOff,
+		})
+	}
+	return infos
 }
 
+// TriggerEvents runs an event once its delay has passed
 func (ue *UeContext) TriggerEvents(event *EventInfo) {
-	// For loop
-	// ue.TriggerRegistrationRequest()
-	// event.Delay excute
+	select {
+	case <-time.After(event.Delay):
+	case <-ue.ctx.Done():
+		return
+	}
+	var err error
+	switch event.EventType {
+	case EVENT_CELL_RESELECTION:
+		err = ue.ReselectCell(event.Pci)
+	case EVENT_DEREGISTRATION:
+		err = ue.TriggerDeregistration(event.SwitchOff)
+	default:
+		err = fmt.Errorf("unknown event")
+	}
+	if err != nil {
+		ue.Error("Event %s failed: %v", event.EventType, err)
+	}
+}
+
+// startEvents runs the configured events one after the other, once for the life of the UE
+func (ue *UeContext) startEvents() {
+	ue.mutex.Lock()
+	events := ue.events
+	ue.events = nil
+	ue.mutex.Unlock()
+	if len(events) == 0 {
+		return
+	}
+	go func() {
+		for i := range events {
+			ue.TriggerEvents(&events[i])
+		}
+	}()
 }
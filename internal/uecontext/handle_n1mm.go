@@ -1,6 +1,8 @@
 package uecontext
 
 import (
+	"bytes"
+	"fmt"
 	"time"
 
 	"github.com/reogac/nas"
@@ -53,8 +55,10 @@ func (ue *UeContext) handleNas_n1mm(nasMsg *nas.NasMessage) {
 
 	case nas.RegistrationAcceptMsgType:
 		ue.Info("Receive Registration Accept")
+		ue.registrationCompleted()
 		ue.handleRegistrationAccept(gmm.RegistrationAccept)
 		ue.SetState(UE_STATE_REGISTERED)
+		ue.SaveState()
 
 	case nas.RegistrationRejectMsgType:
 		ue.Error("Receive Registration Reject")
@@ -66,10 +70,30 @@ func (ue *UeContext) handleNas_n1mm(nasMsg *nas.NasMessage) {
 		ue.handleAuthenticationReject(gmm.AuthenticationReject)
 		ue.SetState(UE_STATE_DEREGISTERED)
 
+	case nas.DeregistrationAcceptFromUeMsgType:
+		ue.Info("Receive Deregistration Accept")
+		ue.deregisterLocally()
+
+	case nas.DeregistrationRequestToUeMsgType:
+		ue.Info("Receive Deregistration Request")
+		ue.handleDeregistrationRequest(gmm.DeregistrationRequestToUe)
+
+	case nas.ServiceAcceptMsgType:
+		ue.Info("Receive Service Accept")
+		ue.handleServiceAccept(gmm.ServiceAccept)
+
+	case nas.ServiceRejectMsgType:
+		ue.Error("Receive Service Reject")
+		ue.handleServiceReject(gmm.ServiceReject)
+
 	case nas.GmmStatusMsgType:
 		ue.Error("Receive Status 5GMM")
 		ue.handleGmmStatus(gmm.GmmStatus)
 
+	case nas.ConfigurationUpdateCommandMsgType:
+		ue.Info("Receive Configuration Update Command")
+		ue.handleConfigurationUpdateCommand(gmm.ConfigurationUpdateCommand)
+
 	case nas.DlNasTransportMsgType:
 		ue.Info("Receive DL NAS Transport")
 		ue.handleDlNasTransport(gmm.DlNasTransport)
@@ -88,40 +112,228 @@ func (ue *UeContext) handleCause5GMM(cause *uint8) {
 func (ue *UeContext) handleAuthenticationReject(message *nas.AuthenticationReject) {
 	_ = message
 	ue.Error("Authentication of UE failed")
+	ue.stopTimer(&ue.t3510)
 	ue.SetState(UE_STATE_DEREGISTERED)
-	ue.ResetSecurityContext()
+	ue.deleteIdentity()
 }
 
+// handleRegistrationReject ends a rejected registration as the 5GMM cause requires (TS 24.501
+// 5.5.1.2.5, 5.5.1.3.5). Causes not handled specifically are abnormal cases retried with the
+// registration attempt counter.
 func (ue *UeContext) handleRegistrationReject(message *nas.RegistrationReject) {
 	ue.handleCause5GMM(&message.GmmCause)
+	ue.stopTimer(&ue.t3510)
+	if message.T3502Value != nil {
+		t3502, _ := gprsTimer2(message.T3502Value)
+		ue.mutex.Lock()
+		ue.timers.t3502 = t3502
+		ue.mutex.Unlock()
+	}
+	ue.SetState(UE_STATE_DEREGISTERED)
+	ue.stopT3512()
+	switch message.GmmCause {
+	case nas.Cause5GMMUEIdentityCannotBeDerivedByTheNetwork:
+		// the network does not know the 5G-GUTI, register again with the SUCI
+		ue.deleteIdentity()
+		ue.registerAfterRelease()
+		return
+	case nas.Cause5GMMCongestion:
+		// without T3346 the reject is an abnormal case
+		if message.T3346Value != nil {
+			if t3346, active := gprsTimer2(message.T3346Value); active && t3346 > 0 {
+				// the registration attempt counter is kept, the registration runs again after the back-off
+				ue.Warn("Registration Reject, congestion, retry in %v (T3346)", t3346)
+				ue.startTimer(&ue.t3346, t3346, ue.retryRegistration)
+				return
+			}
+		}
+	case CAUSE_5GMM_NO_NETWORK_SLICES_AVAILABLE:
+		// the rejected S-NSSAIs are not requested again, the UE has no other slice to try
+		ue.resetRegistrationAttempts()
+		ue.removeRejectedNssai(message.RejectedNssai)
+		ue.Warn("Registration Reject, no network slices available, registration not retried")
+		ue.SaveState()
+		return
+	}
+
+	dropIdentity, retry := registrationRejectAction(message.GmmCause)
+	if retry {
+		// abnormal case (TS 24.501 5.5.1.2.7 i)
+		ue.registrationFailed("Registration Reject, cause " + cause5GMMToString(message.GmmCause))
+		return
+	}
+	ue.resetRegistrationAttempts()
+	if dropIdentity {
+		ue.deleteIdentity()
+		return
+	}
+	ue.SaveState()
+}
+
+// registrationRejectAction returns whether a Registration Reject cause makes the UE delete its
+// 5G-GUTI and security context, and whether the cause is an abnormal case the UE retries
+func registrationRejectAction(cause uint8) (dropIdentity, retry bool) {
+	switch cause {
+	case nas.Cause5GMMIllegalUE, nas.Cause5GMMIllegalME, nas.Cause5GMM5GSServicesNotAllowed:
+		// the USIM is considered invalid for 5GS services
+		return true, false
+	case nas.Cause5GMMPLMNNotAllowed, nas.Cause5GMMN1ModeNotAllowed, nas.Cause5GMMServingNetworkNotAuthorized:
+		return true, false
+	case nas.Cause5GMMTrackingAreaNotAllowed, nas.Cause5GMMRoamingNotAllowedInThisTrackingArea,
+		nas.Cause5GMMNoSuitableCellsInTrackingArea:
+		// a single tracking area is simulated, there is no other cell to select
+		return true, false
+	case CAUSE_5GMM_REDIRECTION_TO_EPC_REQUIRED:
+		// the UE has no E-UTRA access to register in EPC
+		return false, false
+	}
+	return false, true
+}
+
+// removeRejectedNssai removes the S-NSSAIs of a rejected NSSAI from the allowed and configured NSSAI
+// the UE requests in its Registration Request
+func (ue *UeContext) removeRejectedNssai(rejected *nas.RejectedNssai) {
+	if rejected == nil {
+		return
+	}
+	isRejected := func(slice nas.SNssai) bool {
+		for _, r := range rejected.List {
+			if r.Sst == slice.Sst && bytes.Equal(r.Sd, slice.Sd) {
+				return true
+			}
+		}
+		return false
+	}
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	for _, nssai := range []*nas.Nssai{ue.allowedNssai, ue.configuredNssai} {
+		if nssai == nil {
+			continue
+		}
+		kept := nssai.List[:0]
+		for _, slice := range nssai.List {
+			if !isRejected(slice) {
+				kept = append(kept, slice)
+			}
+		}
+		nssai.List = kept
+	}
+	for _, r := range rejected.List {
+		ue.Info("Rejected S-NSSAI SST %d SD %x, cause %d", r.Sst, r.Sd, r.Cause)
+	}
+}
+
+// handleDeregistrationRequest handles a network-initiated de-registration (TS 24.501 5.5.2.3): the
+// UE accepts it, releases its PDU sessions locally and, depending on the 5GMM cause, deletes its
+// 5G-GUTI and security context. With "re-registration required" a new initial registration is run
+// once the network has released the RRC connection.
+func (ue *UeContext) handleDeregistrationRequest(message *nas.DeregistrationRequestToUe) {
+	dereg := message.DeRegistrationType
+	reregistration := dereg.GetReregistration()
+	ue.Info("De-registration type: re-registration required %v, access type %d", reregistration, dereg.GetAccessType())
+	if message.GmmCause != nil {
+		ue.Info("De-registration cause: %s", cause5GMMToString(*message.GmmCause))
+	}
+
+	response := &nas.DeregistrationAcceptToUe{}
+	nasCtx := ue.getNasContext()
+	if nasCtx != nil {
+		response.SetSecurityHeader(nas.NasSecBoth)
+	} else {
+		response.SetSecurityHeader(nas.NasSecNone)
+	}
+	if responsePdu, err := nas.EncodeMm(nasCtx, response); err != nil {
+		ue.Error("Error encoding deregistration accept: %v", err)
+	} else {
+		ue.Send_UlInformationTransfer_To_Du(responsePdu)
+		ue.Info("Deregistration Accept sent")
+	}
+
+	if dereg.GetAccessType() == DEREGISTRATION_ACCESS_NON_3GPP {
+		// the registration over 3GPP access is not affected
+		return
+	}
+
+	ue.releaseAllPduSessionsLocally()
 	ue.SetState(UE_STATE_DEREGISTERED)
-	ue.ResetSecurityContext()
+	ue.stopT3512()
+	// a registration or de-registration of the UE in progress ends with it
+	ue.stopTimer(&ue.t3510)
+	ue.stopTimer(&ue.t3521)
+
+	dropIdentity, mayReregister := false, true
+	if message.GmmCause != nil {
+		dropIdentity, mayReregister = deregistrationCauseAction(*message.GmmCause)
+	}
+	if dropIdentity {
+		ue.deleteIdentity()
+	}
+	if !reregistration {
+		return
+	}
+	if !mayReregister {
+		ue.Warn("Re-registration required but not allowed after this cause")
+		return
+	}
+
+	ue.registerAfterRelease()
+}
+
+// deregistrationCauseAction returns whether a de-registration cause makes the UE delete its
+// 5G-GUTI and security context, and whether the UE may register again afterwards
+func deregistrationCauseAction(cause uint8) (dropIdentity, mayReregister bool) {
+	switch cause {
+	case nas.Cause5GMMIllegalUE, nas.Cause5GMMIllegalME, nas.Cause5GMM5GSServicesNotAllowed:
+		// the USIM is considered invalid for 5GS services
+		return true, false
+	case nas.Cause5GMMPLMNNotAllowed, nas.Cause5GMMN1ModeNotAllowed:
+		return true, false
+	case nas.Cause5GMMTrackingAreaNotAllowed, nas.Cause5GMMRoamingNotAllowedInThisTrackingArea,
+		nas.Cause5GMMNoSuitableCellsInTrackingArea:
+		// a single tracking area is simulated, there is no other cell to select
+		return true, false
+	case nas.Cause5GMMImplicitlyDeregistered:
+		return true, true
+	}
+	return false, true
 }
 
 func (ue *UeContext) handleGmmStatus(message *nas.GmmStatus) {
 	ue.handleCause5GMM(&message.GmmCause)
 }
 
+// handleAuthenticationRequest runs the 5G AKA of the UE (TS 24.501 5.4.1.3). A request with a
+// missing or invalid mandatory IE is answered by 5GMM Status #96 (TS 24.501 7.5); an ngKSI already
+// used by the security context of the UE or an AUTN without the separation bit gives an
+// Authentication Failure.
 func (ue *UeContext) handleAuthenticationRequest(message *nas.AuthenticationRequest) {
 	var responsePdu []byte
 	var response nas.GmmMessage
 
-	if message.Ngksi.Id == 7 {
-		ue.Fatal("Error in Authentication Request, ngKSI not the expected value")
+	switch {
+	case message.Ngksi.Id == 7:
+		ue.rejectMalformedMessage("Authentication Request", "ngKSI 7 (no key available)")
+		return
+	case len(message.Abba) < 2:
+		ue.rejectMalformedMessage("Authentication Request", "ABBA missing or shorter than 2 octets")
+		return
+	case len(message.AuthenticationParameterRand) != 16:
+		ue.rejectMalformedMessage("Authentication Request", "RAND missing or not 16 octets")
+		return
+	case len(message.AuthenticationParameterAutn) != 16:
+		ue.rejectMalformedMessage("Authentication Request", "AUTN missing or not 16 octets")
+		return
 	}
 
-	if len(message.Abba) == 0 {
-		ue.Fatal("Error in Authentication Request, ABBA Content is empty")
-	}
-	if message.AuthenticationParameterRand == nil {
-		ue.Fatal("Error in Authentication Request, RAND is missing")
+	if ue.secCtx != nil && ue.secCtx.MatchNgKsi(&message.Ngksi) && !bytes.Equal(message.AuthenticationParameterRand, ue.auth.rand) {
+		// a retransmitted request repeats the RAND, a new one must not reuse the ngKSI (TS 24.501 5.4.1.3.5)
+		ue.Warn("ngKSI %d of Authentication Request already in use, send authentication failure", message.Ngksi.Id)
+		ue.countAuthError(func(stats *AuthErrorStats) { stats.NgKsiInUse++ })
+		ue.sendAuthenticationFailure(nas.Cause5GMMngKSIAlreadyInUse, nil)
+		return
 	}
 
-	if message.AuthenticationParameterAutn == nil {
-		ue.Fatal("Error in Authentication Request, AUTN is missing")
-	}
-	// getting NgKsi, RAND and AUTN from the message.
-	ue.auth.ngKsi = message.Ngksi
+	// getting RAND and AUTN from the message, the ngKSI is kept once the network is authenticated
 	ue.auth.rand = message.AuthenticationParameterRand
 	ue.auth.milenage.SetRand(ue.auth.rand)
 
@@ -135,21 +347,22 @@ func (ue *UeContext) handleAuthenticationRequest(message *nas.AuthenticationRequ
 	case AUTH_MAC_FAILURE:
 		ue.Info("Authenticity of the authentication request message: FAILED")
 		ue.Info("Send authentication failure with MAC failure")
-		msg := &nas.AuthenticationFailure{
-			GmmCause: nas.Cause5GMMMACFailure,
-		}
-		msg.SetSecurityHeader(nas.NasSecNone)
-		response = msg
+		ue.countAuthError(func(stats *AuthErrorStats) { stats.MacFailures++ })
+		ue.sendAuthenticationFailure(nas.Cause5GMMMACFailure, nil)
+		return
+	case AUTH_NON_5G_FAILURE:
+		ue.Info("Separation bit of the AMF field in AUTN: 0")
+		ue.Info("Send authentication failure with non-5G authentication unacceptable")
+		ue.countAuthError(func(stats *AuthErrorStats) { stats.Non5GAuth++ })
+		ue.sendAuthenticationFailure(nas.Cause5GMMNon5GAuthenticationUnacceptable, nil)
+		return
 	case AUTH_SYNC_FAILURE:
 		ue.Info("Authenticity of the authentication request message: OK")
 		ue.Info("SQN of the authentication request message: INVALID")
 		ue.Info("Send authentication failure with Synch failure")
-		msg := &nas.AuthenticationFailure{
-			GmmCause:                       nas.Cause5GMMSynchFailure,
-			AuthenticationFailureParameter: paramDat,
-		}
-		msg.SetSecurityHeader(nas.NasSecNone)
-		response = msg
+		ue.countAuthError(func(stats *AuthErrorStats) { stats.SynchFailures++ })
+		ue.sendAuthenticationFailure(nas.Cause5GMMSynchFailure, paramDat)
+		return
 
 	case AUTH_SUCCESS:
 		ue.Info("Authenticity of the authentication request message: OK")
@@ -161,6 +374,7 @@ func (ue *UeContext) handleAuthenticationRequest(message *nas.AuthenticationRequ
 		msg.SetSecurityHeader(nas.NasSecNone)
 		response = msg
 		// create an inactive security context
+		ue.auth.ngKsi = message.Ngksi
 		ue.secCtx = sec.NewSecurityContext(&ue.auth.ngKsi, ue.auth.kamf, false)
 	}
 
@@ -168,6 +382,39 @@ func (ue *UeContext) handleAuthenticationRequest(message *nas.AuthenticationRequ
 	ue.Send_UlInformationTransfer_To_Du(responsePdu)
 }
 
+func (ue *UeContext) sendAuthenticationFailure(cause uint8, failureParameter []byte) {
+	msg := &nas.AuthenticationFailure{
+		GmmCause:                       cause,
+		AuthenticationFailureParameter: failureParameter,
+	}
+	msg.SetSecurityHeader(nas.NasSecNone)
+	if responsePdu, err := nas.EncodeMm(nil, msg); err != nil {
+		ue.Error("Error encoding authentication failure: %v", err)
+	} else {
+		ue.Send_UlInformationTransfer_To_Du(responsePdu)
+	}
+}
+
+// rejectMalformedMessage answers a message with a missing or invalid mandatory IE by 5GMM Status
+// #96 "invalid mandatory information" and otherwise ignores it (TS 24.501 7.5)
+func (ue *UeContext) rejectMalformedMessage(name, reason string) {
+	ue.Error("Error in %s, %s: send 5GMM status", name, reason)
+	ue.countAuthError(func(stats *AuthErrorStats) { stats.MalformedRequests++ })
+
+	msg := &nas.GmmStatus{GmmCause: nas.Cause5GMMInvalidMandatoryInformation}
+	nasCtx := ue.getNasContext()
+	if nasCtx != nil {
+		msg.SetSecurityHeader(nas.NasSecBoth)
+	} else {
+		msg.SetSecurityHeader(nas.NasSecNone)
+	}
+	if statusPdu, err := nas.EncodeMm(nasCtx, msg); err != nil {
+		ue.Error("Error encoding 5GMM status: %v", err)
+	} else {
+		ue.Send_UlInformationTransfer_To_Du(statusPdu)
+	}
+}
+
 func (ue *UeContext) handleSecurityModeCommand(message *nas.SecurityModeCommand) {
 	//check for existing NgKsi
 	if message.Ngksi.Id == 7 || ue.auth.ngKsi.Id != message.Ngksi.Id || ue.auth.ngKsi.Tsc != message.Ngksi.Tsc {
@@ -208,13 +455,10 @@ func (ue *UeContext) handleSecurityModeCommand(message *nas.SecurityModeCommand)
 	//derive NasContext keys (then the security context is activated)
 	ue.secCtx.NasContext(true).DeriveKeys(algs.EncAlg(), algs.IntAlg(), ue.secCtx.Kamf())
 
-	// Fixed IMEISV value
-	imeisv := nas.Imei{IsSv: true}
-	imeisv.Parse("1110000000000000") // fixed dummy imei
-	response := &nas.SecurityModeComplete{
-		Imeisv: &nas.MobileIdentity{
-			Id: &imeisv,
-		},
+	response := &nas.SecurityModeComplete{}
+	if message.ImeisvRequest != nil && *message.ImeisvRequest&0x01 != 0 {
+		// IMEISV requested (TS 24.501 9.11.3.28)
+		response.Imeisv = &nas.MobileIdentity{Id: ue.imeiIdentity(true)}
 	}
 	nasCtx := ue.getNasContext()
 	// Include registration request in NasMessageContainer if RINMR bit is set
@@ -238,6 +482,20 @@ func (ue *UeContext) handleRegistrationAccept(message *nas.RegistrationAccept) {
 		ue.Warn("UE was not assigned a 5G-GUTI by AMF")
 	}
 
+	ue.setTaiList(message.TaiList)
+	ue.mutex.Lock()
+	if message.AllowedNssai != nil {
+		ue.allowedNssai = message.AllowedNssai
+	}
+	if message.ConfiguredNssai != nil {
+		ue.configuredNssai = message.ConfiguredNssai
+	}
+	ue.mutex.Unlock()
+	ue.applyRegistrationTimers(message)
+	if message.PduSessionStatus != nil {
+		ue.syncPduSessionStatus(message.PduSessionStatus)
+	}
+
 	// Send Registration Complete
 	response := &nas.RegistrationComplete{}
 	response.SetSecurityHeader(nas.NasSecBoth)
@@ -246,6 +504,15 @@ func (ue *UeContext) handleRegistrationAccept(message *nas.RegistrationAccept) {
 	ue.Send_UlInformationTransfer_To_Du(responsePdu)
 
 	ue.Info("Registration Complete sent")
+	ue.startEvents()
+
+	ue.mutex.Lock()
+	registrationType := ue.registrationType
+	ue.mutex.Unlock()
+	if registrationType != nas.RegistrationType5GSInitialRegistration {
+		// the PDU sessions of the UE are kept by a registration update
+		return
+	}
 
 	// After registration is complete, trigger PDU Session Establishment
 	go func() {
@@ -259,20 +526,25 @@ func (ue *UeContext) handleRegistrationAccept(message *nas.RegistrationAccept) {
 	}()
 }
 
+// handleIdentityRequest answers an Identity Request with the requested identity (TS 24.501 5.4.3).
+// Without a NAS security context only the SUCI is sent (TS 24.501 4.4.4.2).
 func (ue *UeContext) handleIdentityRequest(message *nas.IdentityRequest) {
-	switch uint8(message.IdentityType) {
-	case nas.MobileIdentity5GSTypeSuci:
-		ue.Info("Requested SUCI 5GS type")
-	default:
-		ue.Error("Only SUCI identity is supported")
+	identityType := uint8(message.IdentityType) & 0x07
+	nasCtx := ue.getNasContext()
+	if nasCtx == nil && identityType != nas.MobileIdentity5GSTypeSuci {
+		ue.Warn("Identity type %d requested without NAS security context, request ignored", identityType)
 		return
 	}
+	identity, err := ue.mobileIdentity(identityType)
+	if err != nil {
+		ue.Error("Identity Request: %v", err)
+		return
+	}
+	ue.Info("Requested identity type %d: %s", identityType, identity.String())
 
-	// Fixed SUCI response
 	rsp := &nas.IdentityResponse{
-		MobileIdentity: ue.suci,
+		MobileIdentity: identity,
 	}
-	nasCtx := ue.getNasContext()
 	if nasCtx != nil {
 		rsp.SetSecurityHeader(nas.NasSecBoth)
 	} else {
@@ -286,6 +558,38 @@ func (ue *UeContext) handleIdentityRequest(message *nas.IdentityRequest) {
 	}
 }
 
+// mobileIdentity returns an identity of the UE by 5GS mobile identity type
+func (ue *UeContext) mobileIdentity(identityType uint8) (nas.MobileIdentity, error) {
+	switch identityType {
+	case nas.MobileIdentity5GSTypeSuci:
+		return ue.concealSuci()
+	case nas.MobileIdentity5GSTypeImei, nas.MobileIdentity5GSTypeImeisv:
+		return nas.MobileIdentity{Id: ue.imeiIdentity(identityType == nas.MobileIdentity5GSTypeImeisv)}, nil
+	case nas.MobileIdentity5GSType5gSTmsi:
+		ue.mutex.Lock()
+		guti := ue.guti
+		ue.mutex.Unlock()
+		if guti == nil {
+			return nas.MobileIdentity{}, fmt.Errorf("no 5G-GUTI for the 5G-S-TMSI")
+		}
+		return nas.MobileIdentity{Id: &nas.Tmsi5Gs{AmfId: guti.AmfId, Tmsi: guti.Tmsi}}, nil
+	case nas.MobileIdentity5GSTypeMac:
+		return nas.MobileIdentity{Id: &nas.MacIdentity{Bytes: ue.mac}}, nil
+	}
+	return nas.MobileIdentity{}, fmt.Errorf("identity type %d not supported", identityType)
+}
+
+// imeiIdentity returns the IMEI or IMEISV of the UE
+func (ue *UeContext) imeiIdentity(sv bool) *nas.Imei {
+	id := &nas.Imei{IsSv: sv}
+	if sv {
+		id.Parse(ue.imeisv)
+	} else {
+		id.Parse(ue.imei)
+	}
+	return id
+}
+
 func (ue *UeContext) handleDlNasTransport(message *nas.DlNasTransport) {
 	if uint8(message.PayloadContainerType) != nas.PayloadContainerTypeN1SMInfo {
 		ue.Error("Error in DL NAS Transport, Payload Container Type not expected value")
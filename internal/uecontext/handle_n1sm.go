@@ -72,11 +72,28 @@ func (ue *UeContext) handlePduSessionEstablishmentAccept(msg *nas.PduSessionEsta
 		pduSession.Info("PDU address received: %s", pduSession.ueIP)
 	}
 
-	ue.Info("  QoS Rules: %v", msg.AuthorizedQosRules.Bytes)
-	// Get QoS Rules (comment out if field doesn't exist)
-	// if msg.AuthorizedQosRules != nil {
-	// 	pduSession.Info("PDU session QoS RULES received")
-	// }
+	// Get QoS Rules
+	if rules, err := decodeQosRules(msg.AuthorizedQosRules.Bytes); err != nil {
+		pduSession.Error("Failed to decode QoS rules: %v", err)
+	} else {
+		pduSession.applyQosRules(rules)
+		for _, rule := range rules {
+			pduSession.Info("PDU session QoS %s", &rule)
+		}
+	}
+
+	// Get QoS Flow Descriptions
+	if msg.AuthorizedQosFlowDescriptions != nil {
+		if flows, err := decodeQosFlowDescriptions(msg.AuthorizedQosFlowDescriptions.Bytes); err != nil {
+			pduSession.Error("Failed to decode QoS flow descriptions: %v", err)
+		} else {
+			pduSession.applyQosFlowDescriptions(flows)
+			for _, f := range flows {
+				pduSession.Info("PDU session QoS flow QFI %d: 5QI %d, GFBR UL/DL %d/%d kbit/s, MFBR UL/DL %d/%d kbit/s",
+					f.Qfi, f.FiveQi, f.GfbrUl, f.GfbrDl, f.MfbrUl, f.MfbrDl)
+			}
+		}
+	}
 
 	// Get DNN
 	if msg.Dnn != nil {
@@ -93,6 +110,13 @@ func (ue *UeContext) handlePduSessionEstablishmentAccept(msg *nas.PduSessionEsta
 	// Change state to ACTIVE
 	pduSession.SetState(PDUSessionActive)
 	pduSession.Info("PDU Session established successfully")
+
+	if ue.tun.Enabled {
+		go ue.openConfiguredTun(pduSession)
+	}
+	if ue.traffic.Type != "" {
+		go ue.runConfiguredTraffic(pduSession, "after registration")
+	}
 }
 
 // handlePduSessionEstablishmentReject processes PDU Session Establishment Reject
@@ -49,6 +49,12 @@ func (ue *UeContext) handleDLDCCHMessage(msg *rrcies.DL_DCCH_Message) error {
 			return ue.handleRRCReconfiguration(c1.RrcReconfiguration)
 		}
 
+	case rrcies.DL_DCCH_MessageType_C1_Choice_RrcRelease:
+		// Connection released, e.g. after de-registration
+		if c1.RrcRelease != nil {
+			return ue.handleRRCRelease(c1.RrcRelease)
+		}
+
 	case rrcies.DL_DCCH_MessageType_C1_Choice_SecurityModeCommand:
 		// Handle SecurityModeCommand (AS security, not NAS)
 		ue.Info("Received SecurityModeCommand (AS security)")
@@ -98,7 +104,14 @@ func (ue *UeContext) handleRRCReconfiguration(msg *rrcies.RRCReconfiguration) er
 		ies := msg.CriticalExtensions.RrcReconfiguration
 		if ies != nil {
 			ue.Info("RRCReconfiguration IEs received")
-			// TODO: Handle radio bearer config, measurement config, etc. if needed
+			ue.applyRadioBearerConfig(ies.RadioBearerConfig)
+			if sync := reconfigurationWithSync(ies); sync != nil {
+				// handover command: access the target cell, then report completion there
+				ue.Info("RRCReconfiguration with sync, handover to PCI %d", handoverTargetPci(sync))
+				go ue.performRandomAccess(handoverTargetPci(sync))
+				return nil
+			}
+			// TODO: Handle measurement config, etc. if needed
 		}
 	}
 
@@ -159,6 +172,7 @@ func (ue *UeContext) sendUlInformationTransfer(nasPdu []byte) error {
 	}
 	
 	// Send to DU
+	ue.saveNasCounts(nasPdu)
 	ue.SendToDuChannel <- encoded
 	ue.Info("UL Information Transfer sent successfully, RRC length: %d", len(encoded))
 	return nil
@@ -2,23 +2,35 @@ package uecontext
 
 import (
 	"context"
+	"du_ue/internal/common/logger"
 	"du_ue/pkg/config"
 	"fmt"
+	"time"
 
 	"github.com/lvdund/asn1go/aper"
 	"github.com/lvdund/rrc"
 	rrcies "github.com/lvdund/rrc/ies"
 )
 
-func InitUE(toUE, fromUE chan []byte, ue_config config.UEConfig) *UeContext {
+func InitUE(ctx context.Context, toUE, fromUE chan []byte, toUEData, fromUEData chan DrbPdu, ue_config config.UEConfig) *UeContext {
 	// Channel mapping:
 	// toUE = DU -> UE (DU sends to UE, UE receives from DU)
 	// fromUE = UE -> DU (UE sends to DU, DU receives from UE)
+	// toUEData / fromUEData = the same for user plane packets on DRBs
 
-	ue := CreateUe(ue_config, context.Background())
+	ue, err := CreateUe(ue_config, ctx)
+	if err != nil {
+		logger.InitLogger("", map[string]string{"mod": "ue", "msin": ue_config.MSIN}).Error("Failed to create UE: %v", err)
+		return nil
+	}
 
 	ue.ReceiveFromDuChannel = toUE // UE receives RRC messages from DU
 	ue.SendToDuChannel = fromUE    // UE sends RRC messages to DU
+	ue.ReceiveDataFromDuChannel = toUEData
+	ue.SendDataToDuChannel = fromUEData
+	if toUEData != nil {
+		go ue.listenForDrbData()
+	}
 
 	// Send RRCSetupRequest to DU
 	if err := ue.InitRRCConn(); err != nil {
@@ -26,12 +38,40 @@ func InitUE(toUE, fromUE chan []byte, ue_config config.UEConfig) *UeContext {
 		return nil
 	}
 
-	// Block waiting for RRCSetup from DU
-	ue.Info("Waiting for RRCSetup from DU...")
-	rrcSetupBytes, ok := <-ue.ReceiveFromDuChannel
-	if !ok {
-		ue.Error("ReceiveFromDuChannel closed while waiting for RRCSetup")
-		return nil
+	// Block waiting for RRCSetup from DU, an RRCReject delays a new attempt by its wait time
+	var rrcSetupBytes []byte
+	for {
+		ue.Info("Waiting for RRCSetup from DU...")
+		var rrcBytes []byte
+		select {
+		case msg, ok := <-ue.ReceiveFromDuChannel:
+			if !ok {
+				ue.Error("ReceiveFromDuChannel closed while waiting for RRCSetup")
+				return nil
+			}
+			rrcBytes = msg
+		case <-ctx.Done():
+			ue.Warn("Context cancelled while waiting for RRCSetup")
+			return nil
+		}
+
+		waitTime, rejected := rrcRejectWaitTime(rrcBytes)
+		if !rejected {
+			rrcSetupBytes = rrcBytes
+			break
+		}
+
+		ue.Warn("Received RRCReject, retrying RRC connection in %v", waitTime)
+		select {
+		case <-time.After(waitTime):
+		case <-ctx.Done():
+			ue.Warn("Context cancelled while waiting to retry RRC connection")
+			return nil
+		}
+		if err := ue.InitRRCConn(); err != nil {
+			ue.Error("Failed to initialize RRC connection: %v", err)
+			return nil
+		}
 	}
 
 	// Decode and handle RRCSetup
@@ -53,6 +93,15 @@ func (ue *UeContext) listenForRrcMessages() {
 				ue.Info("ReceiveFromDuChannel closed, stopping RRC listener")
 				return
 			}
+			if ue.isRrcIdle() {
+				// RRCSetup or RRCReject of the RRC connection being established
+				select {
+				case ue.ccch <- rrcMessageBytes:
+				default:
+					ue.Warn("DL-CCCH message without RRC connection establishment, dropped")
+				}
+				continue
+			}
 			if err := ue.HandleRrcMsg(rrcMessageBytes); err != nil {
 				ue.Error("Failed to handle RRC message: %v", err)
 			}
@@ -64,19 +113,33 @@ func (ue *UeContext) listenForRrcMessages() {
 }
 
 func (ue *UeContext) InitRRCConn() error {
+	return ue.initRRCConn(rrcies.EstablishmentCause_Enum_mo_Signalling)
+}
+
+// initRRCConn sends RRCSetupRequest, identifying the UE by its 5G-S-TMSI when it has one
+func (ue *UeContext) initRRCConn(cause aper.Enumerated) error {
 	ue.Info("Initializing RRC connection")
 
+	ueIdentity := rrcies.InitialUE_Identity{
+		Choice: rrcies.InitialUE_Identity_Choice_RandomValue,
+		RandomValue: aper.BitString{
+			Bytes:   []byte{0x1A, 0x2B, 0x3C, 0x4D, 0x5E},
+			NumBits: 39,
+		},
+	}
+	if sTmsi, ok := ue.get5gSTmsi(); ok {
+		// rightmost 39 bits, the leftmost 9 bits follow in RRCSetupComplete
+		ueIdentity = rrcies.InitialUE_Identity{
+			Choice:             rrcies.InitialUE_Identity_Choice_Ng_5G_S_TMSI_Part1,
+			Ng_5G_S_TMSI_Part1: bitString(sTmsi&(1<<39-1), 39),
+		}
+	}
+
 	rrcSetupRequest := rrcies.RRCSetupRequest{
 		RrcSetupRequest: rrcies.RRCSetupRequest_IEs{
-			Ue_Identity: rrcies.InitialUE_Identity{
-				Choice: rrcies.InitialUE_Identity_Choice_RandomValue,
-				RandomValue: aper.BitString{
-					Bytes:   []byte{0x1A, 0x2B, 0x3C, 0x4D, 0x5E},
-					NumBits: 39,
-				},
-			},
+			Ue_Identity: ueIdentity,
 			EstablishmentCause: rrcies.EstablishmentCause{
-				Value: rrcies.EstablishmentCause_Enum_mo_Signalling,
+				Value: cause,
 			},
 			Spare: aper.BitString{
 				Bytes:   []byte{0x00},
@@ -106,6 +169,24 @@ func (ue *UeContext) InitRRCConn() error {
 	return nil
 }
 
+// rrcRejectWaitTime returns the wait time of an RRCReject, or false if the message is not an RRCReject
+func rrcRejectWaitTime(rrcBytes []byte) (time.Duration, bool) {
+	var msg rrcies.DL_CCCH_Message
+	if err := rrc.Decode(rrcBytes, &msg); err != nil || msg.Message.C1 == nil {
+		return 0, false
+	}
+	if msg.Message.C1.Choice != rrcies.DL_CCCH_MessageType_C1_Choice_RrcReject || msg.Message.C1.RrcReject == nil {
+		return 0, false
+	}
+
+	// without a wait time T302 is not started, retry after a short pause anyway
+	waitTime := time.Second
+	if ies := msg.Message.C1.RrcReject.CriticalExtensions.RrcReject; ies != nil && ies.WaitTime != nil {
+		waitTime = time.Duration(ies.WaitTime.Value) * time.Second
+	}
+	return waitTime, true
+}
+
 // handleRRCSetup handles RRCSetup message received from DU
 // This is called directly from InitUE after blocking on ReceiveFromDuChannel
 func (ue *UeContext) handleRRCSetup(rrcSetupBytes []byte) error {
@@ -155,32 +236,49 @@ func (ue *UeContext) handleRRCSetup(rrcSetupBytes []byte) error {
 	ue.auth.snn = []byte(deriveSNN(ue.mcc, ue.mnc))
 
 	// Trigger registration to create NAS Registration Request
-	if err := ue.TriggerInitRegistration(); err != nil {
+	nasPdu, err := ue.TriggerInitRegistration()
+	if err != nil {
 		ue.Error("Failed to trigger registration: %v", err)
 		return err
 	}
-	ue.Info("Created NAS Registration Request, length: %d bytes", len(ue.nasPdu))
+	ue.Info("Created NAS Registration Request, length: %d bytes", len(nasPdu))
 
 	// Send RRCSetupComplete with NAS Registration Request embedded
+	if err := ue.completeRRCSetup(nasPdu); err != nil {
+		return err
+	}
+
+	// Signal that RRC connection is ready (this unblocks <-ue.IsReadyConn in InitUE)
+	// ue.IsReadyConn <- true
+
+	ue.Info("==== RRC connection Initialized ====")
+
+	// Start goroutine to listen for subsequent RRC messages from DU (DL-DCCH messages)
+	go ue.listenForRrcMessages()
+
+	return nil
+}
+
+// completeRRCSetup sends RRCSetupComplete carrying the initial NAS message of the connection
+func (ue *UeContext) completeRRCSetup(nasPdu []byte) error {
 	rrcSetupComplete := rrcies.RRCSetupComplete{
 		Rrc_TransactionIdentifier: rrcies.RRC_TransactionIdentifier{Value: 0},
 		CriticalExtensions: rrcies.RRCSetupComplete_CriticalExtensions{
 			Choice: rrcies.RRCSetupComplete_CriticalExtensions_Choice_RrcSetupComplete,
 			RrcSetupComplete: &rrcies.RRCSetupComplete_IEs{
 				SelectedPLMN_Identity: 1,
-				Ng_5G_S_TMSI_Value: &rrcies.RRCSetupComplete_IEs_ng_5G_S_TMSI_Value{
-					Choice: rrcies.RRCSetupComplete_IEs_ng_5G_S_TMSI_Value_Choice_Ng_5G_S_TMSI_Part2,
-					Ng_5G_S_TMSI_Part2: aper.BitString{
-						Bytes:   []byte{0x00, 0x80},
-						NumBits: 9,
-					},
-				},
 				DedicatedNAS_Message: rrcies.DedicatedNAS_Message{
-					Value: ue.nasPdu, // NAS Registration Request is embedded here
+					Value: nasPdu, // initial NAS message is embedded here
 				},
 			},
 		},
 	}
+	if sTmsi, ok := ue.get5gSTmsi(); ok {
+		rrcSetupComplete.CriticalExtensions.RrcSetupComplete.Ng_5G_S_TMSI_Value = &rrcies.RRCSetupComplete_IEs_ng_5G_S_TMSI_Value{
+			Choice:             rrcies.RRCSetupComplete_IEs_ng_5G_S_TMSI_Value_Choice_Ng_5G_S_TMSI_Part2,
+			Ng_5G_S_TMSI_Part2: bitString(sTmsi>>39, 9),
+		}
+	}
 
 	// Encode RRCSetupComplete as UL-DCCH message
 	uldccchMessage := rrcies.UL_DCCH_Message{
@@ -199,16 +297,12 @@ func (ue *UeContext) handleRRCSetup(rrcSetupBytes []byte) error {
 		return err
 	}
 
-	ue.Info("Sending RRCSetupComplete to DU (with NAS Registration Request embedded)")
+	ue.Info("Sending RRCSetupComplete to DU (with initial NAS message embedded)")
+	ue.saveNasCounts(nasPdu)
 	ue.SendToDuChannel <- encoded
+	return nil
+}
 
-	// Signal that RRC connection is ready (this unblocks <-ue.IsReadyConn in InitUE)
-	// ue.IsReadyConn <- true
-
-	ue.Info("==== RRC connection Initialized ====")
-
-	// Start goroutine to listen for subsequent RRC messages from DU (DL-DCCH messages)
+func (ue *UeContext) StartRrcListenerForTest() {
 	go ue.listenForRrcMessages()
-
-	return nil
 }
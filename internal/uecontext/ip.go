@@ -0,0 +1,168 @@
+package uecontext
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// IPv4 packets built and parsed by the UE user plane
+const (
+	IP_PROTO_ICMP = 1
+	IP_PROTO_TCP  = 6
+	IP_PROTO_UDP  = 17
+
+	ipv4HeaderLen = 20
+	udpHeaderLen  = 8
+	tcpHeaderLen  = 20
+	icmpHeaderLen = 8
+	ipv4TTL       = 64
+)
+
+// TCP flags
+const (
+	tcpFlagFin = 0x01
+	tcpFlagSyn = 0x02
+	tcpFlagRst = 0x04
+	tcpFlagPsh = 0x08
+	tcpFlagAck = 0x10
+)
+
+// ipv4Packet is a parsed IPv4 packet
+type ipv4Packet struct {
+	src     net.IP
+	dst     net.IP
+	proto   uint8
+	payload []byte
+}
+
+// buildIPv4 wraps a transport payload in an IPv4 header
+func buildIPv4(src, dst net.IP, proto uint8, id uint16, payload []byte) []byte {
+	b := make([]byte, ipv4HeaderLen+len(payload))
+	b[0] = 0x45 // version 4, header of 5 words
+	binary.BigEndian.PutUint16(b[2:], uint16(len(b)))
+	binary.BigEndian.PutUint16(b[4:], id)
+	b[6] = 0x40 // don't fragment
+	b[8] = ipv4TTL
+	b[9] = proto
+	copy(b[12:16], src.To4())
+	copy(b[16:20], dst.To4())
+	binary.BigEndian.PutUint16(b[10:], inetChecksum(b[:ipv4HeaderLen], 0))
+	copy(b[ipv4HeaderLen:], payload)
+	return b
+}
+
+// parseIPv4 parses an IPv4 packet, false if it is not a valid unfragmented IPv4 packet
+func parseIPv4(b []byte) (*ipv4Packet, bool) {
+	if len(b) < ipv4HeaderLen || b[0]>>4 != 4 {
+		return nil, false
+	}
+	headerLen := int(b[0]&0x0f) * 4
+	total := int(binary.BigEndian.Uint16(b[2:]))
+	if headerLen < ipv4HeaderLen || total < headerLen || total > len(b) {
+		return nil, false
+	}
+	// more fragments or a fragment offset
+	if binary.BigEndian.Uint16(b[6:])&0x3fff != 0 {
+		return nil, false
+	}
+	return &ipv4Packet{
+		src:     net.IP(b[12:16]),
+		dst:     net.IP(b[16:20]),
+		proto:   b[9],
+		payload: b[headerLen:total],
+	}, true
+}
+
+// buildIcmpEcho builds an ICMP echo request
+func buildIcmpEcho(id, seq uint16, data []byte) []byte {
+	b := make([]byte, icmpHeaderLen+len(data))
+	b[0] = 8 // echo request
+	binary.BigEndian.PutUint16(b[4:], id)
+	binary.BigEndian.PutUint16(b[6:], seq)
+	copy(b[icmpHeaderLen:], data)
+	binary.BigEndian.PutUint16(b[2:], inetChecksum(b, 0))
+	return b
+}
+
+// buildUdp builds a UDP datagram with its checksum
+func buildUdp(src, dst net.IP, sport, dport uint16, data []byte) []byte {
+	b := make([]byte, udpHeaderLen+len(data))
+	binary.BigEndian.PutUint16(b[0:], sport)
+	binary.BigEndian.PutUint16(b[2:], dport)
+	binary.BigEndian.PutUint16(b[4:], uint16(len(b)))
+	copy(b[udpHeaderLen:], data)
+	sum := inetChecksum(b, pseudoHeaderSum(src, dst, IP_PROTO_UDP, len(b)))
+	if sum == 0 {
+		sum = 0xffff
+	}
+	binary.BigEndian.PutUint16(b[6:], sum)
+	return b
+}
+
+// tcpSegment is a TCP segment without options
+type tcpSegment struct {
+	sport, dport uint16
+	seq, ack     uint32
+	flags        uint8
+	window       uint16
+	data         []byte
+}
+
+// buildTcp builds a TCP segment with its checksum
+func buildTcp(src, dst net.IP, seg *tcpSegment) []byte {
+	b := make([]byte, tcpHeaderLen+len(seg.data))
+	binary.BigEndian.PutUint16(b[0:], seg.sport)
+	binary.BigEndian.PutUint16(b[2:], seg.dport)
+	binary.BigEndian.PutUint32(b[4:], seg.seq)
+	binary.BigEndian.PutUint32(b[8:], seg.ack)
+	b[12] = (tcpHeaderLen / 4) << 4
+	b[13] = seg.flags
+	binary.BigEndian.PutUint16(b[14:], seg.window)
+	copy(b[tcpHeaderLen:], seg.data)
+	binary.BigEndian.PutUint16(b[16:], inetChecksum(b, pseudoHeaderSum(src, dst, IP_PROTO_TCP, len(b))))
+	return b
+}
+
+// parseTcp parses a TCP segment, options are skipped
+func parseTcp(b []byte) (*tcpSegment, bool) {
+	if len(b) < tcpHeaderLen {
+		return nil, false
+	}
+	offset := int(b[12]>>4) * 4
+	if offset < tcpHeaderLen || offset > len(b) {
+		return nil, false
+	}
+	return &tcpSegment{
+		sport:  binary.BigEndian.Uint16(b[0:]),
+		dport:  binary.BigEndian.Uint16(b[2:]),
+		seq:    binary.BigEndian.Uint32(b[4:]),
+		ack:    binary.BigEndian.Uint32(b[8:]),
+		flags:  b[13],
+		window: binary.BigEndian.Uint16(b[14:]),
+		data:   b[offset:],
+	}, true
+}
+
+// pseudoHeaderSum returns the checksum contribution of the IPv4 pseudo header of UDP and TCP
+func pseudoHeaderSum(src, dst net.IP, proto uint8, length int) uint32 {
+	var sum uint32
+	for _, ip := range []net.IP{src.To4(), dst.To4()} {
+		sum += uint32(ip[0])<<8 | uint32(ip[1])
+		sum += uint32(ip[2])<<8 | uint32(ip[3])
+	}
+	return sum + uint32(proto) + uint32(length)
+}
+
+// inetChecksum computes the Internet checksum (RFC 1071) of b added to a partial sum
+func inetChecksum(b []byte, sum uint32) uint16 {
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
@@ -0,0 +1,162 @@
+package uecontext
+
+import (
+	"fmt"
+
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+	"github.com/reogac/nas"
+
+	"du_ue/pkg/config"
+)
+
+// servingCell is the cell the UE camps on or is connected to, with the TAC of its SIB1
+type servingCell struct {
+	pci uint16
+	tac uint32
+}
+
+// SetServingCell sets the cell of the DU the UE is attached to
+func (ue *UeContext) SetServingCell(cell config.CellConfig) {
+	tac, err := cell.TACValue()
+	if err != nil {
+		ue.Warn("Serving cell PCI %d: %v", cell.PCI, err)
+		return
+	}
+	ue.mutex.Lock()
+	ue.cell = servingCell{pci: cell.PCI, tac: tac}
+	ue.mutex.Unlock()
+	ue.Info("Serving cell PCI %d, TAC %06x", cell.PCI, tac)
+}
+
+// cellsByPci returns the TAC of the configured cells by PCI
+func cellsByPci(cells []config.CellConfig) map[uint16]uint32 {
+	tacs := make(map[uint16]uint32)
+	for _, cell := range cells {
+		if tac, err := cell.TACValue(); err == nil {
+			tacs[cell.PCI] = tac
+		}
+	}
+	return tacs
+}
+
+// setTaiList stores the registration area of a Registration Accept, the previous one is kept
+// when the message has no TAI list
+func (ue *UeContext) setTaiList(list *nas.TrackingAreaIdentityList) {
+	if list == nil {
+		return
+	}
+	ue.mutex.Lock()
+	ue.taiList = list
+	ue.mutex.Unlock()
+	ue.Info("Registration area: %d partial TAI lists", len(list.Lists))
+}
+
+// inRegistrationArea tells whether the tracking area of the serving cell is in the TAI list of the
+// last Registration Accept. Without a TAI list the UE has no area to leave.
+func (ue *UeContext) inRegistrationArea() bool {
+	ue.mutex.Lock()
+	list := ue.taiList
+	tac := ue.cell.tac
+	ue.mutex.Unlock()
+	if list == nil {
+		return true
+	}
+
+	var plmn nas.PlmnId
+	if err := plmn.Set(ue.mcc, ue.mnc); err != nil {
+		return true
+	}
+	for _, partial := range list.Lists {
+		switch l := partial.(type) {
+		case *nas.TaiListType0:
+			if l.PlmnId != plmn {
+				continue
+			}
+			for _, listed := range l.TacList {
+				if listed == tac {
+					return true
+				}
+			}
+		case *nas.TaiListType1:
+			if l.PlmnId == plmn && tac >= l.Start && tac < l.Start+uint32(l.Size) {
+				return true
+			}
+		case *nas.TaiListType2:
+			for _, tai := range l.List {
+				if tai.PlmnId == plmn && tai.Tac == tac {
+					return true
+				}
+			}
+		case *nas.TaiListType3:
+			if l.PlmnId == plmn {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// changeCell moves the UE to a cell after handover or cell reselection. Entering a tracking area
+// out of the registration area starts a mobility registration update (TS 24.501 5.5.1.3.2).
+func (ue *UeContext) changeCell(pci uint16) error {
+	ue.mutex.Lock()
+	tac, known := ue.cells[pci]
+	if known {
+		ue.cell = servingCell{pci: pci, tac: tac}
+	}
+	ue.mutex.Unlock()
+	if !known {
+		ue.Warn("TAC of cell PCI %d not configured, registration area not checked", pci)
+		return nil
+	}
+	ue.Info("Serving cell PCI %d, TAC %06x", pci, tac)
+
+	if ue.GetState() != UE_STATE_REGISTERED || ue.inRegistrationArea() {
+		return nil
+	}
+	ue.Info("TAC %06x not in the registration area, mobility registration update", tac)
+	return ue.triggerRegistrationUpdate(nas.RegistrationType5GSMobilityRegistrationUpdating, false)
+}
+
+// ReselectCell camps the UE in RRC idle on another configured cell
+func (ue *UeContext) ReselectCell(pci uint16) error {
+	if !ue.isRrcIdle() {
+		return fmt.Errorf("cell reselection needs RRC idle")
+	}
+	ue.Info("Cell reselection to PCI %d", pci)
+	return ue.changeCell(pci)
+}
+
+// reconfigurationWithSync returns the handover command of an RRCReconfiguration, found in the
+// master or secondary cell group, nil when the message is not a handover
+func reconfigurationWithSync(ies *rrcies.RRCReconfiguration_IEs) *rrcies.ReconfigurationWithSync {
+	var cellGroups []*[]byte
+	if ies.NonCriticalExtension != nil {
+		cellGroups = append(cellGroups, ies.NonCriticalExtension.MasterCellGroup)
+	}
+	cellGroups = append(cellGroups, ies.SecondaryCellGroup)
+
+	for _, encoded := range cellGroups {
+		if encoded == nil {
+			continue
+		}
+		var cellGroup rrcies.CellGroupConfig
+		if err := rrc.Decode(*encoded, &cellGroup); err != nil {
+			continue
+		}
+		if cellGroup.SpCellConfig != nil && cellGroup.SpCellConfig.ReconfigurationWithSync != nil {
+			return cellGroup.SpCellConfig.ReconfigurationWithSync
+		}
+	}
+	return nil
+}
+
+// handoverTargetPci returns the PCI of the target cell of a handover command, the neighbour cell
+// of the Measurement Report when the command does not carry it
+func handoverTargetPci(sync *rrcies.ReconfigurationWithSync) uint16 {
+	if sync.SpCellConfigCommon != nil && sync.SpCellConfigCommon.PhysCellId != nil {
+		return uint16(sync.SpCellConfigCommon.PhysCellId.Value)
+	}
+	return NEIGHBOUR_CELL_PCI
+}
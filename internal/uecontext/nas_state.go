@@ -0,0 +1,317 @@
+package uecontext
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext/sec"
+)
+
+// NAS_COUNT_SAVE_STEP is how far ahead of the UL NAS COUNT in use the state file is written, so the
+// file is rewritten once every NAS_COUNT_SAVE_STEP protected uplink NAS messages
+const NAS_COUNT_SAVE_STEP = 32
+
+// nasState is the NAS state the UE keeps across runs (TS 24.501 5.1.3): the 5G-GUTI with the native
+// security context, the SQN of the last authentication, the allowed and configured NSSAI and the
+// registration area
+type nasState struct {
+	Guti            string                `json:"guti,omitempty"`
+	Security        *securityState        `json:"security,omitempty"`
+	Sqn             string                `json:"sqn"`
+	AllowedNssai    []snssaiState         `json:"allowed_nssai,omitempty"`
+	ConfiguredNssai []snssaiState         `json:"configured_nssai,omitempty"`
+	TaiList         []partialTaiListState `json:"tai_list,omitempty"`
+}
+
+type securityState struct {
+	NgKsi   uint8  `json:"ngksi"`
+	Kamf    string `json:"kamf"`
+	EncAlg  uint8  `json:"enc_alg"`
+	IntAlg  uint8  `json:"int_alg"`
+	UlCount uint32 `json:"ul_count"`
+	DlCount uint32 `json:"dl_count"`
+}
+
+type snssaiState struct {
+	Sst uint8  `json:"sst"`
+	Sd  string `json:"sd,omitempty"`
+}
+
+// partialTaiListState is a partial TAI list of type 0 (TACs of a PLMN), 1 (size consecutive TACs
+// from start), 2 (TAIs) or 3 (all TACs of a PLMN)
+type partialTaiListState struct {
+	Type    uint8      `json:"type"`
+	Plmn    string     `json:"plmn,omitempty"`
+	Tacs    []uint32   `json:"tacs,omitempty"`
+	Start   uint32     `json:"start,omitempty"`
+	Size    uint8      `json:"size,omitempty"`
+	Tais    []taiState `json:"tais,omitempty"`
+	Allowed bool       `json:"allowed,omitempty"`
+}
+
+type taiState struct {
+	Plmn string `json:"plmn"`
+	Tac  uint32 `json:"tac"`
+}
+
+// stateFilePath returns the NAS state file of a UE, empty when the state is not kept
+func stateFilePath(dir, supi string) string {
+	if dir == "" {
+		return ""
+	}
+	return filepath.Join(dir, fmt.Sprintf("nas_%s.json", supi))
+}
+
+// SaveState writes the NAS state of the UE to its state file. During a registration the state is
+// in transition and the file keeps the previous one.
+func (ue *UeContext) SaveState() {
+	ue.saveState(0)
+}
+
+// saveState writes the NAS state with the UL NAS COUNT ahead of the one in use by ulReserve
+func (ue *UeContext) saveState(ulReserve uint32) {
+	if ue.stateFile == "" || ue.GetState() == UE_STATE_REGISTERING {
+		return
+	}
+
+	ue.mutex.Lock()
+	state := nasState{
+		Sqn:             hex.EncodeToString(ue.auth.sqn.Bytes()),
+		AllowedNssai:    nssaiToState(ue.allowedNssai),
+		ConfiguredNssai: nssaiToState(ue.configuredNssai),
+		TaiList:         taiListToState(ue.taiList),
+	}
+	if ue.guti != nil {
+		state.Guti = ue.guti.String()
+		if ue.secCtx != nil {
+			encAlg, intAlg := ue.secCtx.NasContext(true).SelectedAlgorithms()
+			ulCount, dlCount := ue.secCtx.NasCounts()
+			state.Security = &securityState{
+				NgKsi:   ue.auth.ngKsi.Id,
+				Kamf:    hex.EncodeToString(ue.secCtx.Kamf()),
+				EncAlg:  encAlg,
+				IntAlg:  intAlg,
+				UlCount: ulCount + ulReserve,
+				DlCount: dlCount,
+			}
+			ue.savedSecCtx, ue.savedUlCount = ue.secCtx, ulCount+ulReserve
+		}
+	}
+	ue.mutex.Unlock()
+
+	if err := writeNasState(ue.stateFile, &state); err != nil {
+		ue.Warn("Failed to save NAS state: %v", err)
+		return
+	}
+	ue.Debug("NAS state saved to %s", ue.stateFile)
+}
+
+// saveNasCounts keeps the NAS COUNTs in the state file before a protected uplink NAS message is
+// sent, a UE restarted without a clean stop then does not reuse a COUNT the AMF has seen. The file
+// is written NAS_COUNT_SAVE_STEP ahead of the UL NAS COUNT and only again once that is used up, a
+// restarted UE skips the COUNTs not used. During a registration only the counts of the stored
+// security context are updated, if it is still in use.
+func (ue *UeContext) saveNasCounts(nasPdu []byte) {
+	if ue.stateFile == "" || len(nasPdu) < 2 || nasPdu[1]&0x0f == nas.NasSecNone {
+		return
+	}
+	ue.mutex.Lock()
+	saved := ue.secCtx == nil || ue.secCtx == ue.savedSecCtx && ue.savedUlCount >= ulNasCount(ue.secCtx)
+	ue.mutex.Unlock()
+	if saved {
+		return
+	}
+	if ue.GetState() != UE_STATE_REGISTERING {
+		ue.saveState(NAS_COUNT_SAVE_STEP)
+		return
+	}
+
+	data, err := os.ReadFile(ue.stateFile)
+	if err != nil {
+		return
+	}
+	var state nasState
+	if err := json.Unmarshal(data, &state); err != nil || state.Security == nil {
+		return
+	}
+	ue.mutex.Lock()
+	if ue.secCtx == nil || state.Security.Kamf != hex.EncodeToString(ue.secCtx.Kamf()) {
+		ue.mutex.Unlock()
+		return
+	}
+	state.Security.UlCount, state.Security.DlCount = ue.secCtx.NasCounts()
+	state.Security.UlCount += NAS_COUNT_SAVE_STEP
+	ue.savedSecCtx, ue.savedUlCount = ue.secCtx, state.Security.UlCount
+	ue.mutex.Unlock()
+
+	if err := writeNasState(ue.stateFile, &state); err != nil {
+		ue.Warn("Failed to save NAS COUNT: %v", err)
+	}
+}
+
+func ulNasCount(secCtx *sec.SecurityContext) uint32 {
+	ul, _ := secCtx.NasCounts()
+	return ul
+}
+
+// writeNasState replaces the state file through a temporary file, a crash leaves the old state
+func writeNasState(path string, state *nasState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// loadState restores the NAS state of an earlier run, the UE then registers with its 5G-GUTI
+func (ue *UeContext) loadState() {
+	if ue.stateFile == "" {
+		return
+	}
+	data, err := os.ReadFile(ue.stateFile)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		ue.Warn("Failed to read NAS state: %v", err)
+		return
+	}
+	var state nasState
+	if err := json.Unmarshal(data, &state); err != nil {
+		ue.Warn("Invalid NAS state in %s: %v", ue.stateFile, err)
+		return
+	}
+
+	if sqn, err := hex.DecodeString(state.Sqn); err == nil && len(sqn) == 6 {
+		ue.auth.sqn.Set(sqn)
+	}
+	ue.allowedNssai = nssaiFromState(state.AllowedNssai)
+	ue.configuredNssai = nssaiFromState(state.ConfiguredNssai)
+	ue.taiList = taiListFromState(state.TaiList)
+	if state.Guti == "" {
+		ue.Info("NAS state restored without 5G-GUTI, SQN %s", ue.auth.sqn.String())
+		return
+	}
+
+	guti := new(nas.Guti)
+	if err := guti.Parse(state.Guti); err != nil {
+		ue.Warn("Invalid 5G-GUTI %s in NAS state: %v", state.Guti, err)
+		return
+	}
+	ue.guti = guti
+	if s := state.Security; s != nil {
+		kamf, err := hex.DecodeString(s.Kamf)
+		if err != nil || len(kamf) != 32 {
+			ue.Warn("Invalid KAMF in NAS state, security context not restored")
+		} else {
+			ngKsi := nas.KeySetIdentifier{Id: s.NgKsi}
+			if ue.secCtx, err = sec.RestoreSecurityContext(&ngKsi, kamf, s.EncAlg, s.IntAlg, s.UlCount, s.DlCount); err != nil {
+				ue.Warn("Failed to restore security context: %v", err)
+				ue.secCtx = nil
+			} else {
+				ue.auth.ngKsi = ngKsi
+			}
+		}
+	}
+	ue.Info("NAS state restored: 5G-GUTI %s, security context %v, SQN %s", guti.String(), ue.secCtx != nil, ue.auth.sqn.String())
+	if ue.secCtx != nil {
+		ue.Info("NAS COUNT UL %d, DL %d, ngKSI %d", state.Security.UlCount, state.Security.DlCount, state.Security.NgKsi)
+	}
+}
+
+func nssaiToState(nssai *nas.Nssai) []snssaiState {
+	if nssai == nil {
+		return nil
+	}
+	var list []snssaiState
+	for _, snssai := range nssai.List {
+		list = append(list, snssaiState{Sst: snssai.Sst, Sd: snssai.GetSd()})
+	}
+	return list
+}
+
+func nssaiFromState(list []snssaiState) *nas.Nssai {
+	if len(list) == 0 {
+		return nil
+	}
+	nssai := new(nas.Nssai)
+	for _, s := range list {
+		snssai := nas.SNssai{Sst: s.Sst}
+		// an S-NSSAI without SD has only the SST
+		if s.Sd != "" {
+			if err := snssai.Set(s.Sst, s.Sd); err != nil {
+				continue
+			}
+		}
+		nssai.Add(&snssai)
+	}
+	return nssai
+}
+
+func taiListToState(list *nas.TrackingAreaIdentityList) []partialTaiListState {
+	if list == nil {
+		return nil
+	}
+	var states []partialTaiListState
+	for _, partial := range list.Lists {
+		state := partialTaiListState{Type: partial.GetType()}
+		switch l := partial.(type) {
+		case *nas.TaiListType0:
+			state.Plmn, state.Tacs, state.Allowed = l.PlmnId.String(), l.TacList, l.Allowed
+		case *nas.TaiListType1:
+			state.Plmn, state.Start, state.Size, state.Allowed = l.PlmnId.String(), l.Start, l.Size, l.Allowed
+		case *nas.TaiListType2:
+			for _, tai := range l.List {
+				state.Tais = append(state.Tais, taiState{Plmn: tai.PlmnId.String(), Tac: tai.Tac})
+			}
+			state.Allowed = l.Allowed
+		case *nas.TaiListType3:
+			state.Plmn, state.Allowed = l.PlmnId.String(), l.Allowed
+		}
+		states = append(states, state)
+	}
+	return states
+}
+
+func taiListFromState(states []partialTaiListState) *nas.TrackingAreaIdentityList {
+	if len(states) == 0 {
+		return nil
+	}
+	list := new(nas.TrackingAreaIdentityList)
+	for _, state := range states {
+		var plmn nas.PlmnId
+		if state.Type != 2 && plmn.Parse(state.Plmn) != nil {
+			continue
+		}
+		switch state.Type {
+		case 0:
+			list.Lists = append(list.Lists, &nas.TaiListType0{PlmnId: plmn, TacList: state.Tacs, Allowed: state.Allowed})
+		case 1:
+			list.Lists = append(list.Lists, &nas.TaiListType1{PlmnId: plmn, Start: state.Start, Size: state.Size, Allowed: state.Allowed})
+		case 2:
+			l := &nas.TaiListType2{Allowed: state.Allowed}
+			for _, t := range state.Tais {
+				tai := nas.TrackingAreaIdentity{Tac: t.Tac}
+				if tai.PlmnId.Parse(t.Plmn) == nil {
+					l.List = append(l.List, tai)
+				}
+			}
+			list.Lists = append(list.Lists, l)
+		case 3:
+			list.Lists = append(list.Lists, &nas.TaiListType3{PlmnId: plmn, Allowed: state.Allowed})
+		}
+	}
+	return list
+}
@@ -0,0 +1,324 @@
+package uecontext
+
+import (
+	"time"
+
+	"github.com/reogac/nas"
+
+	"du_ue/pkg/config"
+)
+
+// default timer values when Registration Accept does not carry them (TS 24.501 10.2)
+const (
+	DEFAULT_T3512 = 54 * time.Minute
+	DEFAULT_T3502 = 12 * time.Minute
+)
+
+// standard values of the timers supervising UE-initiated 5GMM procedures (TS 24.501 10.2)
+const (
+	DEFAULT_T3510 = 15 * time.Second // registration
+	DEFAULT_T3511 = 10 * time.Second // registration retry after a failed attempt
+	DEFAULT_T3517 = 15 * time.Second // service request
+	DEFAULT_T3521 = 15 * time.Second // de-registration
+
+	MAX_REGISTRATION_ATTEMPTS   = 5 // registration attempt counter limit before T3502
+	MAX_DEREGISTRATION_ATTEMPTS = 5 // T3521 expiries before the de-registration is aborted
+)
+
+// 5GMM causes of TS 24.501 9.11.3.2 the nas package has no constant for
+const (
+	CAUSE_5GMM_REDIRECTION_TO_EPC_REQUIRED = 31
+	CAUSE_5GMM_NO_NETWORK_SLICES_AVAILABLE = 62
+)
+
+// procedureTimers holds the values of the timers supervising UE-initiated procedures
+type procedureTimers struct {
+	t3510 time.Duration
+	t3511 time.Duration
+	t3502 time.Duration // replaces the network value when set
+	t3517 time.Duration
+	t3521 time.Duration
+}
+
+func newProcedureTimers(conf config.NasTimersConfig) procedureTimers {
+	value := func(ms int, standard time.Duration) time.Duration {
+		if ms > 0 {
+			return time.Duration(ms) * time.Millisecond
+		}
+		return standard
+	}
+	return procedureTimers{
+		t3510: value(conf.T3510, DEFAULT_T3510),
+		t3511: value(conf.T3511, DEFAULT_T3511),
+		t3502: value(conf.T3502, 0),
+		t3517: value(conf.T3517, DEFAULT_T3517),
+		t3521: value(conf.T3521, DEFAULT_T3521),
+	}
+}
+
+// nasTimers holds the timer values of the last Registration Accept, 0 when deactivated
+type nasTimers struct {
+	t3512        time.Duration // periodic registration update
+	t3502        time.Duration // registration retry after failed attempts
+	t3324        time.Duration // active time
+	t3447        time.Duration // service gap
+	t3448        time.Duration // control plane data back-off
+	non3gppDereg time.Duration // implicit de-registration over non-3GPP access
+}
+
+// gprsTimer2 decodes a GPRS timer 2 value (TS 24.008 10.5.7.4), false when deactivated
+func gprsTimer2(t *nas.GprsTimer2) (time.Duration, bool) {
+	value := time.Duration(t.Value & 0x1f)
+	switch t.Value >> 5 {
+	case 0:
+		return value * 2 * time.Second, true
+	case 1:
+		return value * time.Minute, true
+	case 2:
+		return value * 6 * time.Minute, true
+	}
+	return 0, false
+}
+
+// gprsTimer3 decodes a GPRS timer 3 value (TS 24.008 10.5.7.4a), false when deactivated
+func gprsTimer3(t *nas.GprsTimer3) (time.Duration, bool) {
+	value := time.Duration(t.Value & 0x1f)
+	switch t.Value >> 5 {
+	case 0:
+		return value * 10 * time.Minute, true
+	case 1:
+		return value * time.Hour, true
+	case 2:
+		return value * 10 * time.Hour, true
+	case 3:
+		return value * 2 * time.Second, true
+	case 4:
+		return value * 30 * time.Second, true
+	case 5:
+		return value * time.Minute, true
+	case 6:
+		return value * 320 * time.Hour, true
+	}
+	return 0, false
+}
+
+// applyRegistrationTimers stores the timers of a Registration Accept and restarts T3512
+func (ue *UeContext) applyRegistrationTimers(message *nas.RegistrationAccept) {
+	timers := nasTimers{t3512: DEFAULT_T3512, t3502: DEFAULT_T3502}
+	if message.T3512Value != nil {
+		timers.t3512, _ = gprsTimer3(message.T3512Value)
+	}
+	if message.T3502Value != nil {
+		timers.t3502, _ = gprsTimer2(message.T3502Value)
+	}
+	if message.T3324Value != nil {
+		timers.t3324, _ = gprsTimer3(message.T3324Value)
+	}
+	if message.T3447Value != nil {
+		timers.t3447, _ = gprsTimer3(message.T3447Value)
+	}
+	if message.T3448Value != nil {
+		timers.t3448, _ = gprsTimer2(message.T3448Value)
+	}
+	if message.Non3gppDeRegistrationTimerValue != nil {
+		timers.non3gppDereg, _ = gprsTimer2(message.Non3gppDeRegistrationTimerValue)
+	}
+
+	ue.mutex.Lock()
+	ue.timers = timers
+	ue.mutex.Unlock()
+	ue.Info("NAS timers: T3512 %v, T3502 %v, T3324 %v, T3447 %v, T3448 %v (0 is deactivated)",
+		timers.t3512, timers.t3502, timers.t3324, timers.t3447, timers.t3448)
+	ue.startT3512()
+}
+
+// startT3512 (re)starts the periodic registration timer, unless deactivated by the network
+func (ue *UeContext) startT3512() {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	if ue.t3512 != nil {
+		ue.t3512.Stop()
+		ue.t3512 = nil
+	}
+	if ue.timers.t3512 > 0 {
+		ue.t3512 = time.AfterFunc(ue.timers.t3512, ue.onT3512Expiry)
+	}
+}
+
+func (ue *UeContext) stopT3512() {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	if ue.t3512 != nil {
+		ue.t3512.Stop()
+		ue.t3512 = nil
+	}
+}
+
+// onT3512Expiry runs a periodic registration update, from RRC idle on a new RRC connection. Out of
+// the registration area a mobility registration update is run instead.
+func (ue *UeContext) onT3512Expiry() {
+	if ue.ctx.Err() != nil || ue.GetState() != UE_STATE_REGISTERED {
+		return
+	}
+	registrationType := nas.RegistrationType5GSPeriodicRegistrationUpdating
+	if !ue.inRegistrationArea() {
+		registrationType = nas.RegistrationType5GSMobilityRegistrationUpdating
+	}
+	ue.Info("T3512 expired, registration update type %d", registrationType)
+	if err := ue.triggerRegistrationUpdate(registrationType, false); err != nil {
+		ue.Error("Failed to start periodic registration update: %v", err)
+	}
+}
+
+// startTimer (re)starts one of the procedure timers of the UE. A timer stopped or restarted while
+// its expiry is pending does not run the expiry.
+func (ue *UeContext) startTimer(timer **time.Timer, d time.Duration, expiry func()) {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	if *timer != nil {
+		(*timer).Stop()
+	}
+	var t *time.Timer
+	t = time.AfterFunc(d, func() {
+		ue.mutex.Lock()
+		current := *timer == t
+		if current {
+			*timer = nil
+		}
+		ue.mutex.Unlock()
+		if current && ue.ctx.Err() == nil {
+			expiry()
+		}
+	})
+	*timer = t
+}
+
+// stopTimer stops one of the procedure timers of the UE, false when it was not running
+func (ue *UeContext) stopTimer(timer **time.Timer) bool {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	if *timer == nil {
+		return false
+	}
+	(*timer).Stop()
+	*timer = nil
+	return true
+}
+
+// startRegistrationTimer starts T3510 for a Registration Request, a pending retry is replaced by it
+func (ue *UeContext) startRegistrationTimer() {
+	ue.stopTimer(&ue.t3511)
+	ue.stopTimer(&ue.t3502)
+	ue.stopTimer(&ue.t3346)
+	ue.startTimer(&ue.t3510, ue.procTimers.t3510, ue.onT3510Expiry)
+}
+
+// registrationCompleted stops the registration timers and resets the registration attempt counter
+func (ue *UeContext) registrationCompleted() {
+	ue.stopTimer(&ue.t3510)
+	ue.stopTimer(&ue.t3511)
+	ue.stopTimer(&ue.t3502)
+	ue.stopTimer(&ue.t3346)
+	ue.resetRegistrationAttempts()
+}
+
+// resetRegistrationAttempts resets the registration attempt counter
+func (ue *UeContext) resetRegistrationAttempts() {
+	ue.mutex.Lock()
+	ue.registrationAttempts = 0
+	ue.mutex.Unlock()
+}
+
+// onT3510Expiry aborts a registration the network did not answer (TS 24.501 5.5.1.2.7 c)
+func (ue *UeContext) onT3510Expiry() {
+	ue.registrationFailed("T3510 expired")
+}
+
+// registrationFailed handles an abnormal end of a registration procedure (TS 24.501 5.5.1.2.7,
+// 5.5.1.3.7): the registration attempt counter is incremented and the registration is retried
+// after T3511, or after T3502 once the counter reaches 5. An initial registration failing 5 times
+// also deletes the 5G-GUTI, TAI list and ngKSI.
+func (ue *UeContext) registrationFailed(reason string) {
+	ue.stopTimer(&ue.t3510)
+	ue.mutex.Lock()
+	ue.registrationAttempts++
+	attempts := ue.registrationAttempts
+	registrationType := ue.registrationType
+	ue.mutex.Unlock()
+	initial := registrationType == nas.RegistrationType5GSInitialRegistration
+	if initial {
+		ue.SetState(UE_STATE_DEREGISTERED)
+	}
+
+	if attempts < MAX_REGISTRATION_ATTEMPTS {
+		ue.Warn("%s, registration attempt %d failed, retry in %v (T3511)", reason, attempts, ue.procTimers.t3511)
+		ue.startTimer(&ue.t3511, ue.procTimers.t3511, ue.retryRegistration)
+		return
+	}
+
+	if initial {
+		ue.deleteIdentity()
+	}
+	t3502 := ue.t3502Value()
+	if t3502 == 0 {
+		ue.Warn("%s, registration attempt %d failed, T3502 deactivated", reason, attempts)
+		return
+	}
+	ue.Warn("%s, registration attempt %d failed, retry in %v (T3502)", reason, attempts, t3502)
+	ue.startTimer(&ue.t3502, t3502, ue.retryRegistration)
+}
+
+// retryRegistration runs the failed registration again on T3511 or T3502 expiry
+func (ue *UeContext) retryRegistration() {
+	ue.mutex.Lock()
+	registrationType := ue.registrationType
+	ue.mutex.Unlock()
+	ue.Info("Retrying registration, type %d", registrationType)
+	ue.runPendingRegistration(registrationType)
+}
+
+// t3502Value returns the configured T3502, else the value of the network
+func (ue *UeContext) t3502Value() time.Duration {
+	if ue.procTimers.t3502 > 0 {
+		return ue.procTimers.t3502
+	}
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	return ue.timers.t3502
+}
+
+// onT3517Expiry aborts a service request the network did not answer (TS 24.501 5.6.1.7 c). The next
+// UL data in RRC idle starts a new one.
+func (ue *UeContext) onT3517Expiry() {
+	ue.mutex.Lock()
+	ue.serviceRequest = false
+	ue.mutex.Unlock()
+	ue.Warn("T3517 expired, service request aborted")
+}
+
+// onT3521Expiry retransmits the Deregistration Request, the fifth expiry aborts the de-registration
+// and the UE de-registers locally (TS 24.501 5.5.2.2.6 c)
+func (ue *UeContext) onT3521Expiry() {
+	ue.mutex.Lock()
+	ue.deregistrationAttempts++
+	attempts := ue.deregistrationAttempts
+	ue.mutex.Unlock()
+	if attempts >= MAX_DEREGISTRATION_ATTEMPTS {
+		ue.Warn("T3521 expired %d times, de-registration aborted", attempts)
+		ue.deregisterLocally()
+		return
+	}
+	ue.Warn("T3521 expired, retransmitting Deregistration Request (%d)", attempts)
+	if err := ue.sendDeregistrationRequest(false); err != nil {
+		ue.Error("Failed to retransmit Deregistration Request: %v", err)
+	}
+	ue.startTimer(&ue.t3521, ue.procTimers.t3521, ue.onT3521Expiry)
+}
+
+func GprsTimer2ForTest(t *nas.GprsTimer2) (time.Duration, bool) {
+	return gprsTimer2(t)
+}
+
+func GprsTimer3ForTest(t *nas.GprsTimer3) (time.Duration, bool) {
+	return gprsTimer3(t)
+}
@@ -31,6 +31,16 @@ type PduSession struct {
 	// Transaction
 	pti uint8 // Procedure Transaction Identity
 
+	// QoS
+	qosRules []QosRule                   // by precedence
+	qosFlows map[uint8]QosFlowDescription // by QFI
+
+	// User plane counters
+	dlPackets uint64
+	dlBytes   uint64
+	flow      *trafficFlow // running traffic, nil if none
+	tun       *sessionTun  // TUN device of local applications, nil if none
+
 	// Parent UE
 	ue *UeContext
 
@@ -123,4 +133,31 @@ func (ps *PduSession) GetIP() string {
 // IsActive checks if session is active
 func (ps *PduSession) IsActive() bool {
 	return ps.GetState() == PDUSessionActive
-}
\ No newline at end of file
+}
+
+// receive handles a DL user plane packet of the session
+func (ps *PduSession) receive(data []byte) {
+	ps.mutex.Lock()
+	ps.dlPackets++
+	ps.dlBytes += uint64(len(data))
+	flow := ps.flow
+	tun := ps.tun
+	ps.mutex.Unlock()
+	ps.Debug("Received DL packet, length: %d", len(data))
+
+	if tun != nil {
+		ps.writeTun(tun, data)
+	}
+	if flow == nil {
+		return
+	}
+	pkt, ok := parseIPv4(data)
+	if !ok {
+		return
+	}
+	select {
+	case flow.in <- pkt:
+	default:
+		ps.Warn("Traffic flow queue full, DL packet dropped")
+	}
+}
@@ -0,0 +1,439 @@
+package uecontext
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+)
+
+// QoS rule operation codes (TS 24.501 9.11.4.13)
+const (
+	QOS_RULE_OP_CREATE                 = 1
+	QOS_RULE_OP_DELETE                 = 2
+	QOS_RULE_OP_MODIFY_ADD_FILTERS     = 3
+	QOS_RULE_OP_MODIFY_REPLACE_FILTERS = 4
+	QOS_RULE_OP_MODIFY_DELETE_FILTERS  = 5
+	QOS_RULE_OP_MODIFY_NO_FILTERS      = 6
+)
+
+// Packet filter directions
+const (
+	PACKET_FILTER_DL            = 1
+	PACKET_FILTER_UL            = 2
+	PACKET_FILTER_BIDIRECTIONAL = 3
+)
+
+// Packet filter component types
+const (
+	PF_MATCH_ALL           = 0x01
+	PF_IPV4_REMOTE_ADDRESS = 0x10
+	PF_IPV4_LOCAL_ADDRESS  = 0x11
+	PF_IPV6_REMOTE_ADDRESS = 0x21
+	PF_IPV6_LOCAL_ADDRESS  = 0x23
+	PF_PROTOCOL_ID         = 0x30
+	PF_SINGLE_LOCAL_PORT   = 0x40
+	PF_LOCAL_PORT_RANGE    = 0x41
+	PF_SINGLE_REMOTE_PORT  = 0x50
+	PF_REMOTE_PORT_RANGE   = 0x51
+	PF_SECURITY_PARAM_IDX  = 0x60
+	PF_TYPE_OF_SERVICE     = 0x70
+	PF_FLOW_LABEL          = 0x80
+	PF_DST_MAC_ADDRESS     = 0x81
+	PF_SRC_MAC_ADDRESS     = 0x82
+	PF_CTAG_VID            = 0x83
+	PF_STAG_VID            = 0x84
+	PF_CTAG_PCP_DEI        = 0x85
+	PF_STAG_PCP_DEI        = 0x86
+	PF_ETHERTYPE           = 0x87
+)
+
+// QoS flow description operation codes and parameters (TS 24.501 9.11.4.12)
+const (
+	QOS_FLOW_OP_CREATE = 1
+	QOS_FLOW_OP_DELETE = 2
+	QOS_FLOW_OP_MODIFY = 3
+
+	qosFlowParam5qi     = 0x01
+	qosFlowParamGfbrUl  = 0x02
+	qosFlowParamGfbrDl  = 0x03
+	qosFlowParamMfbrUl  = 0x04
+	qosFlowParamMfbrDl  = 0x05
+	qosFlowParamAvgWin  = 0x06
+	qosFlowParamEpsBrId = 0x07
+)
+
+// pfComponentLen is the value length of each packet filter component type
+var pfComponentLen = map[uint8]int{
+	PF_MATCH_ALL:           0,
+	PF_IPV4_REMOTE_ADDRESS: 8,
+	PF_IPV4_LOCAL_ADDRESS:  8,
+	PF_IPV6_REMOTE_ADDRESS: 17,
+	PF_IPV6_LOCAL_ADDRESS:  17,
+	PF_PROTOCOL_ID:         1,
+	PF_SINGLE_LOCAL_PORT:   2,
+	PF_LOCAL_PORT_RANGE:    4,
+	PF_SINGLE_REMOTE_PORT:  2,
+	PF_REMOTE_PORT_RANGE:   4,
+	PF_SECURITY_PARAM_IDX:  4,
+	PF_TYPE_OF_SERVICE:     2,
+	PF_FLOW_LABEL:          3,
+	PF_DST_MAC_ADDRESS:     6,
+	PF_SRC_MAC_ADDRESS:     6,
+	PF_CTAG_VID:            2,
+	PF_STAG_VID:            2,
+	PF_CTAG_PCP_DEI:        1,
+	PF_STAG_PCP_DEI:        1,
+	PF_ETHERTYPE:           2,
+}
+
+// PacketFilterComponent is one condition of a packet filter
+type PacketFilterComponent struct {
+	Type  uint8
+	Value []byte
+}
+
+// PacketFilter matches packets when all its components match
+type PacketFilter struct {
+	Id         uint8
+	Direction  uint8
+	Components []PacketFilterComponent
+}
+
+// QosRule maps the packets matching its filters to a QoS flow
+type QosRule struct {
+	Id          uint8
+	OpCode      uint8
+	Default     bool
+	Precedence  uint8 // lower values are evaluated first
+	Segregation bool
+	Qfi         uint8
+	Filters     []PacketFilter
+	FilterIds   []uint8 // filters to delete with QOS_RULE_OP_MODIFY_DELETE_FILTERS
+}
+
+// QosFlowDescription holds the QoS parameters of a QoS flow, bit rates in kbit/s
+type QosFlowDescription struct {
+	Qfi         uint8
+	OpCode      uint8
+	FiveQi      uint8
+	GfbrUl      uint64
+	GfbrDl      uint64
+	MfbrUl      uint64
+	MfbrDl      uint64
+	EpsBearerId uint8
+}
+
+// decodeQosRules decodes the QoS rules IE content
+func decodeQosRules(b []byte) ([]QosRule, error) {
+	var rules []QosRule
+	for len(b) > 0 {
+		if len(b) < 3 {
+			return nil, fmt.Errorf("QoS rule truncated")
+		}
+		ruleLen := int(binary.BigEndian.Uint16(b[1:]))
+		if len(b) < 3+ruleLen || ruleLen < 1 {
+			return nil, fmt.Errorf("QoS rule %d length %d invalid", b[0], ruleLen)
+		}
+		rule, err := decodeQosRule(b[0], b[3:3+ruleLen])
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+		b = b[3+ruleLen:]
+	}
+	return rules, nil
+}
+
+func decodeQosRule(id uint8, b []byte) (*QosRule, error) {
+	rule := &QosRule{
+		Id:      id,
+		OpCode:  b[0] >> 5,
+		Default: b[0]&0x10 != 0,
+	}
+	numFilters := int(b[0] & 0x0f)
+	b = b[1:]
+
+	switch rule.OpCode {
+	case QOS_RULE_OP_DELETE, QOS_RULE_OP_MODIFY_NO_FILTERS:
+	case QOS_RULE_OP_MODIFY_DELETE_FILTERS:
+		if len(b) < numFilters {
+			return nil, fmt.Errorf("QoS rule %d packet filter identifiers truncated", id)
+		}
+		for _, f := range b[:numFilters] {
+			rule.FilterIds = append(rule.FilterIds, f&0x0f)
+		}
+		b = b[numFilters:]
+	case QOS_RULE_OP_CREATE, QOS_RULE_OP_MODIFY_ADD_FILTERS, QOS_RULE_OP_MODIFY_REPLACE_FILTERS:
+		for i := 0; i < numFilters; i++ {
+			if len(b) < 2 || len(b) < 2+int(b[1]) {
+				return nil, fmt.Errorf("QoS rule %d packet filter truncated", id)
+			}
+			filter, err := decodePacketFilter(b[0], b[2:2+int(b[1])])
+			if err != nil {
+				return nil, fmt.Errorf("QoS rule %d: %w", id, err)
+			}
+			rule.Filters = append(rule.Filters, *filter)
+			b = b[2+int(b[1]):]
+		}
+	default:
+		return nil, fmt.Errorf("QoS rule %d has unknown operation code %d", id, rule.OpCode)
+	}
+
+	// precedence and QFI are absent from a rule deletion
+	if len(b) >= 2 {
+		rule.Precedence = b[0]
+		rule.Segregation = b[1]&0x40 != 0
+		rule.Qfi = b[1] & 0x3f
+	} else if rule.OpCode != QOS_RULE_OP_DELETE {
+		return nil, fmt.Errorf("QoS rule %d misses precedence and QFI", id)
+	}
+	return rule, nil
+}
+
+func decodePacketFilter(header uint8, b []byte) (*PacketFilter, error) {
+	filter := &PacketFilter{
+		Id:        header & 0x0f,
+		Direction: (header >> 4) & 0x03,
+	}
+	for len(b) > 0 {
+		n, ok := pfComponentLen[b[0]]
+		if !ok {
+			return nil, fmt.Errorf("packet filter %d has unknown component type 0x%x", filter.Id, b[0])
+		}
+		if len(b) < 1+n {
+			return nil, fmt.Errorf("packet filter %d component 0x%x truncated", filter.Id, b[0])
+		}
+		filter.Components = append(filter.Components, PacketFilterComponent{Type: b[0], Value: b[1 : 1+n]})
+		b = b[1+n:]
+	}
+	return filter, nil
+}
+
+// decodeQosFlowDescriptions decodes the QoS flow descriptions IE content
+func decodeQosFlowDescriptions(b []byte) ([]QosFlowDescription, error) {
+	var flows []QosFlowDescription
+	for len(b) > 0 {
+		if len(b) < 3 {
+			return nil, fmt.Errorf("QoS flow description truncated")
+		}
+		flow := QosFlowDescription{
+			Qfi:    b[0] & 0x3f,
+			OpCode: b[1] >> 5,
+		}
+		numParams := int(b[2] & 0x3f)
+		b = b[3:]
+		for i := 0; i < numParams; i++ {
+			if len(b) < 2 || len(b) < 2+int(b[1]) {
+				return nil, fmt.Errorf("QoS flow %d parameter truncated", flow.Qfi)
+			}
+			id, value := b[0], b[2:2+int(b[1])]
+			b = b[2+int(b[1]):]
+			switch id {
+			case qosFlowParam5qi:
+				if len(value) > 0 {
+					flow.FiveQi = value[0]
+				}
+			case qosFlowParamGfbrUl:
+				flow.GfbrUl = decodeBitRate(value)
+			case qosFlowParamGfbrDl:
+				flow.GfbrDl = decodeBitRate(value)
+			case qosFlowParamMfbrUl:
+				flow.MfbrUl = decodeBitRate(value)
+			case qosFlowParamMfbrDl:
+				flow.MfbrDl = decodeBitRate(value)
+			case qosFlowParamEpsBrId:
+				if len(value) > 0 {
+					flow.EpsBearerId = value[0] >> 4
+				}
+			}
+		}
+		flows = append(flows, flow)
+	}
+	return flows, nil
+}
+
+// decodeBitRate decodes a unit and 16-bit value bit rate into kbit/s, each unit step being x4
+func decodeBitRate(b []byte) uint64 {
+	if len(b) < 3 || b[0] == 0 {
+		return 0
+	}
+	rate := uint64(binary.BigEndian.Uint16(b[1:]))
+	for unit := b[0]; unit > 1; unit-- {
+		rate *= 4
+	}
+	return rate
+}
+
+// applyQosRules updates the QoS rules of the session with the operations of a rule list
+func (ps *PduSession) applyQosRules(rules []QosRule) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	for _, r := range rules {
+		idx := -1
+		for i := range ps.qosRules {
+			if ps.qosRules[i].Id == r.Id {
+				idx = i
+			}
+		}
+		switch r.OpCode {
+		case QOS_RULE_OP_CREATE:
+			if idx >= 0 {
+				ps.qosRules[idx] = r
+			} else {
+				ps.qosRules = append(ps.qosRules, r)
+			}
+			continue
+		case QOS_RULE_OP_DELETE:
+			if idx >= 0 {
+				ps.qosRules = append(ps.qosRules[:idx], ps.qosRules[idx+1:]...)
+			}
+			continue
+		}
+		if idx < 0 {
+			ps.Warn("Modification of unknown QoS rule %d ignored", r.Id)
+			continue
+		}
+
+		rule := &ps.qosRules[idx]
+		switch r.OpCode {
+		case QOS_RULE_OP_MODIFY_ADD_FILTERS:
+			for _, f := range r.Filters {
+				rule.Filters = replaceFilter(rule.Filters, f)
+			}
+		case QOS_RULE_OP_MODIFY_REPLACE_FILTERS:
+			rule.Filters = r.Filters
+		case QOS_RULE_OP_MODIFY_DELETE_FILTERS:
+			for _, id := range r.FilterIds {
+				rule.Filters = deleteFilter(rule.Filters, id)
+			}
+		}
+		rule.Precedence, rule.Segregation, rule.Qfi = r.Precedence, r.Segregation, r.Qfi
+	}
+
+	sort.SliceStable(ps.qosRules, func(i, j int) bool {
+		return ps.qosRules[i].Precedence < ps.qosRules[j].Precedence
+	})
+}
+
+func replaceFilter(filters []PacketFilter, f PacketFilter) []PacketFilter {
+	return append(deleteFilter(filters, f.Id), f)
+}
+
+func deleteFilter(filters []PacketFilter, id uint8) []PacketFilter {
+	var kept []PacketFilter
+	for _, f := range filters {
+		if f.Id != id {
+			kept = append(kept, f)
+		}
+	}
+	return kept
+}
+
+// applyQosFlowDescriptions updates the QoS flows of the session
+func (ps *PduSession) applyQosFlowDescriptions(flows []QosFlowDescription) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if ps.qosFlows == nil {
+		ps.qosFlows = make(map[uint8]QosFlowDescription)
+	}
+	for _, f := range flows {
+		if f.OpCode == QOS_FLOW_OP_DELETE {
+			delete(ps.qosFlows, f.Qfi)
+		} else {
+			ps.qosFlows[f.Qfi] = f
+		}
+	}
+}
+
+// classifyUl returns the QFI of a UL packet from the QoS rules of the session, false when no rule
+// matches and the packet must be discarded (TS 24.501 6.2.5.1.1). Without rules the QFI is 0.
+func (ps *PduSession) classifyUl(data []byte) (uint8, bool) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if len(ps.qosRules) == 0 {
+		return 0, true
+	}
+	pkt, ok := parseIPv4(data)
+	if !ok {
+		return 0, false
+	}
+	for _, rule := range ps.qosRules {
+		for _, f := range rule.Filters {
+			if f.matchesUl(data[1], pkt) {
+				return rule.Qfi, true
+			}
+		}
+	}
+	return 0, false
+}
+
+// matchesUl checks a UL IPv4 packet against the filter, local is the UE side
+func (f *PacketFilter) matchesUl(tos uint8, pkt *ipv4Packet) bool {
+	if f.Direction != PACKET_FILTER_UL && f.Direction != PACKET_FILTER_BIDIRECTIONAL {
+		return false
+	}
+	var sport, dport uint16
+	hasPorts := (pkt.proto == IP_PROTO_UDP || pkt.proto == IP_PROTO_TCP) && len(pkt.payload) >= 4
+	if hasPorts {
+		sport = binary.BigEndian.Uint16(pkt.payload)
+		dport = binary.BigEndian.Uint16(pkt.payload[2:])
+	}
+
+	for _, c := range f.Components {
+		v := c.Value
+		var match bool
+		switch c.Type {
+		case PF_MATCH_ALL:
+			match = true
+		case PF_IPV4_REMOTE_ADDRESS:
+			match = maskedEqual(pkt.dst, v[:4], v[4:])
+		case PF_IPV4_LOCAL_ADDRESS:
+			match = maskedEqual(pkt.src, v[:4], v[4:])
+		case PF_PROTOCOL_ID:
+			match = pkt.proto == v[0]
+		case PF_SINGLE_LOCAL_PORT:
+			match = hasPorts && sport == binary.BigEndian.Uint16(v)
+		case PF_LOCAL_PORT_RANGE:
+			match = hasPorts && sport >= binary.BigEndian.Uint16(v) && sport <= binary.BigEndian.Uint16(v[2:])
+		case PF_SINGLE_REMOTE_PORT:
+			match = hasPorts && dport == binary.BigEndian.Uint16(v)
+		case PF_REMOTE_PORT_RANGE:
+			match = hasPorts && dport >= binary.BigEndian.Uint16(v) && dport <= binary.BigEndian.Uint16(v[2:])
+		case PF_TYPE_OF_SERVICE:
+			match = tos&v[1] == v[0]&v[1]
+		}
+		// IPv6, IPsec and Ethernet components never match the IPv4 packets of the UE
+		if !match {
+			return false
+		}
+	}
+	return len(f.Components) > 0
+}
+
+func maskedEqual(ip, addr, mask []byte) bool {
+	for i := 0; i < 4; i++ {
+		if ip[i]&mask[i] != addr[i]&mask[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *QosRule) String() string {
+	return fmt.Sprintf("rule %d: QFI %d, precedence %d, default %v, %d packet filters", r.Id, r.Qfi, r.Precedence, r.Default, len(r.Filters))
+}
+
+func DecodeQosRulesForTest(b []byte) ([]QosRule, error) {
+	return decodeQosRules(b)
+}
+
+func (ps *PduSession) ApplyQosRulesForTest(rules []QosRule) {
+	ps.applyQosRules(rules)
+}
+
+func (ps *PduSession) ClassifyUlForTest(data []byte) (uint8, bool) {
+	return ps.classifyUl(data)
+}
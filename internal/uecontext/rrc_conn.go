@@ -0,0 +1,101 @@
+package uecontext
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lvdund/asn1go/aper"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+const (
+	RRC_SETUP_TIMEOUT      = 2 * time.Second // T300
+	RRC_SETUP_MAX_ATTEMPTS = 5
+)
+
+// isRrcIdle tells whether the RRC connection of the UE was released
+func (ue *UeContext) isRrcIdle() bool {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	return ue.rrcIdle
+}
+
+// handleRRCRelease moves the UE to RRC idle: the DRBs are gone and a new RRC connection is needed
+// to send NAS messages. A registration waiting for the release is started on a new connection.
+func (ue *UeContext) handleRRCRelease(msg *rrcies.RRCRelease) error {
+	ue.Info("Received RRCRelease, RRC connection released")
+
+	ue.mutex.Lock()
+	ue.rrcIdle = true
+	ue.drbs = nil
+	reregister := ue.reregister
+	ue.reregister = 0
+	ue.mutex.Unlock()
+
+	// T3512 restarts when the UE enters idle mode
+	if ue.GetState() == UE_STATE_REGISTERED {
+		ue.startT3512()
+	}
+	ue.SaveState()
+
+	if reregister != 0 {
+		go ue.runPendingRegistration(reregister)
+	}
+	return nil
+}
+
+// establishRrcConnection sets up a new RRC connection from RRC idle and sends the initial NAS
+// message in RRCSetupComplete. RRCReject delays the next attempt by its wait time.
+func (ue *UeContext) establishRrcConnection(nasPdu []byte, cause aper.Enumerated) error {
+	// drop DL-CCCH messages left from an earlier attempt
+	for len(ue.ccch) > 0 {
+		<-ue.ccch
+	}
+
+	for attempt := 1; attempt <= RRC_SETUP_MAX_ATTEMPTS; attempt++ {
+		if err := ue.initRRCConn(cause); err != nil {
+			return err
+		}
+
+		var rrcBytes []byte
+		select {
+		case rrcBytes = <-ue.ccch:
+		case <-time.After(RRC_SETUP_TIMEOUT):
+			ue.Warn("No RRCSetup within %v (attempt %d)", RRC_SETUP_TIMEOUT, attempt)
+			continue
+		case <-ue.ctx.Done():
+			return ue.ctx.Err()
+		}
+
+		if waitTime, rejected := rrcRejectWaitTime(rrcBytes); rejected {
+			ue.Warn("Received RRCReject, retrying RRC connection in %v", waitTime)
+			select {
+			case <-time.After(waitTime):
+			case <-ue.ctx.Done():
+				return ue.ctx.Err()
+			}
+			continue
+		}
+		if !isRrcSetup(rrcBytes) {
+			ue.Warn("Expected RRCSetup, DL-CCCH message dropped")
+			continue
+		}
+
+		ue.Info("Received RRCSetup from DU")
+		ue.mutex.Lock()
+		ue.rrcIdle = false
+		ue.mutex.Unlock()
+		return ue.completeRRCSetup(nasPdu)
+	}
+	return fmt.Errorf("RRC connection not established after %d attempts", RRC_SETUP_MAX_ATTEMPTS)
+}
+
+// isRrcSetup tells whether a DL-CCCH message is an RRCSetup
+func isRrcSetup(rrcBytes []byte) bool {
+	var msg rrcies.DL_CCCH_Message
+	if err := rrc.Decode(rrcBytes, &msg); err != nil || msg.Message.C1 == nil {
+		return false
+	}
+	return msg.Message.C1.Choice == rrcies.DL_CCCH_MessageType_C1_Choice_RrcSetup && msg.Message.C1.RrcSetup != nil
+}
@@ -0,0 +1,33 @@
+package sec
+
+import (
+	"github.com/reogac/nas"
+)
+
+// RestoreSecurityContext rebuilds a stored native security context: the NAS keys are derived again
+// from KAMF and the NAS COUNTs are set to their stored values
+func RestoreSecurityContext(ksi *nas.KeySetIdentifier, kamf []byte, encAlg, intAlg uint8, ulCount, dlCount uint32) (*SecurityContext, error) {
+	ctx := NewSecurityContext(ksi, kamf, false)
+	if err := ctx.gppNas.DeriveKeys(encAlg, intAlg, ctx.kamf); err != nil {
+		return nil, err
+	}
+	ctx.setNasCounts(ulCount, dlCount)
+	return ctx, nil
+}
+
+// NasCounts returns the UL and DL NAS COUNTs of the 3GPP access NAS context
+func (ctx *SecurityContext) NasCounts() (ul, dl uint32) {
+	local, remote := ctx.gppNas.Counters()
+	if ctx.isAmf {
+		return remote, local
+	}
+	return local, remote
+}
+
+func (ctx *SecurityContext) setNasCounts(ul, dl uint32) {
+	local, remote := ul, dl
+	if ctx.isAmf {
+		local, remote = dl, ul
+	}
+	ctx.gppNas.SetCounters(local, remote)
+}
@@ -0,0 +1,141 @@
+package sec
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math/big"
+
+	"github.com/reogac/nas"
+)
+
+const (
+	// lengths of the ECIES profiles of TS 33.501 C.3.4
+	SUCI_ENC_KEY_LEN = 16 // AES-128 key
+	SUCI_ICB_LEN     = 16 // initial counter block of AES-128 CTR
+	SUCI_MAC_KEY_LEN = 32 // HMAC-SHA-256 key
+	SUCI_MAC_TAG_LEN = 8
+)
+
+// HomeNetworkKey is the public key of the home network for an ECIES protection scheme
+type HomeNetworkKey struct {
+	scheme uint8
+	key    *ecdh.PublicKey
+}
+
+// NewHomeNetworkKey parses a home network public key: 32 octets for Profile A, a compressed (33
+// octets) or uncompressed (65 octets) point for Profile B
+func NewHomeNetworkKey(scheme uint8, raw []byte) (*HomeNetworkKey, error) {
+	var key *ecdh.PublicKey
+	var err error
+	switch scheme {
+	case nas.ProtectionSchemeECIESProfileA:
+		key, err = ecdh.X25519().NewPublicKey(raw)
+	case nas.ProtectionSchemeECIESProfileB:
+		if len(raw) == 33 {
+			x, y := elliptic.UnmarshalCompressed(elliptic.P256(), raw)
+			if x == nil {
+				return nil, fmt.Errorf("invalid compressed secp256r1 point")
+			}
+			raw = uncompressedPoint(x, y)
+		}
+		key, err = ecdh.P256().NewPublicKey(raw)
+	default:
+		return nil, fmt.Errorf("protection scheme %d is not an ECIES profile", scheme)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &HomeNetworkKey{scheme: scheme, key: key}, nil
+}
+
+// Conceal returns the scheme output of a SUCI (TS 33.501 C.3.2): a fresh ephemeral public key, the
+// plaintext encrypted with AES-128 CTR and the MAC tag of the ciphertext
+func (hn *HomeNetworkKey) Conceal(plaintext []byte) ([]byte, error) {
+	ephemeral, err := hn.key.Curve().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return hn.conceal(plaintext, ephemeral)
+}
+
+// conceal computes the scheme output with the given ephemeral key pair
+func (hn *HomeNetworkKey) conceal(plaintext []byte, ephemeral *ecdh.PrivateKey) ([]byte, error) {
+	shared, err := ephemeral.ECDH(hn.key)
+	if err != nil {
+		return nil, err
+	}
+	// Profile B sends the ephemeral public key with point compression
+	ephemeralKey := ephemeral.PublicKey().Bytes()
+	if hn.scheme == nas.ProtectionSchemeECIESProfileB {
+		x, y := new(big.Int).SetBytes(ephemeralKey[1:33]), new(big.Int).SetBytes(ephemeralKey[33:])
+		ephemeralKey = elliptic.MarshalCompressed(elliptic.P256(), x, y)
+	}
+
+	sealed, err := seal(shared, ephemeralKey, plaintext)
+	if err != nil {
+		return nil, err
+	}
+	return append(ephemeralKey, sealed...), nil
+}
+
+// seal returns the ciphertext and MAC tag of the plaintext with the keys derived from the shared
+// secret of the ECDH and the ephemeral public key sent in the scheme output
+func seal(shared, ephemeralKey, plaintext []byte) ([]byte, error) {
+	keys := ansiX963Kdf(shared, ephemeralKey, SUCI_ENC_KEY_LEN+SUCI_ICB_LEN+SUCI_MAC_KEY_LEN)
+	encKey := keys[:SUCI_ENC_KEY_LEN]
+	icb := keys[SUCI_ENC_KEY_LEN : SUCI_ENC_KEY_LEN+SUCI_ICB_LEN]
+	macKey := keys[SUCI_ENC_KEY_LEN+SUCI_ICB_LEN:]
+
+	block, err := aes.NewCipher(encKey)
+	if err != nil {
+		return nil, err
+	}
+	ciphertext := make([]byte, len(plaintext), len(plaintext)+SUCI_MAC_TAG_LEN)
+	cipher.NewCTR(block, icb).XORKeyStream(ciphertext, plaintext)
+
+	mac := hmac.New(sha256.New, macKey)
+	mac.Write(ciphertext)
+	return append(ciphertext, mac.Sum(nil)[:SUCI_MAC_TAG_LEN]...), nil
+}
+
+// ansiX963Kdf is the key derivation function of SEC 1 3.6.1 with SHA-256
+func ansiX963Kdf(shared, sharedInfo []byte, length int) []byte {
+	var out []byte
+	counter := make([]byte, 4)
+	for i := uint32(1); len(out) < length; i++ {
+		binary.BigEndian.PutUint32(counter, i)
+		h := sha256.New()
+		h.Write(shared)
+		h.Write(counter)
+		h.Write(sharedInfo)
+		out = h.Sum(out)
+	}
+	return out[:length]
+}
+
+func uncompressedPoint(x, y *big.Int) []byte {
+	point := make([]byte, 65)
+	point[0] = 0x04
+	x.FillBytes(point[1:33])
+	y.FillBytes(point[33:])
+	return point
+}
+
+func (hn *HomeNetworkKey) ConcealForTest(plaintext []byte, ephemeral *ecdh.PrivateKey) ([]byte, error) {
+	return hn.conceal(plaintext, ephemeral)
+}
+
+func SealForTest(shared, ephemeralKey, plaintext []byte) ([]byte, error) {
+	return seal(shared, ephemeralKey, plaintext)
+}
+
+func AnsiX963KdfForTest(shared, sharedInfo []byte, length int) []byte {
+	return ansiX963Kdf(shared, sharedInfo, length)
+}
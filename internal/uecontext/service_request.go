@@ -0,0 +1,205 @@
+package uecontext
+
+import (
+	"fmt"
+
+	"github.com/lvdund/asn1go/aper"
+	rrcies "github.com/lvdund/rrc/ies"
+	"github.com/reogac/nas"
+)
+
+// TriggerServiceRequest brings a registered UE back to connected mode (TS 24.501 5.6.1). From RRC
+// idle a new RRC connection is set up with the 5G-S-TMSI of the UE. With service type data the
+// user plane of all stored PDU sessions is requested through the uplink data status.
+func (ue *UeContext) TriggerServiceRequest(serviceType uint8) error {
+	if ue.GetState() != UE_STATE_REGISTERED {
+		return fmt.Errorf("UE is not registered")
+	}
+	ue.mutex.Lock()
+	guti := ue.guti
+	ngKsi := ue.auth.ngKsi
+	ue.mutex.Unlock()
+	nasCtx := ue.getNasContext()
+	if guti == nil || nasCtx == nil {
+		return fmt.Errorf("service request needs a 5G-GUTI and a NAS security context")
+	}
+
+	status := ue.pduSessionStatus()
+	msg := &nas.ServiceRequest{
+		Ngksi:       ngKsi,
+		ServiceType: serviceType,
+		STmsi: nas.MobileIdentity{
+			Id: &nas.Tmsi5Gs{AmfId: guti.AmfId, Tmsi: guti.Tmsi},
+		},
+		PduSessionStatus: &nas.PduSessionStatus{},
+	}
+	msg.PduSessionStatus.Set(status)
+	if serviceType == nas.ServiceTypeData {
+		msg.UplinkDataStatus = &nas.UplinkDataStatus{}
+		msg.UplinkDataStatus.Set(status)
+	}
+	msg.SetSecurityHeader(nas.NasSecNone)
+	plainPdu, err := nas.EncodeMm(nil, msg)
+	if err != nil {
+		return fmt.Errorf("encode service request: %w", err)
+	}
+	// initial NAS message, integrity protected only: the PDU session status and the uplink data
+	// status are not cleartext IEs, the entire message goes ciphered in the NAS message container
+	// (TS 24.501 4.4.6)
+	container, err := nasCtx.EncryptMmContainer(plainPdu)
+	if err != nil {
+		return fmt.Errorf("cipher service request: %w", err)
+	}
+	msg.PduSessionStatus = nil
+	msg.UplinkDataStatus = nil
+	msg.NasMessageContainer = container
+	msg.SetSecurityHeader(nas.NasSecIntegrity)
+	nasPdu, err := nas.EncodeMm(nasCtx, msg)
+	if err != nil {
+		return fmt.Errorf("encode service request: %w", err)
+	}
+
+	ue.mutex.Lock()
+	ue.serviceRequest = true
+	ue.mutex.Unlock()
+
+	ue.Info("Sending Service Request, service type %d, PDU sessions %v", serviceType, sessionIds(status))
+	ue.startTimer(&ue.t3517, ue.procTimers.t3517, ue.onT3517Expiry)
+	if ue.isRrcIdle() {
+		cause := rrcies.EstablishmentCause_Enum_mo_Signalling
+		if serviceType == nas.ServiceTypeData {
+			cause = rrcies.EstablishmentCause_Enum_mo_Data
+		}
+		return ue.establishRrcConnection(nasPdu, cause)
+	}
+	ue.Send_UlInformationTransfer_To_Du(nasPdu)
+	return nil
+}
+
+// requestUserPlane starts a service request for UL data of the UE in RRC idle, once until it completes.
+// Out of the registration area the user plane is requested by a mobility registration update.
+func (ue *UeContext) requestUserPlane() {
+	ue.mutex.Lock()
+	pending := ue.serviceRequest
+	ue.serviceRequest = true
+	ue.mutex.Unlock()
+	if pending {
+		return
+	}
+	go func() {
+		if !ue.inRegistrationArea() {
+			err := ue.triggerRegistrationUpdate(nas.RegistrationType5GSMobilityRegistrationUpdating, true)
+			if err != nil {
+				ue.Error("Registration update for UL data failed: %v", err)
+			}
+			ue.mutex.Lock()
+			ue.serviceRequest = false
+			ue.mutex.Unlock()
+			return
+		}
+		if err := ue.TriggerServiceRequest(nas.ServiceTypeData); err != nil {
+			ue.Error("Service request for UL data failed: %v", err)
+			ue.mutex.Lock()
+			ue.serviceRequest = false
+			ue.mutex.Unlock()
+		}
+	}()
+}
+
+func (ue *UeContext) handleServiceAccept(message *nas.ServiceAccept) {
+	ue.stopTimer(&ue.t3517)
+	ue.mutex.Lock()
+	ue.serviceRequest = false
+	ue.mutex.Unlock()
+
+	if message.PduSessionStatus != nil {
+		ue.syncPduSessionStatus(message.PduSessionStatus)
+	}
+	if message.PduSessionReactivationResult != nil {
+		result := message.PduSessionReactivationResult.Get()
+		for sessionId := uint8(1); sessionId <= 15; sessionId++ {
+			if result[sessionId] {
+				ue.Warn("User plane of PDU session %d not re-activated", sessionId)
+			}
+		}
+	}
+	ue.Info("Service request accepted")
+}
+
+// handleServiceReject handles the 5GMM cause of a Service Reject (TS 24.501 5.6.1.5)
+func (ue *UeContext) handleServiceReject(message *nas.ServiceReject) {
+	ue.stopTimer(&ue.t3517)
+	ue.mutex.Lock()
+	ue.serviceRequest = false
+	ue.mutex.Unlock()
+	ue.handleCause5GMM(&message.GmmCause)
+
+	switch message.GmmCause {
+	case nas.Cause5GMMUEIdentityCannotBeDerivedByTheNetwork, nas.Cause5GMMImplicitlyDeregistered:
+		// the network lost the UE context, register again
+		ue.releaseAllPduSessionsLocally()
+		ue.SetState(UE_STATE_DEREGISTERED)
+		if message.GmmCause == nas.Cause5GMMUEIdentityCannotBeDerivedByTheNetwork {
+			ue.deleteIdentity()
+		}
+		ue.registerAfterRelease()
+	default:
+		if dropIdentity, _ := deregistrationCauseAction(message.GmmCause); dropIdentity {
+			ue.releaseAllPduSessionsLocally()
+			ue.SetState(UE_STATE_DEREGISTERED)
+			ue.deleteIdentity()
+		}
+	}
+}
+
+// syncPduSessionStatus releases locally the PDU sessions the network does not have any more
+func (ue *UeContext) syncPduSessionStatus(status *nas.PduSessionStatus) {
+	active := status.Get()
+	for sessionId := uint8(1); sessionId <= 15; sessionId++ {
+		if !active[sessionId] && ue.getPduSession(sessionId) != nil {
+			ue.Warn("PDU session %d not active in the network", sessionId)
+			ue.releasePduSession(sessionId)
+		}
+	}
+}
+
+// pduSessionStatus returns the PDU session status flags of the stored PDU sessions, by PDU session ID
+func (ue *UeContext) pduSessionStatus() [16]bool {
+	var status [16]bool
+	for _, session := range ue.getActivePduSessions() {
+		status[session.id] = true
+	}
+	return status
+}
+
+// get5gSTmsi returns the 48-bit 5G-S-TMSI (AMF Set ID, AMF Pointer, 5G-TMSI) of the 5G-GUTI
+func (ue *UeContext) get5gSTmsi() (uint64, bool) {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	if ue.guti == nil {
+		return 0, false
+	}
+	return uint64(ue.guti.AmfId.GetSet())<<38 | uint64(ue.guti.AmfId.GetPointer())<<32 | uint64(ue.guti.Tmsi), true
+}
+
+func sessionIds(status [16]bool) []uint8 {
+	var ids []uint8
+	for id, set := range status {
+		if set {
+			ids = append(ids, uint8(id))
+		}
+	}
+	return ids
+}
+
+// bitString returns the n rightmost bits of v as an ASN.1 bit string
+func bitString(v uint64, n int) aper.BitString {
+	size := (n + 7) / 8
+	v <<= uint(size*8 - n)
+	b := make([]byte, size)
+	for i := size - 1; i >= 0; i-- {
+		b[i] = byte(v)
+		v >>= 8
+	}
+	return aper.BitString{Bytes: b, NumBits: uint64(n)}
+}
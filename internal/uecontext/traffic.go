@@ -0,0 +1,541 @@
+package uecontext
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"net"
+	"time"
+
+	"du_ue/pkg/config"
+)
+
+// Traffic types of the user plane traffic generator
+const (
+	TRAFFIC_ICMP = "icmp"
+	TRAFFIC_UDP  = "udp"
+	TRAFFIC_TCP  = "tcp"
+)
+
+const (
+	DEFAULT_TRAFFIC_COUNT    = 10
+	DEFAULT_TRAFFIC_INTERVAL = 1000    // ms
+	DEFAULT_ICMP_SIZE        = 56      // bytes
+	DEFAULT_UDP_SIZE         = 1000    // bytes
+	DEFAULT_UDP_RATE         = 1000    // kbit/s
+	DEFAULT_TCP_MSS          = 1400    // bytes
+	DEFAULT_TCP_BYTES        = 1 << 20 // bytes
+
+	TRAFFIC_REPLY_TIMEOUT = 2 * time.Second // wait for late replies after the last packet
+	TRAFFIC_DRB_TIMEOUT   = 5 * time.Second // wait for the DRB of a new PDU session
+	TCP_WINDOW_SEGMENTS   = 16
+	TCP_INITIAL_RTO       = time.Second
+	TCP_MIN_RTO           = 200 * time.Millisecond
+	TCP_MAX_RTO           = 8 * time.Second
+	TCP_SYN_RETRIES       = 3
+	tcpReceiveWindow      = 65535
+	trafficQueueLen       = 1024
+	trafficHeaderLen      = 12 // sequence number and send time in UDP payloads
+)
+
+// TrafficStats are the results of a traffic run on a PDU session
+type TrafficStats struct {
+	Type            string
+	PacketsSent     uint64
+	PacketsReceived uint64 // ICMP echo replies, UDP packets echoed back, TCP segments from the peer
+	PacketsLost     uint64 // ICMP/UDP: sent without reply, TCP: retransmitted segments
+	BytesSent       uint64 // TCP: bytes acknowledged by the peer
+	BytesReceived   uint64
+	RttMin          time.Duration
+	RttAvg          time.Duration
+	RttMax          time.Duration
+	Duration        time.Duration
+
+	rttSum     time.Duration
+	rttSamples int64
+}
+
+func (s *TrafficStats) addRtt(rtt time.Duration) {
+	if s.rttSamples == 0 || rtt < s.RttMin {
+		s.RttMin = rtt
+	}
+	if rtt > s.RttMax {
+		s.RttMax = rtt
+	}
+	s.rttSamples++
+	s.rttSum += rtt
+	s.RttAvg = s.rttSum / time.Duration(s.rttSamples)
+}
+
+// UlThroughput returns the UL throughput in bit/s
+func (s *TrafficStats) UlThroughput() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.BytesSent*8) / s.Duration.Seconds()
+}
+
+// DlThroughput returns the DL throughput in bit/s
+func (s *TrafficStats) DlThroughput() float64 {
+	if s.Duration <= 0 {
+		return 0
+	}
+	return float64(s.BytesReceived*8) / s.Duration.Seconds()
+}
+
+// LossRate returns the share of lost packets in percent
+func (s *TrafficStats) LossRate() float64 {
+	if s.PacketsSent == 0 {
+		return 0
+	}
+	return float64(s.PacketsLost) * 100 / float64(s.PacketsSent)
+}
+
+func (s *TrafficStats) String() string {
+	return fmt.Sprintf("%s: sent=%d received=%d lost=%d (%.1f%%) rtt min/avg/max=%v/%v/%v UL=%.1f kbit/s DL=%.1f kbit/s in %v",
+		s.Type, s.PacketsSent, s.PacketsReceived, s.PacketsLost, s.LossRate(),
+		s.RttMin, s.RttAvg, s.RttMax, s.UlThroughput()/1000, s.DlThroughput()/1000, s.Duration.Round(time.Millisecond))
+}
+
+// trafficFlow receives the DL packets of the session while a traffic run is going on
+type trafficFlow struct {
+	in     chan *ipv4Packet
+	mapped bool // QoS flow mapping logged
+}
+
+// RunTraffic runs user plane traffic on a PDU session and returns its statistics
+func (ue *UeContext) RunTraffic(sessionId uint8, cfg config.TrafficConfig) (*TrafficStats, error) {
+	ps := ue.getPduSession(sessionId)
+	if ps == nil {
+		return nil, fmt.Errorf("PDU session %d not found", sessionId)
+	}
+	return ps.RunTraffic(cfg)
+}
+
+// RunTraffic sends traffic sourced from the session IP over the DRB of the session. It blocks until
+// the run is over, a session runs one traffic flow at a time.
+func (ps *PduSession) RunTraffic(cfg config.TrafficConfig) (*TrafficStats, error) {
+	if !ps.IsActive() {
+		return nil, fmt.Errorf("PDU session %d not active", ps.id)
+	}
+	src := net.ParseIP(ps.GetIP()).To4()
+	if src == nil {
+		return nil, fmt.Errorf("PDU session %d has no IPv4 address", ps.id)
+	}
+	dst := net.ParseIP(cfg.Dst).To4()
+	if dst == nil {
+		return nil, fmt.Errorf("invalid IPv4 traffic destination %q", cfg.Dst)
+	}
+
+	flow := &trafficFlow{in: make(chan *ipv4Packet, trafficQueueLen)}
+	ps.mutex.Lock()
+	if ps.flow != nil {
+		ps.mutex.Unlock()
+		return nil, fmt.Errorf("traffic already running on PDU session %d", ps.id)
+	}
+	ps.flow = flow
+	ps.mutex.Unlock()
+	defer func() {
+		ps.mutex.Lock()
+		ps.flow = nil
+		ps.mutex.Unlock()
+	}()
+
+	ps.Info("Starting %s traffic %s -> %s", cfg.Type, src, dst)
+	var stats *TrafficStats
+	var err error
+	switch cfg.Type {
+	case TRAFFIC_ICMP:
+		stats, err = ps.runIcmpEcho(flow, src, dst, cfg)
+	case TRAFFIC_UDP:
+		stats, err = ps.runUdpCbr(flow, src, dst, cfg)
+	case TRAFFIC_TCP:
+		stats, err = ps.runTcpBulk(flow, src, dst, cfg)
+	default:
+		return nil, fmt.Errorf("unknown traffic type %q", cfg.Type)
+	}
+	if err != nil {
+		ps.Error("%s traffic failed: %v", cfg.Type, err)
+		return stats, err
+	}
+	ps.Info("Traffic done, %s", stats)
+	return stats, nil
+}
+
+// runConfiguredTraffic runs the traffic of the UE config once the session has its DRB
+func (ue *UeContext) runConfiguredTraffic(ps *PduSession, reason string) {
+	deadline := time.Now().Add(TRAFFIC_DRB_TIMEOUT)
+	for {
+		if _, ok := ue.drbOfSession(ps.id); ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			ps.Warn("No DRB for PDU session %d, skipping traffic %s", ps.id, reason)
+			return
+		}
+		select {
+		case <-time.After(100 * time.Millisecond):
+		case <-ue.ctx.Done():
+			return
+		}
+	}
+
+	ps.Info("Running configured traffic %s", reason)
+	ps.RunTraffic(ue.traffic)
+}
+
+// runTrafficAfterHandover checks the data connectivity of the active sessions in the target cell
+func (ue *UeContext) runTrafficAfterHandover() {
+	if ue.traffic.Type == "" || !ue.traffic.AfterHandover {
+		return
+	}
+	for _, ps := range ue.getActivePduSessions() {
+		go ue.runConfiguredTraffic(ps, "after handover")
+	}
+}
+
+// sendPacket sends a UL packet of the session, the QoS flow and DRB of a traffic flow are logged once
+func (ps *PduSession) sendPacket(flow *trafficFlow, pkt []byte) error {
+	if flow != nil && !flow.mapped {
+		flow.mapped = true
+		if qfi, ok := ps.classifyUl(pkt); ok {
+			if drbId, _, ok := ps.ue.drbOfFlow(ps.id, qfi); ok {
+				ps.Info("Traffic mapped to QFI %d on DRB %d", qfi, drbId)
+			}
+		}
+	}
+	return ps.ue.sendUlData(ps.id, pkt)
+}
+
+// runIcmpEcho sends echo requests at the configured interval and matches the echo replies
+func (ps *PduSession) runIcmpEcho(flow *trafficFlow, src, dst net.IP, cfg config.TrafficConfig) (*TrafficStats, error) {
+	count := valueOr(cfg.Count, DEFAULT_TRAFFIC_COUNT)
+	interval := time.Duration(valueOr(cfg.Interval, DEFAULT_TRAFFIC_INTERVAL)) * time.Millisecond
+	size := valueOr(cfg.Size, DEFAULT_ICMP_SIZE)
+	id := uint16(rand.Intn(0x10000))
+
+	stats := &TrafficStats{Type: TRAFFIC_ICMP}
+	sentAt := make([]time.Time, count)
+	replied := make([]bool, count)
+	start := time.Now()
+
+	handle := func(pkt *ipv4Packet) {
+		b := pkt.payload
+		if pkt.proto != IP_PROTO_ICMP || !pkt.src.Equal(dst) || len(b) < icmpHeaderLen || b[0] != 0 {
+			return
+		}
+		seq := int(binary.BigEndian.Uint16(b[6:]))
+		if binary.BigEndian.Uint16(b[4:]) != id || seq >= count || sentAt[seq].IsZero() || replied[seq] {
+			return
+		}
+		replied[seq] = true
+		rtt := time.Since(sentAt[seq])
+		stats.PacketsReceived++
+		stats.BytesReceived += uint64(len(b))
+		stats.addRtt(rtt)
+		ps.Debug("Echo reply from %s: seq=%d time=%v", dst, seq, rtt)
+	}
+
+	for seq := 0; seq < count; seq++ {
+		pkt := buildIPv4(src, dst, IP_PROTO_ICMP, uint16(seq), buildIcmpEcho(id, uint16(seq), make([]byte, size)))
+		sentAt[seq] = time.Now()
+		if err := ps.sendPacket(flow, pkt); err != nil {
+			return stats, err
+		}
+		stats.PacketsSent++
+		stats.BytesSent += uint64(len(pkt) - ipv4HeaderLen)
+
+		wait := interval
+		if seq == count-1 {
+			wait = TRAFFIC_REPLY_TIMEOUT
+		}
+		if err := ps.drainFlow(flow, time.Now().Add(wait), handle, func() bool { return stats.PacketsReceived == uint64(count) }); err != nil {
+			return stats, err
+		}
+	}
+
+	stats.Duration = time.Since(start)
+	stats.PacketsLost = stats.PacketsSent - stats.PacketsReceived
+	return stats, nil
+}
+
+// runUdpCbr sends UDP packets at a constant bitrate, the packets echoed back by the destination
+// give RTT and loss
+func (ps *PduSession) runUdpCbr(flow *trafficFlow, src, dst net.IP, cfg config.TrafficConfig) (*TrafficStats, error) {
+	count := valueOr(cfg.Count, DEFAULT_TRAFFIC_COUNT)
+	size := max(valueOr(cfg.Size, DEFAULT_UDP_SIZE), trafficHeaderLen)
+	rate := valueOr(cfg.Rate, DEFAULT_UDP_RATE)
+	interval := time.Duration(float64((ipv4HeaderLen+udpHeaderLen+size)*8) / float64(rate*1000) * float64(time.Second))
+	sport := ephemeralPort()
+	dport := uint16(cfg.Port)
+
+	stats := &TrafficStats{Type: TRAFFIC_UDP}
+	sentAt := make([]time.Time, count)
+	echoed := make([]bool, count)
+	start := time.Now()
+
+	handle := func(pkt *ipv4Packet) {
+		b := pkt.payload
+		if pkt.proto != IP_PROTO_UDP || !pkt.src.Equal(dst) || len(b) < udpHeaderLen {
+			return
+		}
+		if binary.BigEndian.Uint16(b[0:]) != dport || binary.BigEndian.Uint16(b[2:]) != sport {
+			return
+		}
+		stats.BytesReceived += uint64(len(b))
+		data := b[udpHeaderLen:]
+		if len(data) < trafficHeaderLen {
+			return
+		}
+		seq := int(binary.BigEndian.Uint32(data))
+		if seq >= count || sentAt[seq].IsZero() || echoed[seq] {
+			return
+		}
+		echoed[seq] = true
+		stats.PacketsReceived++
+		stats.addRtt(time.Since(sentAt[seq]))
+	}
+
+	next := start
+	for seq := 0; seq < count; seq++ {
+		data := make([]byte, size)
+		binary.BigEndian.PutUint32(data, uint32(seq))
+		binary.BigEndian.PutUint64(data[4:], uint64(time.Now().UnixNano()))
+		pkt := buildIPv4(src, dst, IP_PROTO_UDP, uint16(seq), buildUdp(src, dst, sport, dport, data))
+		sentAt[seq] = time.Now()
+		if err := ps.sendPacket(flow, pkt); err != nil {
+			return stats, err
+		}
+		stats.PacketsSent++
+		stats.BytesSent += uint64(len(pkt) - ipv4HeaderLen)
+
+		next = next.Add(interval)
+		if err := ps.drainFlow(flow, next, handle, nil); err != nil {
+			return stats, err
+		}
+	}
+	sendDuration := time.Since(start)
+
+	if err := ps.drainFlow(flow, time.Now().Add(TRAFFIC_REPLY_TIMEOUT), handle, func() bool { return stats.PacketsReceived == uint64(count) }); err != nil {
+		return stats, err
+	}
+	// throughput over the sending time, not the wait for late echoes
+	stats.Duration = sendDuration
+	if stats.PacketsReceived > 0 {
+		stats.PacketsLost = stats.PacketsSent - stats.PacketsReceived
+	}
+	return stats, nil
+}
+
+// runTcpBulk uploads bytes over a TCP connection with a fixed window, go-back-N retransmission
+// on timeout, and RTT sampled from acknowledgements of segments sent once
+func (ps *PduSession) runTcpBulk(flow *trafficFlow, src, dst net.IP, cfg config.TrafficConfig) (*TrafficStats, error) {
+	total := uint32(valueOr(cfg.Bytes, DEFAULT_TCP_BYTES))
+	mss := uint32(valueOr(cfg.Size, DEFAULT_TCP_MSS))
+	sport := ephemeralPort()
+	dport := uint16(cfg.Port)
+	iss := rand.Uint32()
+	var ipId uint16
+
+	stats := &TrafficStats{Type: TRAFFIC_TCP}
+	send := func(seg *tcpSegment) error {
+		seg.sport, seg.dport, seg.window = sport, dport, tcpReceiveWindow
+		ipId++
+		if err := ps.sendPacket(flow, buildIPv4(src, dst, IP_PROTO_TCP, ipId, buildTcp(src, dst, seg))); err != nil {
+			return err
+		}
+		stats.PacketsSent++
+		return nil
+	}
+	// receive returns the next segment of the connection, nil on timeout
+	receive := func(timeout time.Duration) (*tcpSegment, error) {
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+		for {
+			select {
+			case pkt := <-flow.in:
+				if pkt.proto != IP_PROTO_TCP || !pkt.src.Equal(dst) {
+					continue
+				}
+				seg, ok := parseTcp(pkt.payload)
+				if !ok || seg.sport != dport || seg.dport != sport {
+					continue
+				}
+				stats.PacketsReceived++
+				if seg.flags&tcpFlagRst != 0 {
+					return nil, fmt.Errorf("connection reset by %s:%d", dst, dport)
+				}
+				return seg, nil
+			case <-timer.C:
+				return nil, nil
+			case <-ps.ue.ctx.Done():
+				return nil, ps.ue.ctx.Err()
+			}
+		}
+	}
+
+	start := time.Now()
+
+	// three-way handshake
+	var rcvNxt uint32
+	established := false
+	rto := TCP_INITIAL_RTO
+	for attempt := 0; attempt < TCP_SYN_RETRIES && !established; attempt++ {
+		synAt := time.Now()
+		if err := send(&tcpSegment{seq: iss, flags: tcpFlagSyn}); err != nil {
+			return stats, err
+		}
+		deadline := synAt.Add(rto)
+		for time.Now().Before(deadline) {
+			seg, err := receive(time.Until(deadline))
+			if err != nil {
+				return stats, err
+			}
+			if seg == nil {
+				break
+			}
+			if seg.flags&(tcpFlagSyn|tcpFlagAck) == tcpFlagSyn|tcpFlagAck && seg.ack == iss+1 {
+				rcvNxt = seg.seq + 1
+				if attempt == 0 {
+					stats.addRtt(time.Since(synAt))
+				}
+				established = true
+				break
+			}
+		}
+		rto = min(rto*2, TCP_MAX_RTO)
+	}
+	if !established {
+		stats.Duration = time.Since(start)
+		return stats, fmt.Errorf("no SYN-ACK from %s:%d", dst, dport)
+	}
+	if err := send(&tcpSegment{seq: iss + 1, ack: rcvNxt, flags: tcpFlagAck}); err != nil {
+		return stats, err
+	}
+
+	// data, offsets are relative to the first data byte
+	var sndUna, sndNxt uint32
+	sentAt := make(map[uint32]time.Time) // segment end offset -> send time, segments sent once
+	rto = tcpRto(stats)
+	rtoAt := time.Now().Add(rto)
+	for sndUna < total {
+		for sndNxt < total && sndNxt-sndUna < TCP_WINDOW_SEGMENTS*mss {
+			n := min(mss, total-sndNxt)
+			if err := send(&tcpSegment{seq: iss + 1 + sndNxt, ack: rcvNxt, flags: tcpFlagAck | tcpFlagPsh, data: make([]byte, n)}); err != nil {
+				return stats, err
+			}
+			if _, seen := sentAt[sndNxt+n]; !seen {
+				sentAt[sndNxt+n] = time.Now()
+			}
+			if sndNxt == sndUna {
+				rtoAt = time.Now().Add(rto)
+			}
+			sndNxt += n
+		}
+
+		seg, err := receive(time.Until(rtoAt))
+		if err != nil {
+			return stats, err
+		}
+		if seg == nil {
+			// retransmission timeout, go back to the first unacknowledged byte
+			stats.PacketsLost++
+			for end := range sentAt {
+				if end > sndUna {
+					sentAt[end] = time.Time{} // do not sample retransmitted segments
+				}
+			}
+			sndNxt = sndUna
+			rto = min(rto*2, TCP_MAX_RTO)
+			rtoAt = time.Now().Add(rto)
+			continue
+		}
+
+		if len(seg.data) > 0 {
+			if seg.seq == rcvNxt {
+				rcvNxt += uint32(len(seg.data))
+				stats.BytesReceived += uint64(len(seg.data))
+			}
+			if err := send(&tcpSegment{seq: iss + 1 + sndNxt, ack: rcvNxt, flags: tcpFlagAck}); err != nil {
+				return stats, err
+			}
+		}
+		if seg.flags&tcpFlagAck == 0 {
+			continue
+		}
+		acked := seg.ack - (iss + 1)
+		if acked <= sndUna || acked > sndNxt {
+			continue
+		}
+		if t, ok := sentAt[acked]; ok && !t.IsZero() {
+			stats.addRtt(time.Since(t))
+		}
+		for end := range sentAt {
+			if end <= acked {
+				delete(sentAt, end)
+			}
+		}
+		stats.BytesSent += uint64(acked - sndUna)
+		sndUna = acked
+		rto = tcpRto(stats)
+		rtoAt = time.Now().Add(rto)
+	}
+	stats.Duration = time.Since(start)
+
+	// close, the connection is done whether or not the peer answers
+	finSeq := iss + 1 + total
+	if err := send(&tcpSegment{seq: finSeq, ack: rcvNxt, flags: tcpFlagFin | tcpFlagAck}); err != nil {
+		return stats, err
+	}
+	deadline := time.Now().Add(rto)
+	for time.Now().Before(deadline) {
+		seg, err := receive(time.Until(deadline))
+		if err != nil || seg == nil {
+			break
+		}
+		if seg.flags&tcpFlagFin != 0 {
+			send(&tcpSegment{seq: finSeq + 1, ack: seg.seq + uint32(len(seg.data)) + 1, flags: tcpFlagAck})
+			break
+		}
+	}
+	return stats, nil
+}
+
+// tcpRto returns the retransmission timeout from the RTT seen so far
+func tcpRto(stats *TrafficStats) time.Duration {
+	if stats.rttSamples == 0 {
+		return TCP_INITIAL_RTO
+	}
+	return min(max(2*stats.RttAvg, TCP_MIN_RTO), TCP_MAX_RTO)
+}
+
+// drainFlow hands the DL packets of the flow to handle until the deadline or until done
+func (ps *PduSession) drainFlow(flow *trafficFlow, deadline time.Time, handle func(*ipv4Packet), done func() bool) error {
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	for {
+		if done != nil && done() {
+			return nil
+		}
+		select {
+		case pkt := <-flow.in:
+			handle(pkt)
+		case <-timer.C:
+			return nil
+		case <-ps.ue.ctx.Done():
+			return ps.ue.ctx.Err()
+		}
+	}
+}
+
+// ephemeralPort returns a random port of the dynamic range
+func ephemeralPort() uint16 {
+	return uint16(49152 + rand.Intn(16384))
+}
+
+func valueOr(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
@@ -1,36 +1,322 @@
 package uecontext
 
 import (
+	"fmt"
+	"time"
+
+	rrcies "github.com/lvdund/rrc/ies"
 	"github.com/reogac/nas"
 )
 
-// TriggerInitRegistration creates and sends Registration Request NAS message
-func (ue *UeContext) TriggerInitRegistration() error {
+const (
+	// de-registration access types
+	DEREGISTRATION_ACCESS_3GPP     = 1
+	DEREGISTRATION_ACCESS_NON_3GPP = 2
+	DEREGISTRATION_ACCESS_BOTH     = 3
+
+	REREGISTRATION_RELEASE_WAIT = 5 * time.Second // wait for RRCRelease before registering on the same connection
+)
+
+// TriggerInitRegistration creates the Registration Request NAS message of an initial registration
+// and returns it for the initial NAS message of the RRC connection. A UE with a 5G-GUTI and a native
+// security context, e.g. kept from an earlier run, is identified by the 5G-GUTI and integrity
+// protects the request; otherwise the request carries the SUCI in clear text.
+func (ue *UeContext) TriggerInitRegistration() ([]byte, error) {
 	ue.Info("Initiating Registration")
 
+	ue.mutex.Lock()
+	guti := ue.guti
+	ngKsi := ue.auth.ngKsi
+	requestedNssai := ue.allowedNssai
+	if requestedNssai == nil {
+		requestedNssai = ue.configuredNssai
+	}
+	ue.mutex.Unlock()
+	nasCtx := ue.getNasContext()
+
 	msg := &nas.RegistrationRequest{
 		UeSecurityCapability: ue.secCap,
+		RequestedNssai:       requestedNssai,
 	}
 	msg.RegistrationType = nas.NewRegistrationType(true, nas.RegistrationType5GSInitialRegistration)
 
-	msg.MobileIdentity = ue.suci
-	msg.Ngksi.Id = 7
+	if guti != nil && nasCtx != nil {
+		msg.MobileIdentity = nas.MobileIdentity{Id: guti}
+		msg.Ngksi = ngKsi
+	} else {
+		suci, err := ue.concealSuci()
+		if err != nil {
+			return nil, err
+		}
+		msg.MobileIdentity = suci
+		msg.Ngksi.Id = 7
+		nasCtx = nil
+	}
 	msg.SetSecurityHeader(nas.NasSecNone)
 
 	nasPdu, err := nas.EncodeMm(nil, msg)
 	if err != nil {
 		ue.Error("Error encoding registration request: %v", err)
-		return err
+		return nil, err
 	}
 
 	// Keep a copy for resending in security mode complete
 	ue.mutex.Lock()
 	ue.nasPdu = make([]byte, len(nasPdu))
 	copy(ue.nasPdu, nasPdu)
+	ue.registrationType = nas.RegistrationType5GSInitialRegistration
 	ue.mutex.Unlock()
 
+	if nasCtx != nil {
+		ue.Info("Registering with 5G-GUTI %s", guti.String())
+		// initial NAS message, integrity protected only: the requested NSSAI is not a cleartext IE,
+		// the entire message goes ciphered in the NAS message container (TS 24.501 4.4.6)
+		container, err := nasCtx.EncryptMmContainer(nasPdu)
+		if err != nil {
+			ue.Error("Error ciphering registration request: %v", err)
+			return nil, err
+		}
+		msg.RequestedNssai = nil
+		msg.NasMessageContainer = container
+		msg.SetSecurityHeader(nas.NasSecIntegrity)
+		if nasPdu, err = nas.EncodeMm(nasCtx, msg); err != nil {
+			ue.Error("Error encoding registration request: %v", err)
+			return nil, err
+		}
+	}
+
 	// Update state to REGISTERING
 	ue.SetState(UE_STATE_REGISTERING)
+	ue.startRegistrationTimer()
+
+	return nasPdu, nil
+}
+
+// registerAgain runs a new initial registration, on a new RRC connection when the previous one was released
+func (ue *UeContext) registerAgain() error {
+	nasPdu, err := ue.TriggerInitRegistration()
+	if err != nil {
+		return err
+	}
+
+	if ue.isRrcIdle() {
+		ue.Info("Establishing RRC connection for initial registration")
+		return ue.establishRrcConnection(nasPdu, rrcies.EstablishmentCause_Enum_mo_Signalling)
+	}
+	ue.Send_UlInformationTransfer_To_Du(nasPdu)
+	return nil
+}
+
+// registerAfterRelease runs an initial registration once the network has released the RRC
+// connection. The network may keep the connection, the UE registers on it after a while then.
+func (ue *UeContext) registerAfterRelease() {
+	ue.Info("Initial registration will start after release of the RRC connection")
+	ue.registerOnRelease(nas.RegistrationType5GSInitialRegistration)
+}
+
+// updateRegistrationAfterRelease runs a mobility registration update once the network has released
+// the RRC connection, or on the current connection when it is kept
+func (ue *UeContext) updateRegistrationAfterRelease() {
+	ue.Info("Mobility registration update will start after release of the RRC connection")
+	ue.registerOnRelease(nas.RegistrationType5GSMobilityRegistrationUpdating)
+}
+
+func (ue *UeContext) registerOnRelease(registrationType uint8) {
+	ue.mutex.Lock()
+	ue.reregister = registrationType
+	ue.mutex.Unlock()
+
+	go func() {
+		select {
+		case <-time.After(REREGISTRATION_RELEASE_WAIT):
+		case <-ue.ctx.Done():
+			return
+		}
+		ue.mutex.Lock()
+		pending := ue.reregister
+		ue.reregister = 0
+		ue.mutex.Unlock()
+		if pending == 0 {
+			return
+		}
+		ue.Info("RRC connection not released within %v", REREGISTRATION_RELEASE_WAIT)
+		ue.runPendingRegistration(pending)
+	}()
+}
+
+// runPendingRegistration runs the registration waiting for the release of the RRC connection
+func (ue *UeContext) runPendingRegistration(registrationType uint8) {
+	var err error
+	if registrationType == nas.RegistrationType5GSInitialRegistration {
+		err = ue.registerAgain()
+	} else {
+		err = ue.triggerRegistrationUpdate(registrationType, false)
+	}
+	if err != nil {
+		ue.Error("Failed to register again: %v", err)
+	}
+}
+
+// triggerRegistrationUpdate sends a mobility or periodic registration update identified by the
+// 5G-GUTI and protected with the current security context, from RRC idle on a new RRC connection.
+// Pending UL data from RRC idle asks for the user plane of the PDU sessions in the same request.
+func (ue *UeContext) triggerRegistrationUpdate(registrationType uint8, ulData bool) error {
+	ue.mutex.Lock()
+	guti := ue.guti
+	ngKsi := ue.auth.ngKsi
+	ue.mutex.Unlock()
+	nasCtx := ue.getNasContext()
+	if guti == nil || nasCtx == nil {
+		return fmt.Errorf("registration update needs a 5G-GUTI and a NAS security context")
+	}
+
+	msg := &nas.RegistrationRequest{
+		UeSecurityCapability: ue.secCap,
+		Ngksi:                ngKsi,
+		MobileIdentity:       nas.MobileIdentity{Id: guti},
+		PduSessionStatus:     &nas.PduSessionStatus{},
+	}
+	idle := ue.isRrcIdle()
+	ulData = ulData && idle
+	status := ue.pduSessionStatus()
+	msg.RegistrationType = nas.NewRegistrationType(ulData, registrationType)
+	msg.PduSessionStatus.Set(status)
+	if ulData {
+		msg.UplinkDataStatus = &nas.UplinkDataStatus{}
+		msg.UplinkDataStatus.Set(status)
+	}
+
+	// plain copy for resending in security mode complete
+	msg.SetSecurityHeader(nas.NasSecNone)
+	plainPdu, err := nas.EncodeMm(nil, msg)
+	if err != nil {
+		return fmt.Errorf("encode registration request: %w", err)
+	}
+	if idle {
+		// initial NAS message, integrity protected only: the status IEs are not cleartext IEs, the
+		// entire message goes ciphered in the NAS message container (TS 24.501 4.4.6)
+		container, err := nasCtx.EncryptMmContainer(plainPdu)
+		if err != nil {
+			return fmt.Errorf("cipher registration request: %w", err)
+		}
+		msg.PduSessionStatus = nil
+		msg.UplinkDataStatus = nil
+		msg.NasMessageContainer = container
+		msg.SetSecurityHeader(nas.NasSecIntegrity)
+	} else {
+		msg.SetSecurityHeader(nas.NasSecBoth)
+	}
+	nasPdu, err := nas.EncodeMm(nasCtx, msg)
+	if err != nil {
+		return fmt.Errorf("encode registration request: %w", err)
+	}
+
+	ue.mutex.Lock()
+	ue.nasPdu = plainPdu
+	ue.registrationType = registrationType
+	ue.mutex.Unlock()
+
+	ue.Info("Sending Registration Request, registration type %d", registrationType)
+	ue.startRegistrationTimer()
+	if idle {
+		cause := rrcies.EstablishmentCause_Enum_mo_Signalling
+		if ulData {
+			cause = rrcies.EstablishmentCause_Enum_mo_Data
+		}
+		return ue.establishRrcConnection(nasPdu, cause)
+	}
+	ue.Send_UlInformationTransfer_To_Du(nasPdu)
+	return nil
+}
+
+// TriggerDeregistration de-registers the UE from the network (TS 24.501 5.5.2.2), from RRC idle on a
+// new RRC connection. A switch off de-registration is not answered and the UE de-registers at once;
+// otherwise the request is supervised by T3521 until Deregistration Accept.
+func (ue *UeContext) TriggerDeregistration(switchOff bool) error {
+	if ue.GetState() == UE_STATE_DEREGISTERED {
+		return fmt.Errorf("UE is not registered")
+	}
+	ue.stopTimer(&ue.t3510)
+	ue.stopTimer(&ue.t3511)
+	ue.stopTimer(&ue.t3502)
+	ue.stopTimer(&ue.t3346)
+	ue.mutex.Lock()
+	ue.deregistrationAttempts = 0
+	ue.mutex.Unlock()
 
+	if err := ue.sendDeregistrationRequest(switchOff); err != nil {
+		return err
+	}
+	if switchOff {
+		ue.deregisterLocally()
+		return nil
+	}
+	ue.startTimer(&ue.t3521, ue.procTimers.t3521, ue.onT3521Expiry)
 	return nil
 }
+
+// sendDeregistrationRequest sends a Deregistration Request over 3GPP access with the 5G-GUTI, or
+// the SUCI when the UE has none
+func (ue *UeContext) sendDeregistrationRequest(switchOff bool) error {
+	ue.mutex.Lock()
+	guti := ue.guti
+	ngKsi := ue.auth.ngKsi
+	ue.mutex.Unlock()
+	nasCtx := ue.getNasContext()
+
+	msg := &nas.DeregistrationRequestFromUe{}
+	msg.DeRegistrationType.SetSwitchOff(switchOff)
+	msg.DeRegistrationType.SetAccessType(DEREGISTRATION_ACCESS_3GPP)
+	if guti != nil && nasCtx != nil {
+		msg.MobileIdentity = nas.MobileIdentity{Id: guti}
+		msg.Ngksi = ngKsi
+	} else {
+		suci, err := ue.concealSuci()
+		if err != nil {
+			return err
+		}
+		msg.MobileIdentity = suci
+		msg.Ngksi.Id = 7
+		nasCtx = nil
+	}
+
+	idle := ue.isRrcIdle()
+	switch {
+	case nasCtx == nil:
+		msg.SetSecurityHeader(nas.NasSecNone)
+	case idle:
+		// initial NAS message, integrity protected only. The request has no IE other than the
+		// cleartext IEs, so no NAS message container is needed (TS 24.501 4.4.6).
+		msg.SetSecurityHeader(nas.NasSecIntegrity)
+	default:
+		msg.SetSecurityHeader(nas.NasSecBoth)
+	}
+	nasPdu, err := nas.EncodeMm(nasCtx, msg)
+	if err != nil {
+		return fmt.Errorf("encode deregistration request: %w", err)
+	}
+
+	ue.Info("Sending Deregistration Request, switch off %v", switchOff)
+	if idle {
+		return ue.establishRrcConnection(nasPdu, rrcies.EstablishmentCause_Enum_mo_Signalling)
+	}
+	ue.Send_UlInformationTransfer_To_Du(nasPdu)
+	return nil
+}
+
+// deregisterLocally ends the registration of the UE without the network: the PDU sessions are
+// released locally and the periodic registration update stops
+func (ue *UeContext) deregisterLocally() {
+	ue.stopTimer(&ue.t3521)
+	ue.releaseAllPduSessionsLocally()
+	ue.SetState(UE_STATE_DEREGISTERED)
+	ue.stopT3512()
+	ue.SaveState()
+	ue.Info("UE de-registered")
+}
+
+func (ue *UeContext) PendingRegistrationForTest() uint8 {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	return ue.reregister
+}
@@ -179,12 +179,23 @@ func (ue *UeContext) releasePduSession(sessionId uint8) {
 		return
 	}
 	ue.mutex.Lock()
-	defer ue.mutex.Unlock()
-
-	if ue.sessions[sessionId] != nil {
+	pduSession := ue.sessions[sessionId]
+	if pduSession != nil {
 		ue.Info("Released PDU Session ID: %d", sessionId)
 		ue.sessions[sessionId] = nil
 	}
+	ue.mutex.Unlock()
+
+	if pduSession != nil {
+		pduSession.closeTun()
+	}
+}
+
+// releaseAllPduSessionsLocally releases the PDU sessions of the UE without signalling
+func (ue *UeContext) releaseAllPduSessionsLocally() {
+	for sessionId := uint8(1); sessionId <= 15; sessionId++ {
+		ue.releasePduSession(sessionId)
+	}
 }
 
 // getActivePduSessions returns all active PDU sessions
@@ -0,0 +1,130 @@
+package uecontext
+
+import (
+	"fmt"
+	"os"
+
+	"du_ue/pkg/config"
+)
+
+const (
+	DEFAULT_TUN_PREFIX     = "uetun"
+	DEFAULT_TUN_TABLE_BASE = 100
+	tunMtu                 = 1500
+)
+
+// sessionTun is the TUN device of a PDU session, local applications send their packets through it
+type sessionTun struct {
+	name  string
+	ip    string
+	table int
+	file  *os.File
+}
+
+// openTun creates the TUN device of an active PDU session. Packets written to the device by local
+// applications are sent on the DRB of the session, DL packets of the session are written to it.
+func (ps *PduSession) openTun(cfg config.TunConfig) error {
+	ip := ps.GetIP()
+	if ip == "" {
+		return fmt.Errorf("PDU session %d has no IP address", ps.id)
+	}
+	prefix := cfg.Prefix
+	if prefix == "" {
+		prefix = DEFAULT_TUN_PREFIX
+	}
+	tableBase := cfg.TableBase
+	if tableBase == 0 {
+		tableBase = DEFAULT_TUN_TABLE_BASE
+	}
+	tun := &sessionTun{
+		name:  fmt.Sprintf("%s%d", prefix, ps.id),
+		ip:    ip,
+		table: tableBase + int(ps.id),
+	}
+
+	file, err := openTunDevice(tun.name)
+	if err != nil {
+		return err
+	}
+	tun.file = file
+	if err := configureTun(tun.name, tun.ip, tun.table); err != nil {
+		unconfigureTun(tun.ip, tun.table)
+		file.Close()
+		return err
+	}
+
+	ps.mutex.Lock()
+	if ps.tun != nil {
+		ps.mutex.Unlock()
+		unconfigureTun(tun.ip, tun.table)
+		file.Close()
+		return fmt.Errorf("PDU session %d already has a TUN device", ps.id)
+	}
+	ps.tun = tun
+	ps.mutex.Unlock()
+
+	ps.Info("TUN device %s up with %s, routing table %d", tun.name, tun.ip, tun.table)
+	go ps.readTun(tun)
+	return nil
+}
+
+// closeTun removes the TUN device of the session, if any
+func (ps *PduSession) closeTun() {
+	ps.mutex.Lock()
+	tun := ps.tun
+	ps.tun = nil
+	ps.mutex.Unlock()
+	if tun == nil {
+		return
+	}
+
+	if err := unconfigureTun(tun.ip, tun.table); err != nil {
+		ps.Warn("Failed to remove routing of %s: %v", tun.name, err)
+	}
+	tun.file.Close()
+	ps.Info("TUN device %s removed", tun.name)
+}
+
+// readTun sends the packets of local applications on the DRB of the session until the device is closed
+func (ps *PduSession) readTun(tun *sessionTun) {
+	buf := make([]byte, tunMtu+ipv4HeaderLen)
+	for {
+		n, err := tun.file.Read(buf)
+		if err != nil {
+			ps.Debug("TUN device %s reader stopped: %v", tun.name, err)
+			return
+		}
+		pkt := append([]byte(nil), buf[:n]...)
+		if err := ps.sendPacket(nil, pkt); err != nil {
+			ps.Warn("Failed to send packet of %s: %v", tun.name, err)
+		}
+	}
+}
+
+// writeTun hands a DL packet of the session to the local applications
+func (ps *PduSession) writeTun(tun *sessionTun, data []byte) {
+	if _, err := tun.file.Write(data); err != nil {
+		ps.Debug("Failed to write DL packet to %s: %v", tun.name, err)
+	}
+}
+
+// openConfiguredTun creates the TUN device of a new PDU session when enabled in the UE config
+func (ue *UeContext) openConfiguredTun(ps *PduSession) {
+	if err := ps.openTun(ue.tun); err != nil {
+		ps.Error("Failed to create TUN device: %v", err)
+		return
+	}
+	// the device does not outlive the UE
+	go func() {
+		<-ue.ctx.Done()
+		ps.closeTun()
+	}()
+}
+
+func (ue *UeContext) OpenTunForTest(sessionId uint8) error {
+	return ue.getPduSession(sessionId).openTun(ue.tun)
+}
+
+func (ue *UeContext) CloseTunForTest(sessionId uint8) {
+	ue.getPduSession(sessionId).closeTun()
+}
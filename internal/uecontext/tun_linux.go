@@ -0,0 +1,69 @@
+package uecontext
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"golang.org/x/sys/unix"
+)
+
+// openTunDevice creates a TUN device for IP packets without packet information
+func openTunDevice(name string) (*os.File, error) {
+	fd, err := unix.Open("/dev/net/tun", unix.O_RDWR|unix.O_CLOEXEC, 0)
+	if err != nil {
+		return nil, fmt.Errorf("open /dev/net/tun: %w", err)
+	}
+	ifr, err := unix.NewIfreq(name)
+	if err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	ifr.SetUint16(unix.IFF_TUN | unix.IFF_NO_PI)
+	if err := unix.IoctlIfreq(fd, unix.TUNSETIFF, ifr); err != nil {
+		unix.Close(fd)
+		return nil, fmt.Errorf("create TUN device %s: %w", name, err)
+	}
+	// non blocking so that closing the file interrupts a pending read
+	if err := unix.SetNonblock(fd, true); err != nil {
+		unix.Close(fd)
+		return nil, err
+	}
+	return os.NewFile(uintptr(fd), "/dev/net/tun"), nil
+}
+
+// configureTun sets the UE IP on the device and routes the traffic sourced from it to the device
+// in its own routing table
+func configureTun(name, ueIP string, table int) error {
+	cmds := [][]string{
+		{"addr", "add", ueIP + "/32", "dev", name},
+		{"link", "set", name, "up"},
+		{"route", "replace", "default", "dev", name, "table", fmt.Sprint(table)},
+		{"rule", "add", "from", ueIP, "table", fmt.Sprint(table)},
+	}
+	for _, args := range cmds {
+		if err := runIp(args...); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// unconfigureTun removes the routing rule and table of a device, the device itself goes away
+// when its file is closed
+func unconfigureTun(ueIP string, table int) error {
+	err := runIp("rule", "del", "from", ueIP, "table", fmt.Sprint(table))
+	if ferr := runIp("route", "flush", "table", fmt.Sprint(table)); err == nil {
+		err = ferr
+	}
+	return err
+}
+
+func runIp(args ...string) error {
+	out, err := exec.Command("ip", args...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("ip %s: %v: %s", strings.Join(args, " "), err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
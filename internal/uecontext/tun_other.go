@@ -0,0 +1,20 @@
+//go:build !linux
+
+package uecontext
+
+import (
+	"fmt"
+	"os"
+)
+
+func openTunDevice(name string) (*os.File, error) {
+	return nil, fmt.Errorf("TUN devices are only supported on Linux")
+}
+
+func configureTun(name, ueIP string, table int) error {
+	return fmt.Errorf("TUN devices are only supported on Linux")
+}
+
+func unconfigureTun(ueIP string, table int) error {
+	return nil
+}
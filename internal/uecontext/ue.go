@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/lvdund/rrc"
 	rrcies "github.com/lvdund/rrc/ies"
@@ -33,14 +34,58 @@ type UeContext struct {
 	secCap *nas.UeSecurityCapability
 	supi   string
 	msin   string
-	suci   nas.MobileIdentity
+	suci   nas.SupiImsi        // SUCI parameters, the scheme output is computed for each SUCI sent
+	hnKey  *sec.HomeNetworkKey // home network public key of the ECIES profile, nil for the null scheme
 	guti   *nas.Guti
+	imei   string   // PEI, with the IMEISV and the MAC address
+	imeisv string
+	mac    [6]byte
 	nasPdu []byte // registration request for resending in security mode complete
 
-	auth   AuthContext          // on-going authentication context
-	secCtx *sec.SecurityContext // current security context
+	auth       AuthContext          // on-going authentication context
+	authErrors AuthErrorStats       // authentication requests not accepted
+	secCtx     *sec.SecurityContext // current security context
 
 	sessions [16]*PduSession
+	drbs     map[int64]*sdapDrb // SDAP config by DRB ID, from RRCReconfiguration
+	traffic  config.TrafficConfig // traffic run on new PDU sessions
+	tun      config.TunConfig     // TUN devices of PDU sessions
+
+	rrcIdle        bool        // RRC connection released by RRCRelease
+	ccch           chan []byte // DL-CCCH messages while establishing an RRC connection
+	reregister     uint8       // type of the registration to run once the RRC connection is released, 0 for none
+	serviceRequest bool        // service request waiting for Service Accept or Service Reject
+
+	registrationType uint8       // type of the last Registration Request
+	timers           nasTimers   // timer values of the last Registration Accept
+	t3512            *time.Timer // periodic registration update, nil when not running
+
+	procTimers             procedureTimers // values of the timers supervising UE-initiated procedures
+	t3510                  *time.Timer     // registration supervision
+	t3511                  *time.Timer     // registration retry after a failed attempt
+	t3502                  *time.Timer     // registration retry after MAX_REGISTRATION_ATTEMPTS
+	t3346                  *time.Timer     // registration retry after Registration Reject for congestion
+	t3517                  *time.Timer     // service request supervision
+	t3521                  *time.Timer     // de-registration supervision
+	registrationAttempts   int             // registration attempt counter
+	deregistrationAttempts int             // Deregistration Requests sent for the current de-registration
+
+	cell    servingCell                   // cell the UE camps on or is connected to
+	cells   map[uint16]uint32             // TAC of the cells the UE can move to, by PCI
+	taiList *nas.TrackingAreaIdentityList // registration area of the last Registration Accept
+
+	allowedNssai    *nas.Nssai // allowed NSSAI of the last Registration Accept or Configuration Update Command
+	configuredNssai *nas.Nssai // configured NSSAI provided by the network
+	stateFile       string     // NAS state kept across runs, empty when not kept
+
+	savedSecCtx  *sec.SecurityContext // security context of the NAS COUNTs in the state file
+	savedUlCount uint32               // UL NAS COUNT in the state file
+
+	fullNetworkName  string // network names of the last Configuration Update Command
+	shortNetworkName string
+	ladns            []ladn // LADN information of the last Configuration Update Command
+
+	events []EventInfo // configured events, nil once started
 
 	// Measurement context for handover
 	measurement *MeasurementContext
@@ -49,15 +94,17 @@ type UeContext struct {
 	ctx   context.Context
 
 	// comm: ue vs du
-	ReceiveFromDuChannel chan []byte
-	SendToDuChannel      chan []byte
-	IsReadyConn          chan bool
+	ReceiveFromDuChannel     chan []byte
+	SendToDuChannel          chan []byte
+	ReceiveDataFromDuChannel chan DrbPdu // DL user plane packets
+	SendDataToDuChannel      chan DrbPdu // UL user plane packets
+	IsReadyConn              chan bool
 }
 
 func CreateUe(
 	conf config.UEConfig,
 	ctx context.Context,
-) *UeContext {
+) (*UeContext, error) {
 	ue := &UeContext{
 		id:     1, // Fixed ID for single UE
 		mcc:    conf.PLMN.MCC,
@@ -67,6 +114,13 @@ func CreateUe(
 		state:  UE_STATE_DEREGISTERED,
 		Logger: logger.InitLogger("", map[string]string{"mod": "ue", "msin": conf.MSIN}),
 		ctx:    ctx,
+		traffic: conf.Traffic,
+		tun:     conf.Tun,
+		ccch:    make(chan []byte, 1),
+		cells:   cellsByPci(conf.Cells),
+		events:  eventsOf(conf.Events),
+
+		procTimers: newProcedureTimers(conf.NasTimers),
 	}
 
 	// init AuthContext
@@ -88,13 +142,22 @@ func CreateUe(
 	ue.auth.supi = fmt.Sprintf("imsi-%s%s%s", conf.PLMN.MCC, conf.PLMN.MNC, conf.MSIN)
 	ue.supi = ue.auth.supi
 
-	// create SUCI (simplified - using fixed values)
-	ue.createConcealSuci(conf.PLMN.MCC, conf.PLMN.MNC, conf)
+	// SUCI parameters from the configuration
+	if err := ue.createConcealSuci(conf.PLMN.MCC, conf.PLMN.MNC, conf); err != nil {
+		return nil, err
+	}
+	ue.imei = conf.GetIMEI()
+	ue.imeisv = conf.GetIMEISV()
+	copy(ue.mac[:], conf.GetMAC())
+
+	// 5G-GUTI and security context of an earlier run
+	ue.stateFile = stateFilePath(conf.StateDir, ue.supi)
+	ue.loadState()
 
 	// Initialize measurement context
 	ue.initMeasurement()
 
-	return ue
+	return ue, nil
 }
 
 
@@ -145,6 +208,18 @@ func (ue *UeContext) ResetSecurityContext() {
 	ue.auth.ngKsi.Id = 7
 }
 
+// deleteIdentity deletes the 5G-GUTI, the NAS security context and the registration area, the next
+// registration uses the SUCI
+func (ue *UeContext) deleteIdentity() {
+	ue.Info("Deleting 5G-GUTI and NAS security context")
+	ue.ResetSecurityContext()
+	ue.mutex.Lock()
+	ue.guti = nil
+	ue.taiList = nil
+	ue.mutex.Unlock()
+	ue.SaveState()
+}
+
 // return current nas security context for encoding/decoding nas message
 func (ue *UeContext) getNasContext() *nas.NasContext {
 	if ue.secCtx != nil {
@@ -153,13 +228,42 @@ func (ue *UeContext) getNasContext() *nas.NasContext {
 	return nil
 }
 
-func (ue *UeContext) createConcealSuci(mcc, mnc string, ueConf config.UEConfig) {
-	// Simplified SUCI creation with fixed values
-	// For simplicity, use null scheme (no encryption)
-	suci := new(nas.SupiImsi)
-	// Fixed values: routing indicator = "0000", protection scheme = 0 (null), key ID = 0
-	suci.Parse([]string{mcc, mnc, "0000", "0", "0", ue.msin})
-	ue.suci = nas.MobileIdentity{Id: &nas.Suci{Content: suci}}
+// createConcealSuci sets the SUCI parameters of the UE: routing indicator, protection scheme and
+// home network public key. A home network key that is not usable is an error, the UE never sends
+// its SUPI in clear when the configuration asks for a concealed one.
+func (ue *UeContext) createConcealSuci(mcc, mnc string, ueConf config.UEConfig) error {
+	ue.suci.Parse([]string{mcc, mnc, ueConf.GetRoutingIndicator(), "0", "0", ue.msin})
+	if ueConf.ProtectionScheme == int(nas.ProtectionSchemeNullScheme) {
+		return nil
+	}
+	raw, err := hex.DecodeString(ueConf.HomeNetworkPublicKey)
+	if err != nil {
+		return fmt.Errorf("home network public key: %w", err)
+	}
+	hnKey, err := sec.NewHomeNetworkKey(uint8(ueConf.ProtectionScheme), raw)
+	if err != nil {
+		return fmt.Errorf("home network public key: %w", err)
+	}
+	ue.hnKey = hnKey
+	ue.suci.ProtectionScheme = uint8(ueConf.ProtectionScheme)
+	ue.suci.HomeNetworkPublicKeyId = uint8(ueConf.HomeNetworkPublicKeyID)
+	return nil
+}
+
+// concealSuci returns a SUCI of the UE. With an ECIES profile the MSIN is concealed with a fresh
+// ephemeral key each time (TS 33.501 6.12.2).
+func (ue *UeContext) concealSuci() (nas.MobileIdentity, error) {
+	suci := ue.suci
+	if ue.hnKey != nil {
+		msin, err := nas.ParseMsin(ue.msin)
+		if err != nil {
+			return nas.MobileIdentity{}, err
+		}
+		if suci.SchemeOutput, err = ue.hnKey.Conceal(msin); err != nil {
+			return nas.MobileIdentity{}, fmt.Errorf("conceal SUCI: %w", err)
+		}
+	}
+	return nas.MobileIdentity{Id: &nas.Suci{Content: &suci}}, nil
 }
 
 func (ue *UeContext) set5gGuti(guti *nas.MobileIdentity) {
@@ -204,5 +308,32 @@ func (ue *UeContext) Send_UlInformationTransfer_To_Du(nas_message []byte) {
 		return
 	}
 
+	ue.saveNasCounts(nas_message)
 	ue.SendToDuChannel <- encoded
-}
\ No newline at end of file
+}
+
+func (ue *UeContext) SetRegisteredForTest(guti *nas.Guti) {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	ue.state = UE_STATE_REGISTERED
+	ue.guti = guti
+}
+
+func (ue *UeContext) GutiForTest() *nas.Guti {
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	return ue.guti
+}
+
+func (ue *UeContext) SetSecurityContextForTest(ngKsi uint8, kamf []byte, encAlg, intAlg uint8) error {
+	ksi := nas.KeySetIdentifier{Id: ngKsi}
+	secCtx, err := sec.RestoreSecurityContext(&ksi, kamf, encAlg, intAlg, 0, 0)
+	if err != nil {
+		return err
+	}
+	ue.mutex.Lock()
+	defer ue.mutex.Unlock()
+	ue.secCtx = secCtx
+	ue.auth.ngKsi = ksi
+	return nil
+}
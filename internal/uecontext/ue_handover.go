@@ -14,6 +14,9 @@ const (
 	MEASUREMENT_ACTIVE
 )
 
+// NEIGHBOUR_CELL_PCI is the target cell reported in Measurement Report
+const NEIGHBOUR_CELL_PCI = 2
+
 type MeasurementContext struct {
 	state           int
 	servingCellRSRP int32
@@ -65,7 +68,7 @@ func (ue *UeContext) sendMeasurementReport(servingRSRP, targetRSRP, targetRSRQ i
 
 	measId := rrcies.MeasId{Value: 1}
 	servCellId := rrcies.ServCellIndex{Value: 0}
-	physCellId := rrcies.PhysCellId{Value: 2} // Target cell PCI
+	physCellId := rrcies.PhysCellId{Value: NEIGHBOUR_CELL_PCI} // Target cell PCI
 
 	// Create MeasResult for serving cell
 	servingMeasResult := &rrcies.MeasResultNR_measResult{
@@ -166,6 +169,7 @@ func (ue *UeContext) HandleRrcReconfiguration(rrcBytes []byte) error {
 
 	// Check if this is a handover command by examining ReconfigurationWithSync
 	isHandover := false
+	targetPci := uint16(NEIGHBOUR_CELL_PCI)
 	
 	if rrcReconfig.CriticalExtensions.RrcReconfiguration != nil &&
 		rrcReconfig.CriticalExtensions.RrcReconfiguration.SecondaryCellGroup != nil {
@@ -179,6 +183,7 @@ func (ue *UeContext) HandleRrcReconfiguration(rrcBytes []byte) error {
 			if cellGroupConfig.SpCellConfig != nil && 
 				cellGroupConfig.SpCellConfig.ReconfigurationWithSync != nil {
 				isHandover = true
+				targetPci = handoverTargetPci(cellGroupConfig.SpCellConfig.ReconfigurationWithSync)
 				ue.Info("ReconfigurationWithSync detected - this is a handover command")
 			}
 		}
@@ -187,7 +192,7 @@ func (ue *UeContext) HandleRrcReconfiguration(rrcBytes []byte) error {
 	if isHandover {
 		ue.Info("Handover to target cell, new C-RNTI will be assigned")
 		// Simulate Random Access procedure to target cell
-		go ue.performRandomAccess()
+		go ue.performRandomAccess(targetPci)
 	} else {
 		ue.Info("RRC Reconfiguration completed (not a handover)")
 	}
@@ -196,7 +201,7 @@ func (ue *UeContext) HandleRrcReconfiguration(rrcBytes []byte) error {
 }
 
 // performRandomAccess simulates Random Access procedure with target DU
-func (ue *UeContext) performRandomAccess() {
+func (ue *UeContext) performRandomAccess(targetPci uint16) {
 	ue.Info("Performing Random Access to Target Cell")
 
 	// Simulate Msg1 (RACH Preamble) transmission
@@ -214,6 +219,10 @@ func (ue *UeContext) performRandomAccess() {
 	}
 
 	ue.Info("Handover completed successfully")
+	if err := ue.changeCell(targetPci); err != nil {
+		ue.Error("Failed to start mobility registration update: %v", err)
+	}
+	ue.runTrafficAfterHandover()
 }
 
 // sendRrcReconfigurationComplete sends RRC Reconfiguration Complete
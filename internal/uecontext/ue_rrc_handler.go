@@ -85,6 +85,7 @@ func (ue *UeContext) handleRrcReconfigurationMessage(msg *rrcies.RRCReconfigurat
 	}
 
 	rrcReconfig := msg.CriticalExtensions.RrcReconfiguration
+	ue.applyRadioBearerConfig(rrcReconfig.RadioBearerConfig)
 
 	// Check if this is a handover by checking SecondaryCellGroup
 	isHandover := false
@@ -139,7 +140,7 @@ func (ue *UeContext) handleHandoverReconfiguration(cellGroupConfig *rrcies.CellG
 
 	// Perform Random Access to target cell
 	ue.Info("Initiating Random Access to target cell")
-	go ue.performRandomAccess()
+	go ue.performRandomAccess(handoverTargetPci(syncReconfig))
 
 	return nil
 }
@@ -184,13 +185,11 @@ func (ue *UeContext) sendRrcSetupComplete() error {
 	ue.Info("Sending RRC Setup Complete")
 
 	// Trigger Registration Request
-	if err := ue.TriggerInitRegistration(); err != nil {
+	nasPdu, err := ue.TriggerInitRegistration()
+	if err != nil {
 		return fmt.Errorf("failed to create registration request: %w", err)
 	}
 
-	// Get the NAS PDU
-	nasPdu := ue.nasPdu
-
 	// FIX: SelectedPLMN_Identity is int64, not struct
 	// Create RRC Setup Complete message
 	rrcSetupComplete := &rrcies.RRCSetupComplete{
@@ -224,6 +223,7 @@ func (ue *UeContext) sendRrcSetupComplete() error {
 	}
 
 	// Send to DU
+	ue.saveNasCounts(nasPdu)
 	ue.SendToDuChannel <- encoded
 	ue.Info("RRC Setup Complete sent successfully")
 	return nil
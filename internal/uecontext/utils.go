@@ -51,8 +51,12 @@ func cause5GMMToString(cause5GMM uint8) string {
 		return "N1 mode not allowed"
 	case nas.Cause5GMMRestrictedServiceArea:
 		return "Restricted service area"
+	case CAUSE_5GMM_REDIRECTION_TO_EPC_REQUIRED:
+		return "Redirection to EPC required"
 	case nas.Cause5GMMLADNNotAvailable:
 		return "LADN not available"
+	case CAUSE_5GMM_NO_NETWORK_SLICES_AVAILABLE:
+		return "No network slices available"
 	case nas.Cause5GMMMaximumNumberOfPDUSessionsReached:
 		return "Maximum number of PDU sessions reached"
 	case nas.Cause5GMMInsufficientResourcesForSpecificSliceAndDNN:
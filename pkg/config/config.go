@@ -1,7 +1,9 @@
 package config
 
 import (
+	"encoding/hex"
 	"fmt"
+	"net"
 	"os"
 
 	"github.com/reogac/nas"
@@ -14,14 +16,25 @@ type Config struct {
 }
 
 type DUConfig struct {
-	ID        int64      `yaml:"id"`
-	Name      string     `yaml:"name"`
-	CUCPAddr  string     `yaml:"cucp_address"`
-	CUCPPort  int        `yaml:"cucp_port"`
-	LocalAddr string     `yaml:"local_address"`
-	LocalPort int        `yaml:"local_port"`
-	PLMN      PLMNConfig `yaml:"plmn"`
-	Cell      CellConfig `yaml:"cell"`
+	ID            int64             `yaml:"id"`
+	Name          string            `yaml:"name"`
+	Transport     string            `yaml:"transport"` // F1 transport: sctp (default), tcp or loopback
+	CUCPAddr      string            `yaml:"cucp_address"`
+	CUCPAddrs     []string          `yaml:"cucp_addresses"` // additional CU-CP addresses of a multi-homed association
+	CUCPPort      int               `yaml:"cucp_port"`
+	LocalAddr     string            `yaml:"local_address"`
+	LocalAddrs    []string          `yaml:"local_addresses"` // additional local addresses to bind for SCTP multi-homing
+	LocalPort     int               `yaml:"local_port"`      // 0 to let the kernel pick the source port
+	SCTPStreams   int               `yaml:"sctp_streams"`    // SCTP streams requested in each direction, 0 for the default of 2
+	F1apWorkers   int               `yaml:"f1ap_workers"`    // workers handling UE-associated F1AP messages, 0 for the default of 8
+	F1apQueueSize int               `yaml:"f1ap_queue_size"` // messages queued per worker, 0 for the default of 256
+	PLMN          PLMNConfig        `yaml:"plmn"`
+	Cell          CellConfig        `yaml:"cell"`
+	Load          LoadConfig        `yaml:"load"`
+	Trace         TraceConfig       `yaml:"trace"`
+	Positioning   PositioningConfig `yaml:"positioning"`
+	Overload      OverloadConfig    `yaml:"overload"`
+	F1U           F1UConfig         `yaml:"f1u"`
 }
 
 type PLMNConfig struct {
@@ -31,7 +44,84 @@ type PLMNConfig struct {
 
 type CellConfig struct {
 	PCI uint16 `yaml:"pci"`
-	TAC string `yaml:"tac"`
+	TAC string `yaml:"tac"` // 24-bit tracking area code in hex, default "000001"
+}
+
+// TACValue returns the tracking area code of the cell, 1 when not set
+func (c CellConfig) TACValue() (uint32, error) {
+	if c.TAC == "" {
+		return 1, nil
+	}
+	b, err := hex.DecodeString(c.TAC)
+	if err != nil || len(b) != 3 {
+		return 0, fmt.Errorf("tac must be 6 hex digits")
+	}
+	return uint32(b[0])<<16 | uint32(b[1])<<8 | uint32(b[2]), nil
+}
+
+// LoadConfig describes the simulated cell load reported in Resource Status Update
+type LoadConfig struct {
+	BasePRBUsage  int64 `yaml:"base_prb_usage"` // PRB usage (%) of the cell without any UE
+	PRBPerUE      int64 `yaml:"prb_per_ue"`     // PRB usage (%) added by each active UE
+	GBRShare      int64 `yaml:"gbr_share"`      // share (%) of the used PRBs carrying GBR traffic
+	Jitter        int64 `yaml:"jitter"`         // random +/- variation (%) applied to each sample
+	CapacityClass int64 `yaml:"capacity_class"` // cell capacity class value (1..100), 0 to omit
+}
+
+// TraceConfig controls subscriber and cell traffic trace recording in the DU
+type TraceConfig struct {
+	Dir              string `yaml:"dir"`                // directory of the per-trace-ID trace files
+	CellTrafficTrace bool   `yaml:"cell_traffic_trace"` // send Cell Traffic Trace when a trace is activated
+}
+
+// F1UConfig is the GTP-U endpoint of the DU carrying DRB user plane traffic to CU-UP.
+type F1UConfig struct {
+	Address string `yaml:"address"` // local F1-U address sent to CU-UP, defaults to local_address
+	Port    int    `yaml:"port"`    // local GTP-U port, 0 for 2152
+	// NR user plane protocol (TS 38.425)
+	PdcpSnSize   int `yaml:"pdcp_sn_size"`  // PDCP SN length of DL/UL PDUs (12 or 18), 0 when CU-UP sends no PDCP header
+	DddsInterval int `yaml:"ddds_interval"` // ms between DL Data Delivery Status reports, 0 to report only when polled
+	BufferSize   int `yaml:"buffer_size"`   // desired buffer size of a DRB in bytes, 0 for 1 MiB
+}
+
+// PositioningConfig describes the synthetic geometry used for F1AP positioning measurements.
+// Positions are given in a local east/north/up frame (meters) around the origin.
+type PositioningConfig struct {
+	Origin GeoPoint    `yaml:"origin"`
+	TRPs   []TRPConfig `yaml:"trps"`
+	UE     UEPosition  `yaml:"ue"`
+}
+
+// GeoPoint is the WGS 84 position of the origin of the local frame, used for the TRP geographical coordinates
+type GeoPoint struct {
+	Latitude  float64 `yaml:"latitude"`  // degrees, north positive
+	Longitude float64 `yaml:"longitude"` // degrees, east positive
+	Altitude  float64 `yaml:"altitude"`  // m
+}
+
+// TRPConfig is one Transmission-Reception Point of the served cell
+type TRPConfig struct {
+	ID int64   `yaml:"id"` // TRP ID (0..4095)
+	X  float64 `yaml:"x"`  // east (m)
+	Y  float64 `yaml:"y"`  // north (m)
+	Z  float64 `yaml:"z"`  // up (m)
+}
+
+// UEPosition is the initial UE position and its constant velocity
+type UEPosition struct {
+	X  float64 `yaml:"x"`
+	Y  float64 `yaml:"y"`
+	Z  float64 `yaml:"z"`
+	VX float64 `yaml:"vx"` // velocity east (m/s), 0 for a static UE
+	VY float64 `yaml:"vy"` // velocity north (m/s)
+	VZ float64 `yaml:"vz"` // velocity up (m/s)
+}
+
+// OverloadConfig controls the simulated gNB-DU overload
+type OverloadConfig struct {
+	MaxUEs         int   `yaml:"max_ues"`          // overloaded when the RRC connected UEs reach this number (0 or 1, the DU serves one UE), 0 to disable
+	MaxMessageRate int   `yaml:"max_message_rate"` // overloaded when F1AP messages per second reach this rate, 0 to disable
+	WaitTime       int64 `yaml:"wait_time"`        // RRCReject wait time (1..16 s) sent while overloaded
 }
 
 type UEConfig struct {
@@ -43,6 +133,66 @@ type UEConfig struct {
 	OPC  string     `yaml:"opc"` // OPC in hex (optional)
 	AMF  string     `yaml:"amf"` // AMF in hex
 	PLMN PLMNConfig `yaml:"plmn"`
+	// SUCI calculation (TS 33.501 6.12.2), the null scheme leaves the MSIN in clear
+	RoutingIndicator       string `yaml:"routing_indicator"` // 4 digits, "0000" when empty
+	ProtectionScheme       int    `yaml:"protection_scheme"` // 0 null scheme, 1 ECIES Profile A, 2 ECIES Profile B
+	HomeNetworkPublicKey   string `yaml:"hn_public_key"`     // home network public key in hex for Profile A and B
+	HomeNetworkPublicKeyID int    `yaml:"hn_public_key_id"`  // 0..255, identifies the key at the SIDF
+	// equipment identities, generated from the MSIN when empty
+	IMEI   string `yaml:"imei"`   // 15 digits ending with the Luhn check digit
+	IMEISV string `yaml:"imeisv"` // 16 digits, 2 digit software version after TAC and serial number
+	MAC    string `yaml:"mac"`    // MAC address reported in Identity Response
+	// user plane traffic run on each PDU session once established
+	Traffic TrafficConfig `yaml:"traffic"`
+	// TUN device per PDU session for local applications
+	Tun TunConfig `yaml:"tun"`
+	// cells the UE can move to by handover or cell reselection, with the TAC broadcast in their SIB1
+	Cells []CellConfig `yaml:"cells"`
+	// directory of the NAS state file of the UE (5G-GUTI, security context, SQN), empty to start from scratch
+	StateDir string `yaml:"state_dir"`
+	// 5GMM timers supervising the UE-initiated NAS procedures
+	NasTimers NasTimersConfig `yaml:"nas_timers"`
+	// procedures run one after the other once the UE is registered
+	Events []EventConfig `yaml:"events"`
+}
+
+// EventConfig is a UE procedure started after a delay from the previous event, or from the first
+// Registration Accept for the first one
+type EventConfig struct {
+	Type      string `yaml:"type"`       // cell_reselection or deregistration
+	Delay     int    `yaml:"delay"`      // ms to wait before the event
+	PCI       uint16 `yaml:"pci"`        // target cell of a cell reselection, from cells
+	SwitchOff bool   `yaml:"switch_off"` // de-registration for switch off, not answered by the network
+}
+
+// NasTimersConfig replaces the 5GMM timer values of TS 24.501 10.2, e.g. with shorter ones to test
+// retransmissions. Values are in ms, 0 keeps the standard value.
+type NasTimersConfig struct {
+	T3510 int `yaml:"t3510"` // registration supervision, 0 for 15 s
+	T3511 int `yaml:"t3511"` // registration retry after a failed attempt, 0 for 10 s
+	T3502 int `yaml:"t3502"` // registration retry after 5 failed attempts, 0 for the network value or 12 min
+	T3517 int `yaml:"t3517"` // service request supervision, 0 for 15 s
+	T3521 int `yaml:"t3521"` // de-registration supervision, 0 for 15 s
+}
+
+// TunConfig creates a Linux TUN device with the UE IP for each PDU session (needs CAP_NET_ADMIN)
+type TunConfig struct {
+	Enabled   bool   `yaml:"enabled"`
+	Prefix    string `yaml:"prefix"`     // device name prefix, the PDU session ID is appended, default "uetun"
+	TableBase int    `yaml:"table_base"` // routing table of a session is table_base + PDU session ID, default 100
+}
+
+// TrafficConfig is user plane traffic sent from the PDU session IP to check data connectivity
+type TrafficConfig struct {
+	Type          string `yaml:"type"`           // icmp, udp or tcp, empty to disable
+	Dst           string `yaml:"dst"`            // destination IPv4 address in the data network
+	Port          int    `yaml:"port"`           // destination port of udp and tcp
+	Count         int    `yaml:"count"`          // ICMP echo requests or UDP packets, 0 for 10
+	Interval      int    `yaml:"interval"`       // ms between ICMP echo requests, 0 for 1000
+	Size          int    `yaml:"size"`           // ICMP/UDP payload or TCP segment bytes, 0 for 56/1000/1400
+	Rate          int    `yaml:"rate"`           // UDP bitrate in kbit/s, 0 for 1000
+	Bytes         int    `yaml:"bytes"`          // TCP bytes to upload, 0 for 1 MiB
+	AfterHandover bool   `yaml:"after_handover"` // run again on the active sessions after each handover
 }
 
 // GetUESecurityCapability returns UE security capability with all algorithms enabled
@@ -64,6 +214,73 @@ func (ue *UEConfig) GetUESecurityCapability() *nas.UeSecurityCapability {
 	return secCap
 }
 
+// GetRoutingIndicator returns the configured routing indicator, else "0000"
+func (ue *UEConfig) GetRoutingIndicator() string {
+	if ue.RoutingIndicator == "" {
+		return "0000"
+	}
+	return ue.RoutingIndicator
+}
+
+// TAC of the generated IMEI and IMEISV
+const defaultImeiTac = "35209900"
+
+// GetIMEI returns the configured IMEI, else one with the last 6 MSIN digits as serial number
+func (ue *UEConfig) GetIMEI() string {
+	if ue.IMEI != "" {
+		return ue.IMEI
+	}
+	serial := fmt.Sprintf("%06s", ue.MSIN)
+	serial = serial[len(serial)-6:]
+	imei := defaultImeiTac + serial
+	return imei + string('0'+luhnDigit(imei))
+}
+
+// GetIMEISV returns the configured IMEISV, else the TAC and serial number of the IMEI with software version 01
+func (ue *UEConfig) GetIMEISV() string {
+	if ue.IMEISV != "" {
+		return ue.IMEISV
+	}
+	return ue.GetIMEI()[:14] + "01"
+}
+
+// GetMAC returns the configured MAC address, else a locally administered one made from the MSIN
+func (ue *UEConfig) GetMAC() net.HardwareAddr {
+	if mac, err := net.ParseMAC(ue.MAC); err == nil && len(mac) == 6 {
+		return mac
+	}
+	var msin uint64
+	fmt.Sscanf(ue.MSIN, "%d", &msin)
+	return net.HardwareAddr{0x02, 0x00, byte(msin >> 24), byte(msin >> 16), byte(msin >> 8), byte(msin)}
+}
+
+// luhnDigit returns the Luhn check digit of a digit string (TS 23.003 B.2)
+func luhnDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < len(digits); i++ {
+		d := int(digits[len(digits)-1-i] - '0')
+		if i%2 == 0 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+	return byte((10 - sum%10) % 10)
+}
+
+func isDigits(s string, n int) bool {
+	if len(s) != n {
+		return false
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
 func Load(path string) (*Config, error) {
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -90,15 +307,66 @@ func (c *Config) Validate() error {
 	if c.DU.CUCPAddr == "" {
 		return fmt.Errorf("du.cucp_address is required")
 	}
-	if c.DU.CUCPPort == 0 {
+	switch c.DU.Transport {
+	case "", "sctp", "tcp", "loopback":
+	default:
+		return fmt.Errorf("du.transport must be sctp, tcp or loopback")
+	}
+	if c.DU.CUCPPort == 0 && c.DU.Transport != "loopback" {
 		return fmt.Errorf("du.cucp_port is required")
 	}
+	if c.DU.LocalPort < 0 || c.DU.LocalPort > 65535 {
+		return fmt.Errorf("du.local_port must be in range 0..65535")
+	}
+	if c.DU.SCTPStreams < 0 || c.DU.SCTPStreams > 65535 {
+		return fmt.Errorf("du.sctp_streams must be in range 0..65535")
+	}
+	if c.DU.F1apWorkers < 0 || c.DU.F1apQueueSize < 0 {
+		return fmt.Errorf("du.f1ap_workers and du.f1ap_queue_size must not be negative")
+	}
 	if c.DU.PLMN.MCC == "" {
 		return fmt.Errorf("du.plmn.mcc is required")
 	}
 	if c.DU.PLMN.MNC == "" {
 		return fmt.Errorf("du.plmn.mnc is required")
 	}
+	if c.DU.Cell.PCI > 1007 {
+		return fmt.Errorf("du.cell.pci must be in range 0..1007")
+	}
+	if _, err := c.DU.Cell.TACValue(); err != nil {
+		return fmt.Errorf("du.cell.%w", err)
+	}
+	if c.DU.Load.CapacityClass < 0 || c.DU.Load.CapacityClass > 100 {
+		return fmt.Errorf("du.load.capacity_class must be in range 0..100")
+	}
+	trpIds := make(map[int64]bool)
+	if o := c.DU.Positioning.Origin; o.Latitude < -90 || o.Latitude > 90 || o.Longitude < -180 || o.Longitude > 180 {
+		return fmt.Errorf("du.positioning.origin: latitude must be in range -90..90 and longitude in range -180..180")
+	}
+	for _, trp := range c.DU.Positioning.TRPs {
+		if trp.ID < 0 || trp.ID > 4095 {
+			return fmt.Errorf("du.positioning.trps: id %d must be in range 0..4095", trp.ID)
+		}
+		if trpIds[trp.ID] {
+			return fmt.Errorf("du.positioning.trps: duplicate id %d", trp.ID)
+		}
+		trpIds[trp.ID] = true
+	}
+	if c.DU.Overload.MaxUEs < 0 || c.DU.Overload.MaxUEs > 1 {
+		return fmt.Errorf("du.overload.max_ues must be 0 or 1, the DU serves a single UE")
+	}
+	if c.DU.Overload.WaitTime != 0 && (c.DU.Overload.WaitTime < 1 || c.DU.Overload.WaitTime > 16) {
+		return fmt.Errorf("du.overload.wait_time must be in range 1..16")
+	}
+	if c.DU.F1U.Port < 0 || c.DU.F1U.Port > 65535 {
+		return fmt.Errorf("du.f1u.port must be in range 0..65535")
+	}
+	if c.DU.F1U.PdcpSnSize != 0 && c.DU.F1U.PdcpSnSize != 12 && c.DU.F1U.PdcpSnSize != 18 {
+		return fmt.Errorf("du.f1u.pdcp_sn_size must be 0, 12 or 18")
+	}
+	if c.DU.F1U.DddsInterval < 0 || c.DU.F1U.BufferSize < 0 {
+		return fmt.Errorf("du.f1u.ddds_interval and du.f1u.buffer_size must not be negative")
+	}
 	if c.UE.MSIN == "" {
 		return fmt.Errorf("ue.msin is required")
 	}
@@ -108,5 +376,93 @@ func (c *Config) Validate() error {
 	if c.UE.AMF == "" {
 		return fmt.Errorf("ue.amf is required")
 	}
+	if c.UE.RoutingIndicator != "" && !isDigits(c.UE.RoutingIndicator, 4) {
+		return fmt.Errorf("ue.routing_indicator must be 4 digits")
+	}
+	if c.UE.HomeNetworkPublicKeyID < 0 || c.UE.HomeNetworkPublicKeyID > 255 {
+		return fmt.Errorf("ue.hn_public_key_id must be in range 0..255")
+	}
+	switch c.UE.ProtectionScheme {
+	case 0:
+	case 1, 2:
+		key, err := hex.DecodeString(c.UE.HomeNetworkPublicKey)
+		if err != nil {
+			return fmt.Errorf("ue.hn_public_key must be hex")
+		}
+		if c.UE.ProtectionScheme == 1 && len(key) != 32 {
+			return fmt.Errorf("ue.hn_public_key must be 32 octets for Profile A")
+		}
+		if c.UE.ProtectionScheme == 2 && len(key) != 33 && len(key) != 65 {
+			return fmt.Errorf("ue.hn_public_key must be a compressed (33 octets) or uncompressed (65 octets) point for Profile B")
+		}
+	default:
+		return fmt.Errorf("ue.protection_scheme must be 0, 1 or 2")
+	}
+	if imei := c.UE.IMEI; imei != "" && (!isDigits(imei, 15) || imei[14]-'0' != luhnDigit(imei[:14])) {
+		return fmt.Errorf("ue.imei must be 15 digits ending with the Luhn check digit")
+	}
+	if c.UE.IMEISV != "" && !isDigits(c.UE.IMEISV, 16) {
+		return fmt.Errorf("ue.imeisv must be 16 digits")
+	}
+	if c.UE.MAC != "" {
+		if mac, err := net.ParseMAC(c.UE.MAC); err != nil || len(mac) != 6 {
+			return fmt.Errorf("ue.mac must be a 48-bit MAC address")
+		}
+	}
+	cellPcis := make(map[uint16]bool)
+	for _, cell := range c.UE.Cells {
+		if cell.PCI > 1007 {
+			return fmt.Errorf("ue.cells: pci %d must be in range 0..1007", cell.PCI)
+		}
+		if cellPcis[cell.PCI] {
+			return fmt.Errorf("ue.cells: duplicate pci %d", cell.PCI)
+		}
+		cellPcis[cell.PCI] = true
+		if _, err := cell.TACValue(); err != nil {
+			return fmt.Errorf("ue.cells: pci %d: %w", cell.PCI, err)
+		}
+	}
+	if len(c.UE.Tun.Prefix) > 13 {
+		return fmt.Errorf("ue.tun.prefix must not be longer than 13 characters")
+	}
+	// tables table_base+1..table_base+15 must not hit the reserved default, main and local tables
+	if c.UE.Tun.TableBase < 0 || (c.UE.Tun.TableBase+15 >= 253 && c.UE.Tun.TableBase <= 255) {
+		return fmt.Errorf("ue.tun.table_base must be non-negative and keep tables clear of 253..255")
+	}
+	if t := c.UE.Traffic; t.Type != "" {
+		if t.Type != "icmp" && t.Type != "udp" && t.Type != "tcp" {
+			return fmt.Errorf("ue.traffic.type must be icmp, udp or tcp")
+		}
+		if ip := net.ParseIP(t.Dst); ip == nil || ip.To4() == nil {
+			return fmt.Errorf("ue.traffic.dst must be an IPv4 address")
+		}
+		if t.Type != "icmp" && (t.Port < 1 || t.Port > 65535) {
+			return fmt.Errorf("ue.traffic.port must be in range 1..65535")
+		}
+		if t.Count < 0 || t.Interval < 0 || t.Size < 0 || t.Rate < 0 || t.Bytes < 0 {
+			return fmt.Errorf("ue.traffic count, interval, size, rate and bytes must not be negative")
+		}
+	}
+	if t := c.UE.NasTimers; t.T3510 < 0 || t.T3511 < 0 || t.T3502 < 0 || t.T3517 < 0 || t.T3521 < 0 {
+		return fmt.Errorf("ue.nas_timers must not be negative")
+	}
+	for _, event := range c.UE.Events {
+		switch event.Type {
+		case "cell_reselection":
+			if !cellPcis[event.PCI] {
+				return fmt.Errorf("ue.events: cell_reselection pci %d is not in ue.cells", event.PCI)
+			}
+		case "deregistration":
+		default:
+			return fmt.Errorf("ue.events: type must be cell_reselection or deregistration")
+		}
+		if event.Delay < 0 {
+			return fmt.Errorf("ue.events: delay must not be negative")
+		}
+	}
 	return nil
 }
+
+func LuhnDigitForTest(digits string) byte {
+	return luhnDigit(digits)
+}
@@ -0,0 +1,52 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"du_ue/pkg/config"
+)
+
+// Test 1: Luhn check digit of the IMEI
+func TestLuhnDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{"49015420323751", 8},
+		{"35209900000001", 4},
+		{"7992739871", 3},
+		{"00000000000000", 0},
+		{"1", 8},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, config.LuhnDigitForTest(tt.digits), tt.digits)
+	}
+}
+
+// Test 2: generated and configured IMEI and IMEISV
+func TestGetIMEI(t *testing.T) {
+	ue := config.UEConfig{MSIN: "0000000001"}
+	assert.Equal(t, "352099000000014", ue.GetIMEI())
+	assert.Equal(t, "3520990000000101", ue.GetIMEISV())
+
+	ue.IMEI = "490154203237518"
+	assert.Equal(t, "490154203237518", ue.GetIMEI())
+	assert.Equal(t, "4901542032375101", ue.GetIMEISV())
+
+	ue.IMEISV = "4901542032375199"
+	assert.Equal(t, "4901542032375199", ue.GetIMEISV())
+}
+
+// Test 3: generated and configured MAC address
+func TestGetMAC(t *testing.T) {
+	ue := config.UEConfig{MSIN: "0000000258"}
+	assert.Equal(t, "02:00:00:00:01:02", ue.GetMAC().String())
+
+	ue.MAC = "00:11:22:33:44:55"
+	assert.Equal(t, "00:11:22:33:44:55", ue.GetMAC().String())
+
+	ue.MAC = "invalid"
+	assert.Equal(t, "02:00:00:00:01:02", ue.GetMAC().String())
+}
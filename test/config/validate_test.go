@@ -0,0 +1,127 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"du_ue/pkg/config"
+)
+
+// validConfig returns a minimal configuration that passes validation
+func validConfig() *config.Config {
+	return &config.Config{
+		DU: config.DUConfig{
+			ID:       1,
+			Name:     "TestDU",
+			CUCPAddr: "127.0.0.1",
+			CUCPPort: 38472,
+			PLMN:     config.PLMNConfig{MCC: "999", MNC: "70"},
+			Cell:     config.CellConfig{PCI: 1},
+		},
+		UE: config.UEConfig{
+			MSIN: "0000000001",
+			Key:  "465B5CE8B199B49FAA5F0A2EE238A6BC",
+			OPC:  "E8ED289DEBA952E4283B54E88E6183CA",
+			AMF:  "8000",
+			PLMN: config.PLMNConfig{MCC: "999", MNC: "70"},
+		},
+	}
+}
+
+// Test 1: Overload configuration
+func TestValidateOverload(t *testing.T) {
+	testCases := []struct {
+		name     string
+		overload config.OverloadConfig
+		valid    bool
+	}{
+		{"disabled", config.OverloadConfig{}, true},
+		{"single UE", config.OverloadConfig{MaxUEs: 1, WaitTime: 16}, true},
+		{"message rate", config.OverloadConfig{MaxMessageRate: 100, WaitTime: 1}, true},
+		{"negative max_ues", config.OverloadConfig{MaxUEs: -1}, false},
+		{"max_ues above the single UE", config.OverloadConfig{MaxUEs: 2}, false},
+		{"wait_time too long", config.OverloadConfig{MaxUEs: 1, WaitTime: 17}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.DU.Overload = tc.overload
+			if tc.valid {
+				assert.NoError(t, cfg.Validate())
+			} else {
+				assert.Error(t, cfg.Validate())
+			}
+		})
+	}
+}
+
+// Test 2: F1 transport and SCTP addresses
+func TestValidateTransport(t *testing.T) {
+	testCases := []struct {
+		name   string
+		modify func(*config.DUConfig)
+		valid  bool
+	}{
+		{"default SCTP", func(c *config.DUConfig) {}, true},
+		{"multi-homed SCTP", func(c *config.DUConfig) {
+			c.CUCPAddrs = []string{"10.0.0.2"}
+			c.LocalAddr = "10.0.0.10"
+			c.LocalAddrs = []string{"10.0.1.10"}
+			c.LocalPort = 38472
+		}, true},
+		{"TCP", func(c *config.DUConfig) { c.Transport = "tcp" }, true},
+		{"loopback without port", func(c *config.DUConfig) {
+			c.Transport = "loopback"
+			c.CUCPPort = 0
+		}, true},
+		{"unknown transport", func(c *config.DUConfig) { c.Transport = "udp" }, false},
+		{"SCTP without port", func(c *config.DUConfig) { c.CUCPPort = 0 }, false},
+		{"missing CU-CP address", func(c *config.DUConfig) { c.CUCPAddr = "" }, false},
+		{"negative local port", func(c *config.DUConfig) { c.LocalPort = -1 }, false},
+		{"local port too large", func(c *config.DUConfig) { c.LocalPort = 65536 }, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			tc.modify(&cfg.DU)
+			if tc.valid {
+				assert.NoError(t, cfg.Validate())
+			} else {
+				assert.Error(t, cfg.Validate())
+			}
+		})
+	}
+}
+
+// Test 3: TUN device names and routing tables
+func TestValidateTun(t *testing.T) {
+	testCases := []struct {
+		name  string
+		tun   config.TunConfig
+		valid bool
+	}{
+		{"defaults", config.TunConfig{Enabled: true}, true},
+		{"13 character prefix", config.TunConfig{Prefix: "abcdefghijklm"}, true},
+		{"prefix too long", config.TunConfig{Prefix: "abcdefghijklmn"}, false},
+		{"tables below the reserved ones", config.TunConfig{TableBase: 237}, true},
+		{"tables above the reserved ones", config.TunConfig{TableBase: 256}, true},
+		{"last table reserved", config.TunConfig{TableBase: 238}, false},
+		{"first table reserved", config.TunConfig{TableBase: 252}, false},
+		{"negative table base", config.TunConfig{TableBase: -1}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.UE.Tun = tc.tun
+			if tc.valid {
+				assert.NoError(t, cfg.Validate())
+			} else {
+				assert.Error(t, cfg.Validate())
+			}
+		})
+	}
+}
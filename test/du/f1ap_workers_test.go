@@ -0,0 +1,195 @@
+package test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+// f1apPdu wraps a message received from CU-CP, the workers only look at the message
+func f1apPdu(msg f1ap.MessageUnmarshaller) *f1ap.F1apPdu {
+	return &f1ap.F1apPdu{Message: f1ap.F1apMessage{Msg: msg}}
+}
+
+func dlRrcMessageTransfer(cuUeId, duUeId int64, seq byte) *f1ap.F1apPdu {
+	return f1apPdu(&ies.DLRRCMessageTransfer{
+		GNBCUUEF1APID: cuUeId,
+		GNBDUUEF1APID: duUeId,
+		SRBID:         1,
+		RRCContainer:  []byte{seq},
+	})
+}
+
+// handledByWorker waits until the workers handled the given number of messages and returns
+// the number handled by each worker
+func handledByWorker(t *testing.T, pool interface{ StatsForTest() []du.F1apQueueStats }, total int64) map[string]int64 {
+	t.Helper()
+	handled := make(map[string]int64)
+	require.Eventually(t, func() bool {
+		clear(handled)
+		var sum int64
+		for _, s := range pool.StatsForTest() {
+			handled[s.Worker] = s.Handled
+			sum += s.Handled
+		}
+		return sum == total
+	}, 3*time.Second, 10*time.Millisecond)
+	return handled
+}
+
+// Test 1: The messages of a UE are handled in arrival order on the worker of its gNB-DU UE F1AP ID
+func TestF1apWorkerOrder(t *testing.T) {
+	var mu sync.Mutex
+	received := make(map[int64][]byte)
+	pool := du.NewF1apWorkerPoolForTest(4, 16, func(data []byte, pdu *f1ap.F1apPdu) {
+		msg := pdu.Message.Msg.(*ies.DLRRCMessageTransfer)
+		// slow down the first UE, the others must not wait for it
+		if msg.GNBDUUEF1APID == 1 {
+			time.Sleep(time.Millisecond)
+		}
+		mu.Lock()
+		received[msg.GNBDUUEF1APID] = append(received[msg.GNBDUUEF1APID], msg.RRCContainer[0])
+		mu.Unlock()
+	})
+	t.Cleanup(pool.StopForTest)
+
+	for seq := 0; seq < 50; seq++ {
+		for duUeId := int64(1); duUeId <= 3; duUeId++ {
+			pool.DispatchForTest(dlRrcMessageTransfer(100+duUeId, duUeId, byte(seq)))
+		}
+	}
+
+	handled := handledByWorker(t, pool, 150)
+	assert.Equal(t, map[string]int64{"non-ue": 0, "ue-0": 0, "ue-1": 50, "ue-2": 50, "ue-3": 50}, handled)
+
+	mu.Lock()
+	defer mu.Unlock()
+	for duUeId := int64(1); duUeId <= 3; duUeId++ {
+		require.Len(t, received[duUeId], 50)
+		for seq, got := range received[duUeId] {
+			assert.Equal(t, byte(seq), got, fmt.Sprintf("gNB-DU UE F1AP ID %d", duUeId))
+		}
+	}
+}
+
+// Test 2: Messages without the gNB-DU UE F1AP ID follow the one learnt for the gNB-CU UE F1AP ID
+func TestF1apWorkerSharding(t *testing.T) {
+	pool := du.NewF1apWorkerPoolForTest(4, 16, func([]byte, *f1ap.F1apPdu) {})
+	t.Cleanup(pool.StopForTest)
+	newUe := func(cuUeId int64) *f1ap.F1apPdu {
+		return f1apPdu(&ies.UEContextSetupRequest{GNBCUUEF1APID: cuUeId})
+	}
+	defaultWorker := fmt.Sprintf("ue-%d", du.DU_UE_F1AP_ID%4)
+
+	// unknown gNB-CU UE F1AP ID: the single UE context of the DU
+	pool.DispatchForTest(newUe(10))
+	assert.Equal(t, int64(1), handledByWorker(t, pool, 1)[defaultWorker])
+
+	// learnt from a message with both IDs
+	pool.DispatchForTest(dlRrcMessageTransfer(10, 6, 0))
+	pool.DispatchForTest(newUe(10))
+	assert.Equal(t, int64(2), handledByWorker(t, pool, 3)["ue-2"])
+
+	// forgotten with the UE context release
+	pool.DispatchForTest(f1apPdu(&ies.UEContextReleaseCommand{GNBCUUEF1APID: 10, GNBDUUEF1APID: 6}))
+	pool.DispatchForTest(newUe(10))
+	handled := handledByWorker(t, pool, 5)
+	assert.Equal(t, int64(3), handled["ue-2"])
+	assert.Equal(t, int64(2), handled[defaultWorker])
+
+	// forgotten with F1 Reset, which is handled by the non UE worker
+	pool.DispatchForTest(dlRrcMessageTransfer(10, 7, 0))
+	pool.DispatchForTest(f1apPdu(&ies.Reset{TransactionID: 1}))
+	pool.DispatchForTest(newUe(10))
+	handled = handledByWorker(t, pool, 8)
+	assert.Equal(t, int64(1), handled["non-ue"])
+	assert.Equal(t, int64(1), handled["ue-3"])
+	assert.Equal(t, int64(3), handled[defaultWorker])
+}
+
+// Test 3: A full queue holds back the dispatch instead of dropping messages
+func TestF1apWorkerQueueFull(t *testing.T) {
+	release := make(chan struct{})
+	pool := du.NewF1apWorkerPoolForTest(1, 2, func([]byte, *f1ap.F1apPdu) { <-release })
+	t.Cleanup(pool.StopForTest)
+
+	dispatched := make(chan struct{})
+	go func() {
+		for seq := 0; seq < 5; seq++ {
+			pool.DispatchForTest(dlRrcMessageTransfer(1, 1, byte(seq)))
+		}
+		close(dispatched)
+	}()
+
+	select {
+	case <-dispatched:
+		t.Fatalf("dispatch did not wait for the full queue")
+	case <-time.After(100 * time.Millisecond):
+	}
+	stats := pool.StatsForTest()
+	assert.Equal(t, 2, stats[1].Depth)
+	assert.Equal(t, int64(2), stats[1].MaxDepth)
+
+	close(release)
+	<-dispatched
+	assert.Equal(t, int64(5), handledByWorker(t, pool, 5)["ue-0"])
+}
+
+// Test 4: The DU reports the queues of the configured workers of its F1AP client
+func TestF1apQueueStats(t *testing.T) {
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{F1apWorkers: 2, F1apQueueSize: 8})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	cu.send(f1SetupResponse())
+
+	require.Eventually(t, func() bool {
+		stats := duInstance.F1apQueueStats()
+		return len(stats) == 3 && stats[0].Handled == 1
+	}, time.Second, 10*time.Millisecond)
+	var workers []string
+	for _, s := range duInstance.F1apQueueStats() {
+		workers = append(workers, s.Worker)
+	}
+	assert.Equal(t, []string{"non-ue", "ue-0", "ue-1"}, workers)
+
+	// the mock F1 client has no queues
+	mockDU, _ := createTestDU(t, config.DUConfig{})
+	assert.Nil(t, mockDU.F1apQueueStats())
+}
+
+// Test 5: Stopping the pool ends its workers and releases a dispatch waiting on a full queue
+func TestF1apWorkerStop(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	pool := du.NewF1apWorkerPoolForTest(1, 1, func([]byte, *f1ap.F1apPdu) { <-release })
+
+	dispatched := make(chan struct{})
+	go func() {
+		for seq := 0; seq < 3; seq++ {
+			pool.DispatchForTest(dlRrcMessageTransfer(1, 1, byte(seq)))
+		}
+		close(dispatched)
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	pool.StopForTest()
+	select {
+	case <-dispatched:
+	case <-time.After(time.Second):
+		t.Fatalf("dispatch still waiting after the pool stopped")
+	}
+
+	// the dispatches after the stop are dropped
+	pool.DispatchForTest(dlRrcMessageTransfer(1, 1, 3))
+	assert.LessOrEqual(t, pool.StatsForTest()[1].Depth, 1)
+}
@@ -0,0 +1,263 @@
+package test
+
+import (
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+
+	"du_ue/internal/du"
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+// Test 1: GTP-U messages with and without the optional header fields
+func TestGtpuRoundTrip(t *testing.T) {
+	seq := uint16(0x1234)
+	testCases := []struct {
+		name    string
+		packet  du.GtpuPacket
+		encoded []byte
+	}{
+		{
+			name:    "G-PDU",
+			packet:  du.GtpuPacket{MsgType: du.GTPU_G_PDU, TEID: 0x01020304, Payload: []byte{0xaa, 0xbb}},
+			encoded: []byte{0x30, 0xff, 0x00, 0x02, 0x01, 0x02, 0x03, 0x04, 0xaa, 0xbb},
+		},
+		{
+			name:   "sequence number",
+			packet: du.GtpuPacket{MsgType: du.GTPU_ECHO_REQUEST, SeqNum: &seq},
+			encoded: []byte{0x32, 0x01, 0x00, 0x04, 0x00, 0x00, 0x00, 0x00,
+				0x12, 0x34, 0x00, 0x00},
+		},
+		{
+			name: "extension headers",
+			packet: du.GtpuPacket{
+				MsgType: du.GTPU_G_PDU,
+				TEID:    1,
+				Extensions: []du.GtpuExtensionHeader{
+					{Type: 0x85, Content: []byte{0x01, 0x02}},
+					{Type: 0x84, Content: []byte{0x03, 0x04, 0x05, 0x06, 0x07, 0x08}},
+				},
+				Payload: []byte{0xcc},
+			},
+			encoded: []byte{0x34, 0xff, 0x00, 0x11, 0x00, 0x00, 0x00, 0x01,
+				0x00, 0x00, 0x00, 0x85,
+				0x01, 0x01, 0x02, 0x84,
+				0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, 0x00,
+				0xcc},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := du.EncodeGtpu(&tc.packet)
+			require.NoError(t, err)
+			assert.Equal(t, tc.encoded, b)
+
+			p, err := du.DecodeGtpu(b)
+			require.NoError(t, err)
+			assert.Equal(t, tc.packet.MsgType, p.MsgType)
+			assert.Equal(t, tc.packet.TEID, p.TEID)
+			assert.Equal(t, tc.packet.SeqNum, p.SeqNum)
+			assert.Equal(t, tc.packet.Extensions, p.Extensions)
+			assert.Equal(t, len(tc.packet.Payload), len(p.Payload))
+			if len(tc.packet.Payload) > 0 {
+				assert.Equal(t, tc.packet.Payload, p.Payload)
+			}
+		})
+	}
+}
+
+// Test 2: Invalid GTP-U messages
+func TestGtpuErrors(t *testing.T) {
+	_, err := du.EncodeGtpu(&du.GtpuPacket{
+		MsgType:    du.GTPU_G_PDU,
+		Extensions: []du.GtpuExtensionHeader{{Type: 0x85, Content: []byte{0x01}}},
+	})
+	assert.Error(t, err, "extension header length not a multiple of 4")
+
+	_, err = du.EncodeGtpu(&du.GtpuPacket{MsgType: du.GTPU_G_PDU, Payload: make([]byte, 0x10000)})
+	assert.Error(t, err, "message too long")
+
+	for name, b := range map[string][]byte{
+		"too short":                  {0x30, 0xff, 0x00, 0x00},
+		"GTP version 2":              {0x48, 0xff, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00},
+		"length exceeds message":     {0x30, 0xff, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01, 0xaa},
+		"optional header truncated":  {0x32, 0xff, 0x00, 0x02, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01},
+		"extension header truncated": {0x34, 0xff, 0x00, 0x06, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x85, 0x02, 0x00},
+		"zero extension length":      {0x34, 0xff, 0x00, 0x08, 0x00, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0x85, 0x00, 0x00, 0x00, 0x00},
+	} {
+		_, err := du.DecodeGtpu(b)
+		assert.Error(t, err, name)
+	}
+}
+
+// testCuUp is the F1-U endpoint of CU-UP, on the GTP-U port as the DU always sends to it
+type testCuUp struct {
+	t    *testing.T
+	conn *net.UDPConn
+}
+
+func newTestCuUp(t *testing.T, ip string) *testCuUp {
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.ParseIP(ip), Port: du.GTPU_PORT})
+	if err != nil {
+		t.Skipf("GTP-U port not available: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testCuUp{t: t, conn: conn}
+}
+
+func (cu *testCuUp) send(to string, p *du.GtpuPacket) {
+	cu.t.Helper()
+	b, err := du.EncodeGtpu(p)
+	require.NoError(cu.t, err)
+	_, err = cu.conn.WriteToUDP(b, &net.UDPAddr{IP: net.ParseIP(to), Port: du.GTPU_PORT})
+	require.NoError(cu.t, err)
+}
+
+func (cu *testCuUp) receive() *du.GtpuPacket {
+	cu.t.Helper()
+	buf := make([]byte, 2048)
+	cu.conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, err := cu.conn.Read(buf)
+	require.NoError(cu.t, err)
+	p, err := du.DecodeGtpu(buf[:n])
+	require.NoError(cu.t, err)
+	return p
+}
+
+// drbToBeSetup requests a DRB with its UL tunnel at the given CU-UP address and TEID
+func drbToBeSetup(drbId int64, cuUpIp string, ulTeid uint32) ies.DRBsToBeSetupItem {
+	teid := make([]byte, 4)
+	binary.BigEndian.PutUint32(teid, ulTeid)
+	return ies.DRBsToBeSetupItem{
+		DRBID: drbId,
+		ULUPTNLInformationToBeSetupList: []ies.ULUPTNLInformationToBeSetupItem{{
+			ULUPTNLInformation: ies.UPTransportLayerInformation{
+				Choice: ies.UPTransportLayerInformationPresentGTPTunnel,
+				GTPTunnel: &ies.GTPTunnel{
+					TransportLayerAddress: aper.BitString{Bytes: net.ParseIP(cuUpIp).To4(), NumBits: 32},
+					GTPTEID:               teid,
+				},
+			},
+		}},
+	}
+}
+
+func ueContextSetupWithDrbs(drbs ...ies.DRBsToBeSetupItem) *f1ap.F1apPdu {
+	pdu := ueContextSetupRequest(100, 1)
+	pdu.Message.Msg.(*ies.UEContextSetupRequest).DRBsToBeSetupList = drbs
+	return pdu
+}
+
+// setupF1uDU creates a DU with F1-U on 127.0.0.1 and a UE with user plane channels
+func setupF1uDU(t *testing.T, cfg config.F1UConfig) (*du.DU, *MockF1Client, *du.UeChannel) {
+	cfg.Address = "127.0.0.1"
+	duInstance, client := createTestDU(t, config.DUConfig{F1U: cfg})
+	ue := &du.UeChannel{
+		ReceiveDataFromUeChannel: make(chan uecontext.DrbPdu, 10),
+		SendDataToUeChannel:      make(chan uecontext.DrbPdu, 10),
+	}
+	duInstance.SetUEChannelForTest(ue)
+	return duInstance, client, ue
+}
+
+// Test 3: A DRB of UE Context Setup carries DL and UL packets between CU-UP and the UE
+func TestF1uDataTransfer(t *testing.T) {
+	cuUp := newTestCuUp(t, "127.0.0.2")
+	duInstance, client, ue := setupF1uDU(t, config.F1UConfig{})
+
+	require.NoError(t, duInstance.HandleUeContextSetupRequest(ueContextSetupWithDrbs(drbToBeSetup(1, "127.0.0.2", 0xabcd))))
+	pdu := waitForMessage(t, client, ies.ProcedureCode_UEContextSetup)
+	response := pdu.Message.Msg.(*ies.UEContextSetupResponse)
+	require.Len(t, response.DRBsSetupList, 1)
+	drb := response.DRBsSetupList[0]
+	assert.Equal(t, int64(1), drb.DRBID)
+	require.NotNil(t, drb.LCID)
+	assert.Equal(t, int64(4), *drb.LCID)
+	require.Len(t, drb.DLUPTNLInformationToBeSetupList, 1)
+	dlTeid := binary.BigEndian.Uint32(drb.DLUPTNLInformationToBeSetupList[0].DLUPTNLInformation.GTPTunnel.GTPTEID)
+	assert.Equal(t, uint32(du.F1U_FIRST_TEID), dlTeid)
+	assert.Empty(t, response.DRBsFailedToBeSetupList)
+
+	// DL
+	cuUp.send("127.0.0.1", &du.GtpuPacket{MsgType: du.GTPU_G_PDU, TEID: dlTeid, Payload: []byte{0x45, 1, 2, 3}})
+	select {
+	case dl := <-ue.SendDataToUeChannel:
+		assert.Equal(t, uecontext.DrbPdu{DrbId: 1, Data: []byte{0x45, 1, 2, 3}}, dl)
+	case <-time.After(3 * time.Second):
+		t.Fatalf("DL packet not delivered to the UE")
+	}
+
+	// UL
+	go duInstance.HandleDataFromUE()
+	ue.ReceiveDataFromUeChannel <- uecontext.DrbPdu{DrbId: 1, Data: []byte{0x45, 4, 5, 6}}
+	ul := cuUp.receive()
+	assert.Equal(t, uint8(du.GTPU_G_PDU), ul.MsgType)
+	assert.Equal(t, uint32(0xabcd), ul.TEID)
+	assert.Equal(t, []byte{0x45, 4, 5, 6}, ul.Payload)
+}
+
+// Test 4: Echo Request is answered, a G-PDU on an unknown TEID gets an Error Indication
+func TestF1uPathManagement(t *testing.T) {
+	cuUp := newTestCuUp(t, "127.0.0.2")
+	duInstance, client, _ := setupF1uDU(t, config.F1UConfig{})
+	require.NoError(t, duInstance.HandleUeContextSetupRequest(ueContextSetupWithDrbs(drbToBeSetup(1, "127.0.0.2", 1))))
+	waitForMessage(t, client, ies.ProcedureCode_UEContextSetup)
+
+	seq := uint16(7)
+	cuUp.send("127.0.0.1", &du.GtpuPacket{MsgType: du.GTPU_ECHO_REQUEST, SeqNum: &seq})
+	echo := cuUp.receive()
+	assert.Equal(t, uint8(du.GTPU_ECHO_RESPONSE), echo.MsgType)
+	require.NotNil(t, echo.SeqNum)
+	assert.Equal(t, seq, *echo.SeqNum)
+	assert.Equal(t, []byte{du.GTPU_IE_RECOVERY, 0}, echo.Payload)
+
+	cuUp.send("127.0.0.1", &du.GtpuPacket{MsgType: du.GTPU_G_PDU, TEID: 0x9999, Payload: []byte{1}})
+	ind := cuUp.receive()
+	assert.Equal(t, uint8(du.GTPU_ERROR_INDICATION), ind.MsgType)
+	assert.Equal(t, []byte{du.GTPU_IE_TEID_DATA_I, 0, 0, 0x99, 0x99, du.GTPU_IE_GSN_ADDRESS, 0, 4, 127, 0, 0, 1}, ind.Payload)
+}
+
+// Test 5: DRBs that cannot be set up are listed with their cause
+func TestF1uDrbSetupFailure(t *testing.T) {
+	t.Run("no UE", func(t *testing.T) {
+		duInstance, client := createTestDU(t, config.DUConfig{F1U: config.F1UConfig{Address: "127.0.0.1"}})
+		require.NoError(t, duInstance.HandleUeContextSetupRequest(ueContextSetupWithDrbs(drbToBeSetup(1, "127.0.0.2", 1))))
+		response := waitForMessage(t, client, ies.ProcedureCode_UEContextSetup).Message.Msg.(*ies.UEContextSetupResponse)
+		assert.Empty(t, response.DRBsSetupList)
+		require.Len(t, response.DRBsFailedToBeSetupList, 1)
+		require.NotNil(t, response.DRBsFailedToBeSetupList[0].Cause.RadioNetwork)
+		assert.Equal(t, ies.CauseRadioNetworkUnknownorinconsistentpairofuef1Apid, response.DRBsFailedToBeSetupList[0].Cause.RadioNetwork.Value)
+	})
+
+	t.Run("no F1-U address", func(t *testing.T) {
+		duInstance, client := createTestDU(t, config.DUConfig{})
+		duInstance.SetUEChannelForTest(&du.UeChannel{})
+		require.NoError(t, duInstance.HandleUeContextSetupRequest(ueContextSetupWithDrbs(drbToBeSetup(1, "127.0.0.2", 1))))
+		response := waitForMessage(t, client, ies.ProcedureCode_UEContextSetup).Message.Msg.(*ies.UEContextSetupResponse)
+		require.Len(t, response.DRBsFailedToBeSetupList, 1)
+		require.NotNil(t, response.DRBsFailedToBeSetupList[0].Cause.Transport)
+		assert.Equal(t, ies.CauseTransportTransportresourceunavailable, response.DRBsFailedToBeSetupList[0].Cause.Transport.Value)
+	})
+
+	t.Run("invalid UL tunnel", func(t *testing.T) {
+		duInstance, client, _ := setupF1uDU(t, config.F1UConfig{})
+		invalid := drbToBeSetup(2, "127.0.0.2", 1)
+		invalid.ULUPTNLInformationToBeSetupList[0].ULUPTNLInformation.GTPTunnel.GTPTEID = []byte{1, 2}
+		require.NoError(t, duInstance.HandleUeContextSetupRequest(ueContextSetupWithDrbs(drbToBeSetup(1, "127.0.0.2", 1), invalid)))
+		response := waitForMessage(t, client, ies.ProcedureCode_UEContextSetup).Message.Msg.(*ies.UEContextSetupResponse)
+		require.Len(t, response.DRBsSetupList, 1)
+		assert.Equal(t, int64(1), response.DRBsSetupList[0].DRBID)
+		require.Len(t, response.DRBsFailedToBeSetupList, 1)
+		assert.Equal(t, int64(2), response.DRBsFailedToBeSetupList[0].DRBID)
+	})
+}
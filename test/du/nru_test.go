@@ -0,0 +1,188 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JocelynWS/f1-gen/ies"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+// Test 1: DL USER DATA frames
+func TestNruDlUserData(t *testing.T) {
+	tests := []struct {
+		name  string
+		frame du.NruDlUserData
+		wire  []byte
+	}{
+		{
+			name:  "polling",
+			frame: du.NruDlUserData{ReportPolling: true, NrUSN: 0x010203},
+			wire:  []byte{0x01, 0x00, 0x01, 0x02, 0x03, 0x00},
+		},
+		{
+			name:  "flush with discard SN",
+			frame: du.NruDlUserData{DlFlush: true, NrUSN: 0x010203, DlDiscardPdcpSn: 0x0a0b0c},
+			wire:  []byte{0x02, 0x00, 0x01, 0x02, 0x03, 0x0a, 0x0b, 0x0c, 0x00, 0x00},
+		},
+		{
+			name: "all flags",
+			frame: du.NruDlUserData{ReportPolling: true, RetransmissionFlag: true, AssistanceInfoPolling: true,
+				UserDataExistence: true, ReportDelivered: true, RequestOutOfSeqReport: true, NrUSN: du.NRU_SN_MODULUS - 1},
+			wire: []byte{0x01, 0x1f, 0xff, 0xff, 0xff, 0x00},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wire := du.EncodeNruDlUserData(&tt.frame)
+			assert.Equal(t, tt.wire, wire)
+			assert.Zero(t, (len(wire)+2)%4, "frame must fill 4 octet units of the extension header")
+
+			frame, err := du.DecodeNruDlUserData(wire)
+			require.NoError(t, err)
+			assert.Equal(t, tt.frame, *frame)
+		})
+	}
+}
+
+// Test 2: Invalid DL USER DATA frames
+func TestDecodeNruDlUserDataErrors(t *testing.T) {
+	for name, wire := range map[string][]byte{
+		"too short":     {0x00, 0x00, 0x01},
+		"wrong type":    {0x10, 0x00, 0x00, 0x00, 0x01, 0x00},
+		"flush missing": {0x02, 0x00, 0x00, 0x00, 0x01, 0x00},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := du.DecodeNruDlUserData(wire)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// Test 3: DL DATA DELIVERY STATUS frames
+func TestNruDdds(t *testing.T) {
+	delivered, transmitted, cause := uint32(0x000102), uint32(0x000105), uint8(1)
+	tests := []struct {
+		name  string
+		frame du.NruDlDataDeliveryStatus
+		wire  []byte
+	}{
+		{
+			name:  "buffer size only",
+			frame: du.NruDlDataDeliveryStatus{FinalFrame: true, DesiredBufferSize: 1 << 20},
+			wire:  []byte{0x12, 0x00, 0x00, 0x10, 0x00, 0x00},
+		},
+		{
+			name: "lost ranges, SNs and cause",
+			frame: du.NruDlDataDeliveryStatus{
+				DesiredBufferSize:        0x1000,
+				LostNrUSnRanges:          []du.NruSnRange{{Start: 5, End: 7}},
+				HighestDeliveredPdcpSn:   &delivered,
+				HighestTransmittedPdcpSn: &transmitted,
+				Cause:                    &cause,
+			},
+			wire: []byte{
+				0x1d, 0x01, 0x00, 0x00, 0x10, 0x00,
+				0x01, 0x00, 0x00, 0x05, 0x00, 0x00, 0x07,
+				0x00, 0x01, 0x02,
+				0x00, 0x01, 0x05,
+				0x01,
+				0x00, 0x00,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wire, err := du.EncodeNruDdds(&tt.frame)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wire, wire)
+			assert.Zero(t, (len(wire)+2)%4, "frame must fill 4 octet units of the extension header")
+
+			frame, err := du.DecodeNruDdds(wire)
+			require.NoError(t, err)
+			assert.Equal(t, tt.frame, *frame)
+		})
+	}
+}
+
+// Test 4: The desired data rate of DL DATA DELIVERY STATUS is skipped
+func TestDecodeNruDddsSkipsDesiredDataRate(t *testing.T) {
+	wire := []byte{0x14, 0x08, 0x00, 0x00, 0x10, 0x00, 0xaa, 0xbb, 0xcc, 0xdd, 0x00, 0x00, 0x09, 0x00}
+	frame, err := du.DecodeNruDdds(wire)
+	require.NoError(t, err)
+	require.NotNil(t, frame.HighestDeliveredPdcpSn)
+	assert.Equal(t, uint32(9), *frame.HighestDeliveredPdcpSn)
+	assert.Equal(t, uint32(0x1000), frame.DesiredBufferSize)
+}
+
+// Test 5: Invalid DL DATA DELIVERY STATUS frames
+func TestNruDddsErrors(t *testing.T) {
+	_, err := du.EncodeNruDdds(&du.NruDlDataDeliveryStatus{LostNrUSnRanges: make([]du.NruSnRange, 0x100)})
+	assert.Error(t, err)
+
+	for name, wire := range map[string][]byte{
+		"too short":        {0x10, 0x00, 0x00},
+		"wrong type":       {0x00, 0x00, 0x00, 0x00, 0x10, 0x00},
+		"range truncated":  {0x11, 0x00, 0x00, 0x00, 0x10, 0x00, 0x01, 0x00, 0x00, 0x05},
+		"cause truncated":  {0x10, 0x01, 0x00, 0x00, 0x10, 0x00},
+		"SN truncated":     {0x14, 0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x01},
+		"data rate missed": {0x10, 0x08, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := du.DecodeNruDdds(wire)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// nruGpdu is a DL G-PDU with a DL USER DATA frame and a PDCP Data PDU of 12 bit SN
+func nruGpdu(teid uint32, frame du.NruDlUserData, pdcpSn uint16, data []byte) *du.GtpuPacket {
+	return &du.GtpuPacket{
+		MsgType:    du.GTPU_G_PDU,
+		TEID:       teid,
+		Extensions: []du.GtpuExtensionHeader{{Type: du.GTPU_EXT_NR_RAN_CONTAINER, Content: du.EncodeNruDlUserData(&frame)}},
+		Payload:    append([]byte{0x80 | byte(pdcpSn>>8), byte(pdcpSn)}, data...),
+	}
+}
+
+// Test 6: Polled DL Data Delivery Status reports the lost NR-U SNs and the highest transmitted PDCP SN
+func TestNruPolledDdds(t *testing.T) {
+	cuUp := newTestCuUp(t, "127.0.0.2")
+	duInstance, client, ue := setupF1uDU(t, config.F1UConfig{PdcpSnSize: 12, BufferSize: 4096})
+	require.NoError(t, duInstance.HandleUeContextSetupRequest(ueContextSetupWithDrbs(drbToBeSetup(1, "127.0.0.2", 0x10))))
+	waitForMessage(t, client, ies.ProcedureCode_UEContextSetup)
+	dlTeid := uint32(du.F1U_FIRST_TEID)
+
+	cuUp.send("127.0.0.1", nruGpdu(dlTeid, du.NruDlUserData{NrUSN: 0}, 1, []byte{0xaa}))
+	// NR-U SN 1 and 2 lost
+	cuUp.send("127.0.0.1", nruGpdu(dlTeid, du.NruDlUserData{NrUSN: 3, ReportPolling: true}, 4, []byte{0xbb}))
+
+	// the UE gets the packets without PDCP header
+	for _, want := range []byte{0xaa, 0xbb} {
+		select {
+		case pdu := <-ue.SendDataToUeChannel:
+			assert.Equal(t, []byte{want}, pdu.Data)
+		case <-time.After(3 * time.Second):
+			t.Fatalf("DL packet not delivered to the UE")
+		}
+	}
+
+	pkt := cuUp.receive()
+	assert.Equal(t, uint8(du.GTPU_G_PDU), pkt.MsgType)
+	assert.Equal(t, uint32(0x10), pkt.TEID)
+	assert.Empty(t, pkt.Payload)
+	require.Len(t, pkt.Extensions, 1)
+	assert.Equal(t, uint8(du.GTPU_EXT_NR_RAN_CONTAINER), pkt.Extensions[0].Type)
+	ddds, err := du.DecodeNruDdds(pkt.Extensions[0].Content)
+	require.NoError(t, err)
+	assert.False(t, ddds.FinalFrame)
+	assert.Equal(t, []du.NruSnRange{{Start: 1, End: 2}}, ddds.LostNrUSnRanges)
+	require.NotNil(t, ddds.HighestTransmittedPdcpSn)
+	assert.Equal(t, uint32(4), *ddds.HighestTransmittedPdcpSn)
+	assert.LessOrEqual(t, ddds.DesiredBufferSize, uint32(4096))
+}
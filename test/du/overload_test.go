@@ -0,0 +1,94 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/JocelynWS/f1-gen/ies"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+// expectStatusIndication checks the overload information of the next gNB-DU Status Indication
+func expectStatusIndication(t *testing.T, client *MockF1Client, overloaded bool) {
+	t.Helper()
+	pdu := waitForMessage(t, client, ies.ProcedureCode_GNBDUStatusIndication)
+	msg := pdu.Message.Msg.(*ies.GNBDUStatusIndication)
+	want := ies.GNBDUOverloadInformationNotOverloaded
+	if overloaded {
+		want = ies.GNBDUOverloadInformationOverloaded
+	}
+	assert.Equal(t, want, msg.GNBDUOverloadInformation.Value)
+}
+
+// Test 1: max_ues is reached by the RRC connected UE only
+func TestOverloadMaxUes(t *testing.T) {
+	duInstance, client := createTestDU(t, config.DUConfig{
+		Overload: config.OverloadConfig{MaxUEs: 1},
+	})
+
+	// a UE still waiting for RRCSetup does not overload the DU
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	duInstance.EvaluateOverloadForTest()
+	assert.False(t, duInstance.IsOverloaded())
+	assertNoMessage(t, client, 50*time.Millisecond)
+
+	duInstance.SetRrcConnectedForTest(true)
+	duInstance.EvaluateOverloadForTest()
+	assert.True(t, duInstance.IsOverloaded())
+	expectStatusIndication(t, client, true)
+
+	// no new indication while the state is unchanged
+	duInstance.EvaluateOverloadForTest()
+	assertNoMessage(t, client, 50*time.Millisecond)
+
+	duInstance.SetRrcConnectedForTest(false)
+	duInstance.EvaluateOverloadForTest()
+	assert.False(t, duInstance.IsOverloaded())
+	expectStatusIndication(t, client, false)
+}
+
+// Test 2: Overload triggers disabled
+func TestOverloadDisabled(t *testing.T) {
+	duInstance, client := createTestDU(t, config.DUConfig{})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	duInstance.SetRrcConnectedForTest(true)
+
+	duInstance.EvaluateOverloadForTest()
+	assert.False(t, duInstance.IsOverloaded())
+	assertNoMessage(t, client, 50*time.Millisecond)
+}
+
+// Test 3: max_message_rate counts the F1AP messages sent and received in the interval
+func TestOverloadMessageRate(t *testing.T) {
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{
+		Overload: config.OverloadConfig{MaxMessageRate: 10},
+	})
+	duInstance.StopOverloadMonitor()
+
+	// F1 Setup Request, then 5 Resource Status Request and Failure
+	for i := 0; i < 5; i++ {
+		cu.send(&ies.ResourceStatusRequest{
+			TransactionID:       int64(i),
+			GNBCUMeasurementID:  1,
+			RegistrationRequest: ies.RegistrationRequest{Value: ies.RegistrationRequestStop},
+		})
+		cu.expect(ies.ProcedureCode_ResourceStatusReportingInitiation)
+	}
+	duInstance.EvaluateOverloadForTest()
+	assert.True(t, duInstance.IsOverloaded())
+	pdu := cu.expect(ies.ProcedureCode_GNBDUStatusIndication)
+	assert.Equal(t, ies.GNBDUOverloadInformationOverloaded,
+		pdu.Message.Msg.(*ies.GNBDUStatusIndication).GNBDUOverloadInformation.Value)
+
+	// only the gNB-DU Status Indication in the next interval
+	duInstance.EvaluateOverloadForTest()
+	assert.False(t, duInstance.IsOverloaded())
+	pdu = cu.expect(ies.ProcedureCode_GNBDUStatusIndication)
+	assert.Equal(t, ies.GNBDUOverloadInformationNotOverloaded,
+		pdu.Message.Msg.(*ies.GNBDUStatusIndication).GNBDUOverloadInformation.Value)
+}
@@ -0,0 +1,445 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+// positioningConfig places the TRPs so that the UE is 500 m east-north-east of TRP 1 at its height,
+// and 400 m north and 400 m below TRP 2. The origin is in the western hemisphere so the longitude
+// of the TRPs is negative.
+var positioningConfig = config.PositioningConfig{
+	Origin: config.GeoPoint{Latitude: 21.0, Longitude: -105.8, Altitude: 10},
+	TRPs: []config.TRPConfig{
+		{ID: 1, X: 0, Y: 0, Z: 25},
+		{ID: 2, X: 300, Y: 0, Z: 425},
+	},
+	UE: config.UEPosition{X: 300, Y: 400, Z: 25},
+}
+
+// expected measurement results of each TRP: the distance is 500 m for TRP 1 and 565.7 m for TRP 2,
+// the reported timing values are the delays in units of Tc plus the k=0 offset 985024
+var expectedPositioning = map[int64]struct {
+	rtoa, rxTx      int64
+	azimuth, zenith int64
+}{
+	1: {rtoa: 985024 + 3279, rxTx: 985024 + 6558, azimuth: 3231, zenith: 900},
+	2: {rtoa: 985024 + 3710, rxTx: 985024 + 7420, azimuth: 0, zenith: 1350},
+}
+
+// createPositioningDU creates a DU with the positioning geometry and a UE
+func createPositioningDU(t *testing.T) (*du.DU, *MockF1Client) {
+	duInstance, client := createTestDU(t, config.DUConfig{Positioning: positioningConfig})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	return duInstance, client
+}
+
+func initiatingMessage(procedureCode int64, msg f1ap.MessageUnmarshaller) *f1ap.F1apPdu {
+	return &f1ap.F1apPdu{
+		Present: ies.F1apPduInitiatingMessage,
+		Message: f1ap.F1apMessage{
+			ProcedureCode: ies.ProcedureCode{Value: aper.Integer(procedureCode)},
+			Msg:           msg,
+		},
+	}
+}
+
+func positioningMeasurementRequest(lmfMeasId int64, periodic bool, quantities ...aper.Enumerated) *f1ap.F1apPdu {
+	msg := &ies.PositioningMeasurementRequest{
+		TransactionID:             1,
+		LMFMeasurementID:          lmfMeasId,
+		RANMeasurementID:          lmfMeasId + 100,
+		TRPMeasurementRequestList: []ies.TRPMeasurementRequestItem{{TRPID: 1}, {TRPID: 2}},
+		PosReportCharacteristics:  &ies.PosReportCharacteristics{Value: ies.PosReportCharacteristicsOndemand},
+	}
+	if periodic {
+		period := ies.PosMeasurementPeriodicity_ms120
+		msg.PosReportCharacteristics.Value = ies.PosReportCharacteristicsPeriodic
+		msg.PosMeasurementPeriodicity = &period
+	}
+	for _, quantity := range quantities {
+		msg.PosMeasurementQuantities = append(msg.PosMeasurementQuantities, ies.PosMeasurementQuantitiesItem{
+			PosMeasurementType: ies.PosMeasurementType{Value: quantity},
+		})
+	}
+	return initiatingMessage(ies.ProcedureCode_PositioningMeasurementExchange, msg)
+}
+
+// sentData returns the next F1AP message sent by the DU undecoded
+func sentData(t *testing.T, m *MockF1Client) []byte {
+	t.Helper()
+	select {
+	case data := <-m.SentMessagesC:
+		return data
+	case <-time.After(3 * time.Second):
+		t.Fatalf("no F1AP message sent")
+	}
+	return nil
+}
+
+// protocolIes decodes the F1AP PDU header with the f1-gen types and returns the procedure code and
+// the encoded value of each protocol IE, for the IEs f1-gen does not decode: it never reads the
+// zenith angle of UL-AoA and mis-sizes the negative longitude of TRP coordinates
+func protocolIes(t *testing.T, data []byte) (int64, map[int64][]byte) {
+	t.Helper()
+	r := aper.NewReader(bytes.NewReader(data))
+	_, err := r.ReadBool()
+	require.NoError(t, err)
+	_, err = r.ReadChoice(2, false)
+	require.NoError(t, err)
+	procedureCode, err := r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 255}, false)
+	require.NoError(t, err)
+	var criticality ies.Criticality
+	require.NoError(t, criticality.Decode(r))
+	container, err := r.ReadOpenType()
+	require.NoError(t, err)
+
+	values := make(map[int64][]byte)
+	r = aper.NewReader(bytes.NewReader(container))
+	_, err = r.ReadBool()
+	require.NoError(t, err)
+	_, err = aper.ReadSequenceOf(func(r *aper.AperReader) (*ies.F1apMessageIE, error) {
+		var id ies.ProtocolIEID
+		if err := id.Decode(r); err != nil {
+			return nil, err
+		}
+		if err := criticality.Decode(r); err != nil {
+			return nil, err
+		}
+		value, err := r.ReadOpenType()
+		values[int64(id.Value)] = value
+		return &ies.F1apMessageIE{}, err
+	}, r, &aper.Constraint{Lb: 0, Ub: 65535}, false)
+	require.NoError(t, err)
+	return procedureCode, values
+}
+
+// ulAoAResults reads the UL-AoA results of a positioning measurement result list, azimuth and
+// zenith by TRP ID (TS 38.473 9.3.1.168)
+func ulAoAResults(t *testing.T, value []byte) map[int64][2]int64 {
+	t.Helper()
+	results := make(map[int64][2]int64)
+	r := aper.NewReader(bytes.NewReader(value))
+	_, err := aper.ReadSequenceOf(func(r *aper.AperReader) (*ies.PosMeasurementResultListItem, error) {
+		var aoa [2]int64
+		if _, err := r.ReadBits(2); err != nil { // extension bit and iE-Extensions
+			return nil, err
+		}
+		_, err := aper.ReadSequenceOf(func(r *aper.AperReader) (*ies.PosMeasurementResultItem, error) {
+			if _, err := r.ReadBits(4); err != nil { // extension bit and optional IEs
+				return nil, err
+			}
+			choice, err := r.ReadChoice(4, false)
+			if err != nil {
+				return nil, err
+			}
+			if choice != ies.MeasuredResultsValuePresentULAngleOfArrival {
+				return nil, assert.AnError
+			}
+			optionals, err := r.ReadBits(4) // extension bit, zenithAoA, angleCoordinateSystem, iE-Extensions
+			if err != nil {
+				return nil, err
+			}
+			if aoa[0], err = r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 3599}, false); err != nil {
+				return nil, err
+			}
+			aoa[1] = -1
+			if optionals[0]&0x40 != 0 {
+				if aoa[1], err = r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 1799}, false); err != nil {
+					return nil, err
+				}
+			}
+			var timeStamp ies.TimeStamp
+			return &ies.PosMeasurementResultItem{}, timeStamp.Decode(r)
+		}, r, &aper.Constraint{Lb: 1, Ub: 16384}, true)
+		if err != nil {
+			return nil, err
+		}
+		trpId, err := r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 4095}, true)
+		results[trpId] = aoa
+		return &ies.PosMeasurementResultListItem{}, err
+	}, r, &aper.Constraint{Lb: 1, Ub: 64}, true)
+	require.NoError(t, err)
+	return results
+}
+
+// trpCoordinates reads the PCI and the geographical coordinates of each TRP of a TRP information
+// list. The PCI and NR CGI are decoded by f1-gen, the coordinates come last.
+func trpCoordinates(t *testing.T, value []byte) (map[int64]int64, map[int64]ies.AccessPointPosition) {
+	t.Helper()
+	pcis := make(map[int64]int64)
+	positions := make(map[int64]ies.AccessPointPosition)
+	r := aper.NewReader(bytes.NewReader(value))
+	_, err := aper.ReadSequenceOf(func(r *aper.AperReader) (*ies.TRPInformationItem, error) {
+		if _, err := r.ReadBits(4); err != nil { // extension and iE-Extensions bits of the item and TRP information
+			return nil, err
+		}
+		trpId, err := r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 4095}, true)
+		if err != nil {
+			return nil, err
+		}
+		n := 0
+		_, err = aper.ReadSequenceOf(func(r *aper.AperReader) (*ies.TRPInformationTypeResponseItem, error) {
+			n++
+			if n < 3 {
+				var item ies.TRPInformationTypeResponseItem
+				if err := item.Decode(r); err != nil {
+					return nil, err
+				}
+				if item.PCINR != nil {
+					pcis[trpId] = *item.PCINR
+				}
+				return &item, nil
+			}
+			pos, err := readGeographicalCoordinates(r)
+			positions[trpId] = pos
+			return &ies.TRPInformationTypeResponseItem{}, err
+		}, r, &aper.Constraint{Lb: 1, Ub: 64}, true)
+		return &ies.TRPInformationItem{}, err
+	}, r, &aper.Constraint{Lb: 1, Ub: 65535}, true)
+	require.NoError(t, err)
+	return pcis, positions
+}
+
+// readGeographicalCoordinates reads a TRP position given directly as an access point position
+func readGeographicalCoordinates(r *aper.AperReader) (pos ies.AccessPointPosition, err error) {
+	choice, err := r.ReadChoice(8, false)
+	if err != nil {
+		return
+	}
+	if choice != ies.TRPInformationTypeResponseItemPresentGeographicalCoordinates {
+		return pos, assert.AnError
+	}
+	// GeographicalCoordinates optionals, TRPPositionDefinitionType direct, TRPPositionDirect
+	// extension and iE-Extensions, accuracy TRP position, AccessPointPosition extension and
+	// iE-Extensions
+	if _, err = r.ReadBits(3); err != nil {
+		return
+	}
+	if _, err = r.ReadChoice(2, false); err != nil {
+		return
+	}
+	if _, err = r.ReadBits(2); err != nil {
+		return
+	}
+	if _, err = r.ReadChoice(2, false); err != nil {
+		return
+	}
+	if _, err = r.ReadBits(2); err != nil {
+		return
+	}
+	if err = pos.LatitudeSign.Decode(r); err != nil {
+		return
+	}
+	if pos.Latitude, err = r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 8388607}, false); err != nil {
+		return
+	}
+	// encoded as the offset to the lower bound
+	if pos.Longitude, err = r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 16777215}, false); err != nil {
+		return
+	}
+	pos.Longitude -= 8388608
+	if err = pos.DirectionOfAltitude.Decode(r); err != nil {
+		return
+	}
+	fields := []struct {
+		value *int64
+		ub    int64
+	}{
+		{&pos.Altitude, 32767},
+		{&pos.UncertaintySemiMajor, 127},
+		{&pos.UncertaintySemiMinor, 127},
+		{&pos.OrientationOfMajorAxis, 179},
+		{&pos.UncertaintyAltitude, 127},
+		{&pos.Confidence, 100},
+	}
+	for _, f := range fields {
+		if *f.value, err = r.ReadInteger(&aper.Constraint{Lb: 0, Ub: f.ub}, false); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// Test 1: Positioning Information Request is answered with the SRS configuration of the served
+// cell and the SFN initialisation time, an unknown UE with Positioning Information Failure
+func TestPositioningInformation(t *testing.T) {
+	duInstance, client := createPositioningDU(t)
+
+	request := &ies.PositioningInformationRequest{GNBCUUEF1APID: 10, GNBDUUEF1APID: du.DU_UE_F1AP_ID}
+	require.NoError(t, duInstance.HandlePositioningInformationRequest(
+		initiatingMessage(ies.ProcedureCode_PositioningInformationExchange, request)))
+
+	pdu := waitForMessage(t, client, ies.ProcedureCode_PositioningInformationExchange)
+	require.Equal(t, uint8(ies.F1apPduSuccessfulOutcome), pdu.Present)
+	response := pdu.Message.Msg.(*ies.PositioningInformationResponse)
+	assert.Equal(t, int64(10), response.GNBCUUEF1APID)
+	require.NotNil(t, response.SRSConfiguration)
+	require.Len(t, response.SRSConfiguration.SRSCarrierList, 1)
+	require.NotNil(t, response.SRSConfiguration.SRSCarrierList[0].Pci)
+	assert.Equal(t, int64(1), response.SRSConfiguration.SRSCarrierList[0].Pci.Value)
+	assert.Equal(t, uint64(64), response.SFNInitialisationTime.NumBits)
+
+	request.GNBDUUEF1APID = du.DU_UE_F1AP_ID + 1
+	require.NoError(t, duInstance.HandlePositioningInformationRequest(
+		initiatingMessage(ies.ProcedureCode_PositioningInformationExchange, request)))
+	pdu = waitForMessage(t, client, ies.ProcedureCode_PositioningInformationExchange)
+	require.Equal(t, uint8(ies.F1apPduUnsuccessfulOutcome), pdu.Present)
+	failure := pdu.Message.Msg.(*ies.PositioningInformationFailure)
+	require.NotNil(t, failure.Cause.RadioNetwork)
+	assert.Equal(t, ies.CauseRadioNetworkUnknownoralreadyallocatedgnbduuef1Apid, failure.Cause.RadioNetwork.Value)
+}
+
+// Test 2: Positioning Activation Request is answered with the SFN and slot of the activation
+func TestPositioningActivation(t *testing.T) {
+	duInstance, client := createPositioningDU(t)
+
+	request := &ies.PositioningActivationRequest{
+		GNBCUUEF1APID: 10,
+		GNBDUUEF1APID: du.DU_UE_F1AP_ID,
+		SRSType:       ies.SRSType{Choice: ies.SRSTypePresentSemipersistentSRS, SemipersistentSRS: &ies.SemipersistentSRS{SRSResourceSetID: 1}},
+	}
+	require.NoError(t, duInstance.HandlePositioningActivationRequest(
+		initiatingMessage(ies.ProcedureCode_PositioningActivation, request)))
+
+	pdu := waitForMessage(t, client, ies.ProcedureCode_PositioningActivation)
+	require.Equal(t, uint8(ies.F1apPduSuccessfulOutcome), pdu.Present)
+	response := pdu.Message.Msg.(*ies.PositioningActivationResponse)
+	assert.Equal(t, int64(10), response.GNBCUUEF1APID)
+	require.NotNil(t, response.SystemFrameNumber)
+	require.NotNil(t, response.SlotNumber)
+	assert.Less(t, *response.SystemFrameNumber, int64(1024))
+	assert.Less(t, *response.SlotNumber, int64(20)) // 30 kHz SCS
+}
+
+// Test 3: gNB Rx-Tx time difference and UL RTOA follow the distance between each TRP and the UE
+func TestPositioningMeasurementTiming(t *testing.T) {
+	duInstance, client := createPositioningDU(t)
+
+	require.NoError(t, duInstance.HandlePositioningMeasurementRequest(positioningMeasurementRequest(1, false,
+		ies.PosMeasurementTypeGnbrxtx, ies.PosMeasurementTypeUlrtoa)))
+
+	pdu := waitForMessage(t, client, ies.ProcedureCode_PositioningMeasurementExchange)
+	require.Equal(t, uint8(ies.F1apPduSuccessfulOutcome), pdu.Present)
+	response := pdu.Message.Msg.(*ies.PositioningMeasurementResponse)
+	assert.Equal(t, int64(1), response.LMFMeasurementID)
+	assert.Equal(t, int64(101), response.RANMeasurementID)
+	require.Len(t, response.PosMeasurementResultList, 2)
+
+	for _, trp := range response.PosMeasurementResultList {
+		want := expectedPositioning[trp.TRPID]
+		require.Len(t, trp.PosMeasurementResult, 2, "TRP %d", trp.TRPID)
+
+		rxTx := trp.PosMeasurementResult[0].MeasuredResultsValue.GNBRxTxTimeDiff
+		require.NotNil(t, rxTx)
+		require.NotNil(t, rxTx.RxTxTimeDiff.K0)
+		assert.Equal(t, want.rxTx, *rxTx.RxTxTimeDiff.K0, "TRP %d", trp.TRPID)
+
+		rtoa := trp.PosMeasurementResult[1].MeasuredResultsValue.ULRTOA
+		require.NotNil(t, rtoa)
+		require.NotNil(t, rtoa.ULRTOAMeasurementItem.K0)
+		assert.Equal(t, want.rtoa, *rtoa.ULRTOAMeasurementItem.K0, "TRP %d", trp.TRPID)
+	}
+}
+
+// Test 4: UL-AoA gives the direction from each TRP to the UE, azimuth counter-clockwise from north
+// and zenith angle from the zenith
+func TestPositioningMeasurementAoA(t *testing.T) {
+	duInstance, client := createPositioningDU(t)
+
+	require.NoError(t, duInstance.HandlePositioningMeasurementRequest(positioningMeasurementRequest(1, false,
+		ies.PosMeasurementTypeUlaoa)))
+
+	procedureCode, values := protocolIes(t, sentData(t, client))
+	require.Equal(t, int64(ies.ProcedureCode_PositioningMeasurementExchange), procedureCode)
+	require.Contains(t, values, int64(ies.ProtocolIEID_PosMeasurementResultList))
+	results := ulAoAResults(t, values[ies.ProtocolIEID_PosMeasurementResultList])
+	require.Len(t, results, 2)
+	for trpId, want := range expectedPositioning {
+		assert.Equal(t, [2]int64{want.azimuth, want.zenith}, results[trpId], "TRP %d", trpId)
+	}
+}
+
+// Test 5: a periodic measurement is reported until Positioning Measurement Abort, a request for
+// TRPs that are not configured fails
+func TestPositioningMeasurementPeriodic(t *testing.T) {
+	duInstance, client := createPositioningDU(t)
+
+	require.NoError(t, duInstance.HandlePositioningMeasurementRequest(positioningMeasurementRequest(2, true,
+		ies.PosMeasurementTypeUlrtoa)))
+	waitForMessage(t, client, ies.ProcedureCode_PositioningMeasurementExchange)
+
+	for i := 0; i < 2; i++ {
+		pdu := waitForMessage(t, client, ies.ProcedureCode_PositioningMeasurementReport)
+		report := pdu.Message.Msg.(*ies.PositioningMeasurementReport)
+		assert.Equal(t, int64(2), report.LMFMeasurementID)
+		require.Len(t, report.PosMeasurementResultList, 2)
+		for _, trp := range report.PosMeasurementResultList {
+			rtoa := trp.PosMeasurementResult[0].MeasuredResultsValue.ULRTOA
+			require.NotNil(t, rtoa)
+			assert.Equal(t, expectedPositioning[trp.TRPID].rtoa, *rtoa.ULRTOAMeasurementItem.K0)
+		}
+	}
+
+	abort := &ies.PositioningMeasurementAbort{TransactionID: 2, LMFMeasurementID: 2, RANMeasurementID: 102}
+	require.NoError(t, duInstance.HandlePositioningMeasurementAbort(
+		initiatingMessage(ies.ProcedureCode_PositioningMeasurementAbort, abort)))
+	// a report may have been under way
+	select {
+	case <-client.SentMessagesC:
+	case <-time.After(200 * time.Millisecond):
+	}
+	assertNoMessage(t, client, 300*time.Millisecond)
+
+	request := positioningMeasurementRequest(3, false, ies.PosMeasurementTypeUlrtoa)
+	request.Message.Msg.(*ies.PositioningMeasurementRequest).TRPMeasurementRequestList = []ies.TRPMeasurementRequestItem{{TRPID: 9}}
+	require.NoError(t, duInstance.HandlePositioningMeasurementRequest(request))
+	pdu := waitForMessage(t, client, ies.ProcedureCode_PositioningMeasurementExchange)
+	require.Equal(t, uint8(ies.F1apPduUnsuccessfulOutcome), pdu.Present)
+}
+
+// Test 6: TRP Information Response gives the PCI and the geographical coordinates of each TRP,
+// projected from the local frame around the origin
+func TestTrpInformation(t *testing.T) {
+	duInstance, client := createPositioningDU(t)
+
+	request := &ies.TRPInformationRequest{TransactionID: 4}
+	require.NoError(t, duInstance.HandleTrpInformationRequest(
+		initiatingMessage(ies.ProcedureCode_TRPInformationExchange, request)))
+
+	procedureCode, values := protocolIes(t, sentData(t, client))
+	require.Equal(t, int64(ies.ProcedureCode_TRPInformationExchange), procedureCode)
+	require.Contains(t, values, int64(ies.ProtocolIEID_TRPInformationListTRPResp))
+	pcis, positions := trpCoordinates(t, values[ies.ProtocolIEID_TRPInformationListTRPResp])
+	assert.Equal(t, map[int64]int64{1: 1, 2: 1}, pcis)
+
+	// 21° N, 105.8° W: latitude 21/90 * 2^23, longitude -105.8/360 * 2^24; TRP 2 is 300 m east
+	wantLongitude := map[int64]int64{1: -4930638, 2: -4930503}
+	wantAltitude := map[int64]int64{1: 35, 2: 435}
+	require.Len(t, positions, 2)
+	for trpId, pos := range positions {
+		assert.Equal(t, ies.LatitudeSignNorth, pos.LatitudeSign.Value, "TRP %d", trpId)
+		assert.Equal(t, int64(1957341), pos.Latitude, "TRP %d", trpId)
+		assert.Equal(t, wantLongitude[trpId], pos.Longitude, "TRP %d", trpId)
+		assert.Equal(t, ies.DirectionOfAltitudeHeight, pos.DirectionOfAltitude.Value, "TRP %d", trpId)
+		assert.Equal(t, wantAltitude[trpId], pos.Altitude, "TRP %d", trpId)
+		assert.Equal(t, int64(68), pos.Confidence, "TRP %d", trpId)
+	}
+
+	request.TRPList = []ies.TRPListItem{{TRPID: 9}}
+	require.NoError(t, duInstance.HandleTrpInformationRequest(
+		initiatingMessage(ies.ProcedureCode_TRPInformationExchange, request)))
+	pdu := waitForMessage(t, client, ies.ProcedureCode_TRPInformationExchange)
+	require.Equal(t, uint8(ies.F1apPduUnsuccessfulOutcome), pdu.Present)
+}
@@ -0,0 +1,189 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+func f1SetupResponse() *ies.F1SetupResponse {
+	return &ies.F1SetupResponse{
+		GNBCURRCVersion: ies.RRCVersion{
+			LatestRRCVersion: aper.BitString{Bytes: []byte{0xe0}, NumBits: 3},
+		},
+	}
+}
+
+// Test 1: The DU reconnects after the connection loss and redoes F1 Setup
+func TestReconnectAfterConnectionLoss(t *testing.T) {
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	assert.Equal(t, du.DU_ACTIVE, duInstance.GetStateForTest())
+
+	lost := time.Now()
+	cu.conn.Close()
+
+	require.Eventually(t, func() bool {
+		return duInstance.GetStateForTest() == du.DU_LOST
+	}, time.Second, 10*time.Millisecond)
+	// the UE context is released with the F1 state
+	assert.Nil(t, duInstance.GetUEChannelForTest())
+
+	cu.accept(3 * time.Second)
+	assert.GreaterOrEqual(t, time.Since(lost), du.RECONNECT_MIN_BACKOFF)
+	cu.expect(ies.ProcedureCode_F1Setup)
+	assert.Equal(t, du.DU_LOST, duInstance.GetStateForTest())
+
+	// a UE channel keeps the DU from starting a UE after F1 Setup
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	cu.send(f1SetupResponse())
+	require.Eventually(t, func() bool {
+		return duInstance.GetStateForTest() == du.DU_ACTIVE
+	}, time.Second, 10*time.Millisecond)
+}
+
+// Test 2: Failed reconnect attempts double the backoff
+func TestReconnectBackoff(t *testing.T) {
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{})
+
+	// CU-CP down: the attempt after 1 s fails, the next one is 2 s later
+	cu.listener.Close()
+	lost := time.Now()
+	cu.conn.Close()
+	time.Sleep(du.RECONNECT_MIN_BACKOFF * 3 / 2)
+
+	cu = newTestCuCp(t)
+	cu.accept(4 * time.Second)
+	elapsed := time.Since(lost)
+	assert.GreaterOrEqual(t, elapsed, du.RECONNECT_MIN_BACKOFF*3)
+	assert.Less(t, elapsed, du.RECONNECT_MIN_BACKOFF*4)
+	cu.expect(ies.ProcedureCode_F1Setup)
+	assert.Equal(t, du.DU_LOST, duInstance.GetStateForTest())
+}
+
+// Test 3: A stopped DU does not reconnect
+func TestNoReconnectAfterStop(t *testing.T) {
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{})
+
+	require.NoError(t, duInstance.Stop())
+	assert.Equal(t, du.DU_INACTIVE, duInstance.GetStateForTest())
+
+	select {
+	case <-cu.conns:
+		t.Errorf("stopped DU reconnected")
+	case <-time.After(du.RECONNECT_MIN_BACKOFF + 500*time.Millisecond):
+	}
+	assert.Equal(t, du.DU_INACTIVE, duInstance.GetStateForTest())
+}
+
+// Test 4: Stop while reconnecting ends the reconnect attempts
+func TestStopWhileReconnecting(t *testing.T) {
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{})
+
+	cu.conn.Close()
+	require.Eventually(t, func() bool {
+		return duInstance.GetStateForTest() == du.DU_LOST
+	}, time.Second, 10*time.Millisecond)
+	require.NoError(t, duInstance.Stop())
+
+	select {
+	case <-cu.conns:
+		t.Errorf("stopped DU reconnected")
+	case <-time.After(du.RECONNECT_MIN_BACKOFF + 500*time.Millisecond):
+	}
+	assert.Equal(t, du.DU_INACTIVE, duInstance.GetStateForTest())
+}
+
+// Test 5: A CU-CP restart redoes F1 Setup on the same connection
+func TestPeerRestart(t *testing.T) {
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+
+	duInstance.OnF1PeerRestart()
+	assert.Equal(t, du.DU_LOST, duInstance.GetStateForTest())
+	assert.Nil(t, duInstance.GetUEChannelForTest())
+	cu.expect(ies.ProcedureCode_F1Setup)
+
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	cu.send(f1SetupResponse())
+	require.Eventually(t, func() bool {
+		return duInstance.GetStateForTest() == du.DU_ACTIVE
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case <-cu.conns:
+		t.Errorf("DU reconnected after a CU-CP restart")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+// Test 6: A connection lost again before F1 Setup Response is re-established with the doubled backoff
+func TestReconnectLostDuringSetup(t *testing.T) {
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+
+	cu.conn.Close()
+	cu.accept(3 * time.Second)
+	cu.expect(ies.ProcedureCode_F1Setup)
+
+	lost := time.Now()
+	cu.conn.Close()
+	cu.accept(4 * time.Second)
+	assert.GreaterOrEqual(t, time.Since(lost), du.RECONNECT_MIN_BACKOFF*2)
+	cu.expect(ies.ProcedureCode_F1Setup)
+	assert.Equal(t, du.DU_LOST, duInstance.GetStateForTest())
+
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	cu.send(f1SetupResponse())
+	require.Eventually(t, func() bool {
+		return duInstance.GetStateForTest() == du.DU_ACTIVE
+	}, time.Second, 10*time.Millisecond)
+}
+
+// Test 7: F1 Setup Failure is answered by a new F1 Setup Request on the same connection after its
+// TimeToWait
+func TestReconnectSetupFailure(t *testing.T) {
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{})
+
+	cu.conn.Close()
+	cu.accept(3 * time.Second)
+	cu.expect(ies.ProcedureCode_F1Setup)
+
+	rejected := time.Now()
+	cu.send(&ies.F1SetupFailure{
+		Cause:      radioNetworkCause(ies.CauseRadioNetworkUnspecified),
+		TimeToWait: &ies.TimeToWait{Value: ies.TimeToWaitV5S},
+	})
+	// the backoff is 2 s, the TimeToWait is longer
+	time.Sleep(3 * time.Second)
+	cu.expect(ies.ProcedureCode_F1Setup)
+	assert.GreaterOrEqual(t, time.Since(rejected), 5*time.Second)
+	assert.Equal(t, du.DU_LOST, duInstance.GetStateForTest())
+
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	cu.send(f1SetupResponse())
+	require.Eventually(t, func() bool {
+		return duInstance.GetStateForTest() == du.DU_ACTIVE
+	}, time.Second, 10*time.Millisecond)
+
+	select {
+	case <-cu.conns:
+		t.Errorf("DU reconnected after F1 Setup Failure")
+	case <-time.After(100 * time.Millisecond):
+	}
+}
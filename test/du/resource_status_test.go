@@ -0,0 +1,252 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+// MockF1Client captures sent messages for verification
+type MockF1Client struct {
+	SentMessagesC chan []byte
+}
+
+func newMockF1Client() *MockF1Client {
+	return &MockF1Client{SentMessagesC: make(chan []byte, 100)}
+}
+
+func (m *MockF1Client) Connect() error            { return nil }
+func (m *MockF1Client) Close() error              { return nil }
+func (m *MockF1Client) SendF1SetupRequest() error { return nil }
+func (m *MockF1Client) ReadLoop()                 {}
+func (m *MockF1Client) Send(data []byte) error {
+	select {
+	case m.SentMessagesC <- data:
+	default:
+	}
+	return nil
+}
+
+// waitForMessage returns the next sent F1AP message, which must have the given procedure code
+func waitForMessage(t *testing.T, m *MockF1Client, procedureCode int64) f1ap.F1apPdu {
+	t.Helper()
+	select {
+	case data := <-m.SentMessagesC:
+		pdu, err, _ := f1ap.F1apDecode(data)
+		require.NoError(t, err)
+		require.Equal(t, procedureCode, int64(pdu.Message.ProcedureCode.Value))
+		return pdu
+	case <-time.After(3 * time.Second):
+		t.Fatalf("no F1AP message with procedure code %d sent", procedureCode)
+	}
+	return f1ap.F1apPdu{}
+}
+
+// assertNoMessage checks that nothing is sent for the given time
+func assertNoMessage(t *testing.T, m *MockF1Client, d time.Duration) {
+	t.Helper()
+	select {
+	case <-m.SentMessagesC:
+		t.Errorf("unexpected F1AP message sent")
+	case <-time.After(d):
+	}
+}
+
+func createTestDU(t *testing.T, cfg config.DUConfig) (*du.DU, *MockF1Client) {
+	cfg.ID = 1
+	cfg.Name = "TestDU"
+	cfg.PLMN = config.PLMNConfig{MCC: "999", MNC: "70"}
+	cfg.Cell = config.CellConfig{PCI: 1}
+
+	duInstance, err := du.NewDU(&config.Config{DU: cfg})
+	require.NoError(t, err)
+
+	client := newMockF1Client()
+	duInstance.SetF1ClientForTest(client)
+	t.Cleanup(func() { duInstance.Stop() })
+	return duInstance, client
+}
+
+func resourceStatusRequest(cuMeasId int64, registration aper.Enumerated, characteristics []byte) *f1ap.F1apPdu {
+	msg := &ies.ResourceStatusRequest{
+		TransactionID:        1,
+		GNBCUMeasurementID:   cuMeasId,
+		RegistrationRequest:  ies.RegistrationRequest{Value: registration},
+		ReportingPeriodicity: &ies.ReportingPeriodicity{Value: ies.ReportingPeriodicityMs500},
+	}
+	if characteristics != nil {
+		msg.ReportCharacteristics = &aper.BitString{Bytes: characteristics, NumBits: 32}
+	}
+	return &f1ap.F1apPdu{
+		Present: ies.F1apPduInitiatingMessage,
+		Message: f1ap.F1apMessage{
+			ProcedureCode: ies.ProcedureCode{Value: ies.ProcedureCode_ResourceStatusReportingInitiation},
+			Msg:           msg,
+		},
+	}
+}
+
+// Test 1: Load model without jitter
+func TestLoadModelSample(t *testing.T) {
+	model := du.NewLoadModel(config.LoadConfig{
+		BasePRBUsage:  20,
+		PRBPerUE:      10,
+		GBRShare:      50,
+		CapacityClass: 7,
+	})
+
+	load := model.Sample(2)
+	assert.Equal(t, int64(40), load.DLTotalPRB)
+	assert.Equal(t, int64(40), load.ULTotalPRB)
+	assert.Equal(t, int64(20), load.DLGBRPRB)
+	assert.Equal(t, int64(20), load.ULGBRPRB)
+	assert.Equal(t, int64(60), load.DLAvailable)
+	assert.Equal(t, int64(60), load.ULAvailable)
+	assert.Equal(t, int64(2), load.ActiveUEs)
+	assert.Equal(t, int64(7), load.CapacityClass)
+
+	// PRB usage is clamped to 100%
+	load = model.Sample(20)
+	assert.Equal(t, int64(100), load.DLTotalPRB)
+	assert.Equal(t, int64(0), load.DLAvailable)
+}
+
+// Test 2: Load model jitter stays within the configured range
+func TestLoadModelJitter(t *testing.T) {
+	model := du.NewLoadModel(config.LoadConfig{BasePRBUsage: 50, Jitter: 5})
+
+	for i := 0; i < 100; i++ {
+		load := model.Sample(0)
+		assert.GreaterOrEqual(t, load.DLTotalPRB, int64(45))
+		assert.LessOrEqual(t, load.DLTotalPRB, int64(55))
+	}
+}
+
+// Test 3: Start, periodic update with the RRC connected UE and stop
+func TestResourceStatusReporting(t *testing.T) {
+	duInstance, client := createTestDU(t, config.DUConfig{
+		Load: config.LoadConfig{BasePRBUsage: 20, PRBPerUE: 10},
+	})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	duInstance.SetRrcConnectedForTest(true)
+
+	// PRB periodic and number of active UEs
+	characteristics := []byte{0x88, 0x00, 0x00, 0x00}
+	err := duInstance.HandleResourceStatusRequest(resourceStatusRequest(5, ies.RegistrationRequestStart, characteristics))
+	require.NoError(t, err)
+
+	pdu := waitForMessage(t, client, ies.ProcedureCode_ResourceStatusReportingInitiation)
+	require.Equal(t, uint8(ies.F1apPduSuccessfulOutcome), pdu.Present)
+	response := pdu.Message.Msg.(*ies.ResourceStatusResponse)
+	assert.Equal(t, int64(5), response.GNBCUMeasurementID)
+	duMeasId := response.GNBDUMeasurementID
+
+	pdu = waitForMessage(t, client, ies.ProcedureCode_ResourceStatusReporting)
+	update := pdu.Message.Msg.(*ies.ResourceStatusUpdate)
+	assert.Equal(t, int64(5), update.GNBCUMeasurementID)
+	assert.Equal(t, duMeasId, update.GNBDUMeasurementID)
+	assert.Nil(t, update.HardwareLoadIndicator)
+	require.Len(t, update.CellMeasurementResultList, 1)
+	item := update.CellMeasurementResultList[0]
+	require.NotNil(t, item.NumberofActiveUEs)
+	assert.Equal(t, int64(1), *item.NumberofActiveUEs)
+	require.NotNil(t, item.RadioResourceStatus)
+	assert.Equal(t, int64(30), item.RadioResourceStatus.SSBAreaRadioResourceStatusList.SSBAreaDLTotalPRBusage)
+	assert.Nil(t, item.CompositeAvailableCapacityGroup)
+
+	stop := resourceStatusRequest(5, ies.RegistrationRequestStop, nil)
+	stop.Message.Msg.(*ies.ResourceStatusRequest).GNBDUMeasurementID = &duMeasId
+	require.NoError(t, duInstance.HandleResourceStatusRequest(stop))
+
+	// an update may have been queued just before the stop
+	for {
+		pdu, err, _ := f1ap.F1apDecode(<-client.SentMessagesC)
+		require.NoError(t, err)
+		if int64(pdu.Message.ProcedureCode.Value) == ies.ProcedureCode_ResourceStatusReportingInitiation {
+			require.Equal(t, uint8(ies.F1apPduSuccessfulOutcome), pdu.Present)
+			break
+		}
+	}
+	assertNoMessage(t, client, time.Second)
+}
+
+// Test 4: A UE that is not RRC connected is not counted
+func TestResourceStatusIdleUe(t *testing.T) {
+	duInstance, client := createTestDU(t, config.DUConfig{
+		Load: config.LoadConfig{BasePRBUsage: 20, PRBPerUE: 10},
+	})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+
+	err := duInstance.HandleResourceStatusRequest(resourceStatusRequest(1, ies.RegistrationRequestStart, []byte{0x08, 0, 0, 0}))
+	require.NoError(t, err)
+	waitForMessage(t, client, ies.ProcedureCode_ResourceStatusReportingInitiation)
+
+	pdu := waitForMessage(t, client, ies.ProcedureCode_ResourceStatusReporting)
+	update := pdu.Message.Msg.(*ies.ResourceStatusUpdate)
+	require.Len(t, update.CellMeasurementResultList, 1)
+	require.NotNil(t, update.CellMeasurementResultList[0].NumberofActiveUEs)
+	assert.Equal(t, int64(0), *update.CellMeasurementResultList[0].NumberofActiveUEs)
+}
+
+// radioNetworkCause wraps a radio network cause value
+func radioNetworkCause(value aper.Enumerated) ies.Cause {
+	return ies.Cause{Choice: ies.CausePresentRadioNetwork, RadioNetwork: &ies.CauseRadioNetwork{Value: value}}
+}
+
+// Test 5: Resource Status Failure for invalid requests
+func TestResourceStatusFailure(t *testing.T) {
+	testCases := []struct {
+		name  string
+		setup func(*du.DU)
+		pdu   *f1ap.F1apPdu
+		cause ies.Cause
+	}{
+		{
+			name:  "empty report characteristics",
+			pdu:   resourceStatusRequest(1, ies.RegistrationRequestStart, []byte{0, 0, 0, 0}),
+			cause: radioNetworkCause(ies.CauseRadioNetworkReportcharacteristicsempty),
+		},
+		{
+			name: "existing measurement ID",
+			setup: func(d *du.DU) {
+				d.HandleResourceStatusRequest(resourceStatusRequest(1, ies.RegistrationRequestStart, []byte{0x80, 0, 0, 0}))
+			},
+			pdu:   resourceStatusRequest(1, ies.RegistrationRequestStart, []byte{0x80, 0, 0, 0}),
+			cause: radioNetworkCause(ies.CauseRadioNetworkExistingmeasurementid),
+		},
+		{
+			name: "unknown measurement ID",
+			pdu:  resourceStatusRequest(2, ies.RegistrationRequestStop, nil),
+			cause: ies.Cause{
+				Choice:   ies.CausePresentProtocol,
+				Protocol: &ies.CauseProtocol{Value: ies.CauseProtocolMessageNotCompatibleWithReceiverState},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			duInstance, client := createTestDU(t, config.DUConfig{})
+			if tc.setup != nil {
+				tc.setup(duInstance)
+				waitForMessage(t, client, ies.ProcedureCode_ResourceStatusReportingInitiation)
+			}
+
+			require.NoError(t, duInstance.HandleResourceStatusRequest(tc.pdu))
+
+			pdu := waitForMessage(t, client, ies.ProcedureCode_ResourceStatusReportingInitiation)
+			require.Equal(t, uint8(ies.F1apPduUnsuccessfulOutcome), pdu.Present)
+			failure := pdu.Message.Msg.(*ies.ResourceStatusFailure)
+			assert.Equal(t, tc.cause, failure.Cause)
+		})
+	}
+}
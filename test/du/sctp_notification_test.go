@@ -0,0 +1,110 @@
+package test
+
+import (
+	"encoding/binary"
+	"errors"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/ishidawataru/sctp"
+
+	"du_ue/internal/du"
+)
+
+// sctpNotification builds an SCTP notification with the given header type and body
+func sctpNotification(notificationType sctp.SCTPNotificationType, body []byte) []byte {
+	data := make([]byte, 8, 8+len(body))
+	binary.NativeEndian.PutUint16(data, uint16(notificationType))
+	binary.NativeEndian.PutUint32(data[4:], uint32(8+len(body)))
+	return append(data, body...)
+}
+
+// assocChange builds an SCTP_ASSOC_CHANGE notification
+func assocChange(state, ostreams, istreams uint16) []byte {
+	body := make([]byte, 12)
+	binary.NativeEndian.PutUint16(body, state)
+	binary.NativeEndian.PutUint16(body[4:], ostreams)
+	binary.NativeEndian.PutUint16(body[6:], istreams)
+	return sctpNotification(sctp.SCTP_ASSOC_CHANGE, body)
+}
+
+// peerAddrChange builds an SCTP_PEER_ADDR_CHANGE notification for an IPv4 address
+func peerAddrChange(state uint32) []byte {
+	body := make([]byte, 128+12)
+	binary.NativeEndian.PutUint16(body, syscall.AF_INET)
+	binary.BigEndian.PutUint16(body[2:], 38472)
+	copy(body[4:], []byte{127, 0, 0, 2})
+	binary.NativeEndian.PutUint32(body[128:], state)
+	return sctpNotification(sctp.SCTP_PEER_ADDR_CHANGE, body)
+}
+
+// Test 1: Association changes are mapped to the F1 transport errors
+func TestSctpAssocChange(t *testing.T) {
+	testCases := []struct {
+		name  string
+		state uint16
+		err   error
+	}{
+		{"communication up", du.SCTP_COMM_UP, nil},
+		{"communication lost", du.SCTP_COMM_LOST, du.ErrF1ConnectionLost},
+		{"restart", du.SCTP_RESTART, du.ErrF1PeerRestarted},
+		{"shutdown complete", du.SCTP_SHUTDOWN_COMP, du.ErrF1ConnectionLost},
+		{"cannot start association", du.SCTP_CANT_STR_ASSOC, du.ErrF1ConnectionLost},
+		{"unknown state", 42, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			transport := du.NewSctpTransportForTest(4)
+			err := transport.HandleNotificationForTest(assocChange(tc.state, 3, 4))
+			if tc.err == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, tc.err)
+			}
+		})
+	}
+
+	// a restart is not a lost connection
+	err := du.NewSctpTransportForTest(4).HandleNotificationForTest(assocChange(du.SCTP_RESTART, 3, 4))
+	assert.False(t, errors.Is(err, du.ErrF1ConnectionLost))
+}
+
+// Test 2: The outbound streams negotiated with CU-CP are taken from association up and restart
+func TestSctpAssocChangeStreams(t *testing.T) {
+	transport := du.NewSctpTransportForTest(4)
+	assert.Equal(t, uint16(0), transport.Streams())
+
+	transport.HandleNotificationForTest(assocChange(du.SCTP_COMM_UP, 3, 4))
+	assert.Equal(t, uint16(3), transport.Streams())
+
+	transport.HandleNotificationForTest(assocChange(du.SCTP_RESTART, 2, 4))
+	assert.Equal(t, uint16(2), transport.Streams())
+
+	transport.HandleNotificationForTest(assocChange(du.SCTP_COMM_LOST, 0, 0))
+	assert.Equal(t, uint16(2), transport.Streams())
+}
+
+// Test 3: Peer shutdown ends the connection, path changes and other notifications do not
+func TestSctpOtherNotifications(t *testing.T) {
+	transport := du.NewSctpTransportForTest(4)
+
+	assert.ErrorIs(t, transport.HandleNotificationForTest(sctpNotification(sctp.SCTP_SHUTDOWN_EVENT, make([]byte, 4))), du.ErrF1ConnectionLost)
+
+	for _, state := range []uint32{du.SCTP_ADDR_AVAILABLE, du.SCTP_ADDR_UNREACHABLE, du.SCTP_ADDR_POTENTIALLY_FAILED, 99} {
+		assert.NoError(t, transport.HandleNotificationForTest(peerAddrChange(state)))
+	}
+	assert.NoError(t, transport.HandleNotificationForTest(sctpNotification(sctp.SCTP_SEND_FAILED, make([]byte, 16))))
+}
+
+// Test 4: Truncated notifications are ignored
+func TestSctpShortNotification(t *testing.T) {
+	transport := du.NewSctpTransportForTest(4)
+
+	assert.NoError(t, transport.HandleNotificationForTest([]byte{1, 0}))
+	assert.NoError(t, transport.HandleNotificationForTest(assocChange(du.SCTP_COMM_LOST, 0, 0)[:12]))
+	assert.NoError(t, transport.HandleNotificationForTest(peerAddrChange(du.SCTP_ADDR_UNREACHABLE)[:100]))
+	assert.Equal(t, uint16(0), transport.Streams())
+}
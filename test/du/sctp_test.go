@@ -0,0 +1,144 @@
+package test
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/ishidawataru/sctp"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+// listenSctp listens for F1 on the given addresses, the test is skipped without SCTP support
+func listenSctp(t *testing.T, addrs ...string) *sctp.SCTPListener {
+	laddr := &sctp.SCTPAddr{}
+	for _, addr := range addrs {
+		laddr.IPAddrs = append(laddr.IPAddrs, net.IPAddr{IP: net.ParseIP(addr)})
+	}
+	listener, err := sctp.ListenSCTPExt("sctp", laddr, sctp.InitMsg{NumOstreams: 4, MaxInstreams: 4})
+	if err != nil {
+		t.Skipf("SCTP not available: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	return listener
+}
+
+// freeSctpPort returns a local SCTP port that is not in use
+func freeSctpPort(t *testing.T) int {
+	listener := listenSctp(t, "127.0.0.1")
+	port := listener.Addr().(*sctp.SCTPAddr).Port
+	listener.Close()
+	return port
+}
+
+// startSctpDU starts a DU connected to the listener and returns the accepted association
+func startSctpDU(t *testing.T, listener *sctp.SCTPListener, cfg config.DUConfig) (*du.DU, *sctp.SCTPConn) {
+	cfg.ID = 1
+	cfg.Name = "TestDU"
+	cfg.Transport = du.F1_TRANSPORT_SCTP
+	if cfg.CUCPAddr == "" {
+		cfg.CUCPAddr = "127.0.0.1"
+	}
+	cfg.CUCPPort = listener.Addr().(*sctp.SCTPAddr).Port
+	cfg.PLMN = config.PLMNConfig{MCC: "999", MNC: "70"}
+	cfg.Cell = config.CellConfig{PCI: 1}
+
+	duInstance, err := du.NewDU(&config.Config{DU: cfg})
+	require.NoError(t, err)
+	t.Cleanup(func() { duInstance.Stop() })
+
+	connC := make(chan *sctp.SCTPConn, 1)
+	go func() {
+		if conn, err := listener.AcceptSCTP(); err == nil {
+			connC <- conn
+		}
+	}()
+	require.NoError(t, duInstance.Start())
+
+	select {
+	case conn := <-connC:
+		t.Cleanup(func() { conn.Close() })
+		require.NoError(t, conn.SubscribeEvents(sctp.SCTP_EVENT_DATA_IO))
+		return duInstance, conn
+	case <-time.After(3 * time.Second):
+		t.Fatalf("DU did not connect")
+	}
+	return nil, nil
+}
+
+// readSctp returns the next F1AP message of the association and its stream
+func readSctp(t *testing.T, conn *sctp.SCTPConn) (f1ap.F1apPdu, uint16) {
+	t.Helper()
+	buf := make([]byte, 8192)
+	n, info, err := conn.SCTPRead(buf)
+	require.NoError(t, err)
+	require.NotNil(t, info)
+	assert.Equal(t, du.F1AP_PPID, info.PPID)
+	pdu, err, _ := f1ap.F1apDecode(buf[:n])
+	require.NoError(t, err)
+	return pdu, info.Stream
+}
+
+// Test 1: The DU binds the association to the configured local address and port
+func TestSctpLocalBinding(t *testing.T) {
+	port := freeSctpPort(t)
+	listener := listenSctp(t, "127.0.0.1")
+
+	_, conn := startSctpDU(t, listener, config.DUConfig{
+		LocalAddr: "127.0.0.1",
+		LocalPort: port,
+	})
+
+	remote, ok := conn.RemoteAddr().(*sctp.SCTPAddr)
+	require.True(t, ok)
+	assert.Equal(t, port, remote.Port)
+
+	pdu, stream := readSctp(t, conn)
+	assert.Equal(t, int64(ies.ProcedureCode_F1Setup), int64(pdu.Message.ProcedureCode.Value))
+	assert.Equal(t, uint16(0), stream)
+}
+
+// Test 2: A multi-homed association uses all local and CU-CP addresses
+func TestSctpMultiHoming(t *testing.T) {
+	listener := listenSctp(t, "127.0.0.1", "127.0.0.2")
+
+	_, conn := startSctpDU(t, listener, config.DUConfig{
+		CUCPAddr:   "127.0.0.1",
+		CUCPAddrs:  []string{"127.0.0.2"},
+		LocalAddr:  "127.0.0.1",
+		LocalAddrs: []string{"127.0.0.3"},
+	})
+
+	remote, ok := conn.RemoteAddr().(*sctp.SCTPAddr)
+	require.True(t, ok)
+	var ips []string
+	for _, addr := range remote.IPAddrs {
+		ips = append(ips, addr.IP.String())
+	}
+	assert.ElementsMatch(t, []string{"127.0.0.1", "127.0.0.3"}, ips)
+
+	pdu, _ := readSctp(t, conn)
+	assert.Equal(t, int64(ies.ProcedureCode_F1Setup), int64(pdu.Message.ProcedureCode.Value))
+}
+
+// Test 3: Unresolvable local address
+func TestSctpInvalidLocalAddress(t *testing.T) {
+	duInstance, err := du.NewDU(&config.Config{DU: config.DUConfig{
+		ID:        1,
+		Name:      "TestDU",
+		CUCPAddr:  "127.0.0.1",
+		CUCPPort:  38472,
+		LocalAddr: "invalid..address",
+		PLMN:      config.PLMNConfig{MCC: "999", MNC: "70"},
+	}})
+	require.NoError(t, err)
+	assert.Error(t, duInstance.Start())
+	assert.Equal(t, du.DU_INACTIVE, duInstance.GetStateForTest())
+}
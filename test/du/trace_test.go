@@ -0,0 +1,230 @@
+package test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+// testCuCp is an in-process CU-CP peer of a DU using the loopback transport
+type testCuCp struct {
+	t        *testing.T
+	listener *du.LoopbackListener
+	conns    chan net.Conn
+	conn     net.Conn
+	received chan []byte
+}
+
+// newTestCuCp listens for DUs with cucp_address set to the name of the test
+func newTestCuCp(t *testing.T) *testCuCp {
+	listener, err := du.ListenLoopback(t.Name())
+	require.NoError(t, err)
+
+	cu := &testCuCp{
+		t:        t,
+		listener: listener,
+		conns:    make(chan net.Conn, 10),
+		received: make(chan []byte, 100),
+	}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go cu.readLoop(conn)
+			cu.conns <- conn
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return cu
+}
+
+func (cu *testCuCp) readLoop(conn net.Conn) {
+	for {
+		data, err := du.ReadF1Frame(conn)
+		if err != nil {
+			return
+		}
+		cu.received <- data
+	}
+}
+
+// accept waits for the next F1 connection of the DU
+func (cu *testCuCp) accept(timeout time.Duration) net.Conn {
+	cu.t.Helper()
+	select {
+	case cu.conn = <-cu.conns:
+		return cu.conn
+	case <-time.After(timeout):
+		cu.t.Fatalf("DU did not connect within %v", timeout)
+	}
+	return nil
+}
+
+// send sends an F1AP message to the DU on the current connection
+func (cu *testCuCp) send(msg f1ap.F1apMessageEncoder) {
+	cu.t.Helper()
+	data, err := f1ap.F1apEncode(msg)
+	require.NoError(cu.t, err)
+	require.NoError(cu.t, du.WriteF1Frame(cu.conn, data))
+}
+
+// expect returns the next F1AP message sent by the DU, which must have the given procedure code
+func (cu *testCuCp) expect(procedureCode int64) f1ap.F1apPdu {
+	cu.t.Helper()
+	pdu, _ := cu.expectData(procedureCode)
+	return pdu
+}
+
+// expectData is expect that also returns the encoded message
+func (cu *testCuCp) expectData(procedureCode int64) (f1ap.F1apPdu, []byte) {
+	cu.t.Helper()
+	select {
+	case data := <-cu.received:
+		pdu, err, _ := f1ap.F1apDecode(data)
+		require.NoError(cu.t, err)
+		require.Equal(cu.t, procedureCode, int64(pdu.Message.ProcedureCode.Value))
+		return pdu, data
+	case <-time.After(3 * time.Second):
+		cu.t.Fatalf("no F1AP message with procedure code %d received", procedureCode)
+	}
+	return f1ap.F1apPdu{}, nil
+}
+
+// startLoopbackDU starts a DU connected to the CU-CP over the loopback transport, F1 Setup
+// Request is received but not answered
+func startLoopbackDU(t *testing.T, cu *testCuCp, cfg config.DUConfig) *du.DU {
+	cfg.ID = 1
+	cfg.Name = "TestDU"
+	cfg.Transport = du.F1_TRANSPORT_LOOPBACK
+	cfg.CUCPAddr = t.Name()
+	cfg.PLMN = config.PLMNConfig{MCC: "999", MNC: "70"}
+	cfg.Cell = config.CellConfig{PCI: 1}
+
+	duInstance, err := du.NewDU(&config.Config{DU: cfg})
+	require.NoError(t, err)
+	t.Cleanup(func() { duInstance.Stop() })
+
+	require.NoError(t, duInstance.Start())
+	cu.accept(time.Second)
+	cu.expect(ies.ProcedureCode_F1Setup)
+	return duInstance
+}
+
+func traceStart(traceId []byte, depth aper.Enumerated) *ies.TraceStart {
+	return &ies.TraceStart{
+		GNBCUUEF1APID: 10,
+		GNBDUUEF1APID: 1,
+		TraceActivation: ies.TraceActivation{
+			TraceID:                        traceId,
+			InterfacesToTrace:              aper.BitString{Bytes: []byte{0x20}, NumBits: 8},
+			TraceDepth:                     ies.TraceDepth{Value: depth},
+			TraceCollectionEntityIPAddress: aper.BitString{Bytes: []byte{10, 0, 0, 1}, NumBits: 32},
+		},
+	}
+}
+
+// readTrace waits until the trace file has the given number of lines
+func readTrace(t *testing.T, path string, lines int) []string {
+	t.Helper()
+	var content []string
+	require.Eventually(t, func() bool {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		content = strings.Split(strings.TrimSpace(string(data)), "\n")
+		return len(content) >= lines
+	}, 3*time.Second, 10*time.Millisecond)
+	return content
+}
+
+// Test 1: Trace Start records the UE associated F1AP messages until Deactivate Trace
+func TestTraceStartAndDeactivate(t *testing.T) {
+	dir := t.TempDir()
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{
+		Trace: config.TraceConfig{Dir: dir, CellTrafficTrace: true},
+	})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+
+	traceId := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	cu.send(traceStart(traceId, ies.TraceDepthMaximum))
+
+	pdu, data := cu.expectData(ies.ProcedureCode_CellTrafficTrace)
+	trace := pdu.Message.Msg.(*ies.CellTrafficTrace)
+	assert.Equal(t, int64(10), trace.GNBCUUEF1APID)
+	assert.Equal(t, int64(1), trace.GNBDUUEF1APID)
+	assert.Equal(t, traceId, trace.TraceID.Value)
+	// f1-gen does not decode the Trace Collection Entity IP address, the last IE of the message
+	assert.Equal(t, []byte{10, 0, 0, 1}, data[len(data)-4:])
+
+	cu.send(&ies.DeactivateTrace{GNBCUUEF1APID: 10, GNBDUUEF1APID: 1, TraceID: traceId})
+
+	path := filepath.Join(dir, "trace_0102030405060708.log")
+	lines := readTrace(t, path, 3)
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "# trace 0102030405060708 started, CU-UE-ID=10, DU-UE-ID=1")
+	assert.Contains(t, lines[1], " UL F1AP CellTrafficTrace")
+	assert.Contains(t, lines[2], " DL F1AP DeactivateTrace")
+	// maximum depth records the message content
+	assert.Regexp(t, `len=\d+ [0-9a-f]+$`, lines[2])
+
+	// nothing is recorded once the trace is deactivated
+	cu.send(traceStart([]byte{9, 9, 9, 9, 9, 9, 9, 9}, ies.TraceDepthMinimum))
+	cu.expect(ies.ProcedureCode_CellTrafficTrace)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 3)
+}
+
+// Test 2: Minimum trace depth records message names only
+func TestTraceMinimumDepth(t *testing.T) {
+	dir := t.TempDir()
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{
+		Trace: config.TraceConfig{Dir: dir, CellTrafficTrace: true},
+	})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+
+	cu.send(traceStart([]byte{1, 1, 1, 1, 1, 1, 1, 1}, ies.TraceDepthMinimum))
+	cu.expect(ies.ProcedureCode_CellTrafficTrace)
+
+	lines := readTrace(t, filepath.Join(dir, "trace_0101010101010101.log"), 2)
+	assert.Contains(t, lines[1], " UL F1AP CellTrafficTrace")
+	assert.Regexp(t, `len=\d+$`, lines[1])
+}
+
+// Test 3: Deactivate Trace with another Trace ID keeps the trace active
+func TestDeactivateTraceUnknownId(t *testing.T) {
+	dir := t.TempDir()
+	cu := newTestCuCp(t)
+	duInstance := startLoopbackDU(t, cu, config.DUConfig{
+		Trace: config.TraceConfig{Dir: dir},
+	})
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+
+	traceId := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	cu.send(traceStart(traceId, ies.TraceDepthMedium))
+	cu.send(&ies.DeactivateTrace{GNBCUUEF1APID: 10, GNBDUUEF1APID: 1, TraceID: []byte{8, 7, 6, 5, 4, 3, 2, 1}})
+	cu.send(&ies.DeactivateTrace{GNBCUUEF1APID: 10, GNBDUUEF1APID: 1, TraceID: traceId})
+
+	lines := readTrace(t, filepath.Join(dir, "trace_0102030405060708.log"), 3)
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], " DL F1AP DeactivateTrace")
+	assert.Contains(t, lines[2], " DL F1AP DeactivateTrace")
+}
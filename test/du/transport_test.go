@@ -0,0 +1,139 @@
+package test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+// Test 1: Frames are read back in order with their content
+func TestF1FrameRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, du.WriteF1Frame(&buf, []byte{1, 2, 3}))
+	require.NoError(t, du.WriteF1Frame(&buf, []byte{}))
+	require.NoError(t, du.WriteF1Frame(&buf, []byte{4, 5}))
+	assert.Equal(t, []byte{0, 0, 0, 3, 1, 2, 3}, buf.Bytes()[:7])
+
+	for _, want := range [][]byte{{1, 2, 3}, {}, {4, 5}} {
+		data, err := du.ReadF1Frame(&buf)
+		require.NoError(t, err)
+		assert.Equal(t, want, data)
+	}
+	_, err := du.ReadF1Frame(&buf)
+	assert.Equal(t, io.EOF, err)
+}
+
+// Test 2: Oversized and truncated frames
+func TestF1FrameErrors(t *testing.T) {
+	var buf bytes.Buffer
+	assert.Error(t, du.WriteF1Frame(&buf, make([]byte, du.F1_MAX_FRAME_LEN+1)))
+	assert.Zero(t, buf.Len())
+
+	header := make([]byte, du.F1_FRAME_HEADER_LEN)
+	binary.BigEndian.PutUint32(header, du.F1_MAX_FRAME_LEN+1)
+	_, err := du.ReadF1Frame(bytes.NewReader(header))
+	assert.Error(t, err)
+
+	// connection closed within the header or the message
+	_, err = du.ReadF1Frame(bytes.NewReader([]byte{0, 0}))
+	assert.Equal(t, io.EOF, err)
+	_, err = du.ReadF1Frame(bytes.NewReader([]byte{0, 0, 0, 3, 1}))
+	assert.Equal(t, io.EOF, err)
+}
+
+// Test 3: Loopback listener names are unique until the listener is closed
+func TestLoopbackListener(t *testing.T) {
+	listener, err := du.ListenLoopback(t.Name())
+	require.NoError(t, err)
+	_, err = du.ListenLoopback(t.Name())
+	assert.Error(t, err)
+
+	require.NoError(t, listener.Close())
+	_, err = listener.Accept()
+	assert.ErrorIs(t, err, net.ErrClosed)
+
+	listener, err = du.ListenLoopback(t.Name())
+	require.NoError(t, err)
+	listener.Close()
+}
+
+// Test 4: Without a loopback listener the DU fails to start
+func TestLoopbackNoListener(t *testing.T) {
+	duInstance, err := du.NewDU(&config.Config{DU: config.DUConfig{
+		ID:        1,
+		Name:      "TestDU",
+		Transport: du.F1_TRANSPORT_LOOPBACK,
+		CUCPAddr:  t.Name(),
+		PLMN:      config.PLMNConfig{MCC: "999", MNC: "70"},
+	}})
+	require.NoError(t, err)
+	assert.Error(t, duInstance.Start())
+	assert.Equal(t, du.DU_INACTIVE, duInstance.GetStateForTest())
+}
+
+// Test 5: F1 Setup over the TCP transport
+func TestTcpTransport(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+
+	duInstance, err := du.NewDU(&config.Config{DU: config.DUConfig{
+		ID:        1,
+		Name:      "TestDU",
+		Transport: du.F1_TRANSPORT_TCP,
+		CUCPAddr:  "127.0.0.1",
+		CUCPPort:  listener.Addr().(*net.TCPAddr).Port,
+		PLMN:      config.PLMNConfig{MCC: "999", MNC: "70"},
+		Cell:      config.CellConfig{PCI: 1},
+	}})
+	require.NoError(t, err)
+	t.Cleanup(func() { duInstance.Stop() })
+
+	connC := make(chan net.Conn, 1)
+	go func() {
+		if conn, err := listener.Accept(); err == nil {
+			connC <- conn
+		}
+	}()
+	require.NoError(t, duInstance.Start())
+
+	var conn net.Conn
+	select {
+	case conn = <-connC:
+		t.Cleanup(func() { conn.Close() })
+	case <-time.After(3 * time.Second):
+		t.Fatalf("DU did not connect")
+	}
+
+	data, err := du.ReadF1Frame(conn)
+	require.NoError(t, err)
+	pdu, err, _ := f1ap.F1apDecode(data)
+	require.NoError(t, err)
+	assert.Equal(t, int64(ies.ProcedureCode_F1Setup), int64(pdu.Message.ProcedureCode.Value))
+
+	duInstance.SetUEChannelForTest(&du.UeChannel{})
+	data, err = f1ap.F1apEncode(f1SetupResponse())
+	require.NoError(t, err)
+	require.NoError(t, du.WriteF1Frame(conn, data))
+	require.Eventually(t, func() bool {
+		return duInstance.GetStateForTest() == du.DU_ACTIVE
+	}, time.Second, 10*time.Millisecond)
+}
+
+// Test 6: Unknown transport
+func TestUnknownTransport(t *testing.T) {
+	_, err := du.NewF1Transport(&config.DUConfig{Transport: "udp"}, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,105 @@
+package test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/ishidawataru/sctp"
+
+	"du_ue/internal/du"
+	"du_ue/pkg/config"
+)
+
+// MockF1UeClient also captures the gNB-DU UE F1AP ID of UE-associated messages
+type MockF1UeClient struct {
+	*MockF1Client
+	UeIdsC chan int64
+}
+
+func (m *MockF1UeClient) SendUe(data []byte, duUeId int64) error {
+	m.UeIdsC <- duUeId
+	return m.Send(data)
+}
+
+func ueContextSetupRequest(cuUeId, duUeId int64) *f1ap.F1apPdu {
+	return &f1ap.F1apPdu{
+		Present: ies.F1apPduInitiatingMessage,
+		Message: f1ap.F1apMessage{
+			ProcedureCode: ies.ProcedureCode{Value: ies.ProcedureCode_UEContextSetup},
+			Msg: &ies.UEContextSetupRequest{
+				GNBCUUEF1APID: cuUeId,
+				GNBDUUEF1APID: &duUeId,
+			},
+		},
+	}
+}
+
+// Test 1: UE-associated messages carry the gNB-DU UE F1AP ID to the F1 client
+func TestSendUeWithDuUeId(t *testing.T) {
+	duInstance, client := createTestDU(t, config.DUConfig{})
+	ueClient := &MockF1UeClient{MockF1Client: client, UeIdsC: make(chan int64, 10)}
+	duInstance.SetF1ClientForTest(ueClient)
+
+	require.NoError(t, duInstance.HandleUeContextSetupRequest(ueContextSetupRequest(100, 7)))
+	pdu := waitForMessage(t, client, ies.ProcedureCode_UEContextSetup)
+	assert.Equal(t, int64(7), pdu.Message.Msg.(*ies.UEContextSetupResponse).GNBDUUEF1APID)
+	select {
+	case duUeId := <-ueClient.UeIdsC:
+		assert.Equal(t, int64(7), duUeId)
+	default:
+		t.Errorf("UE Context Setup Response not sent as UE-associated signalling")
+	}
+
+	// non UE-associated signalling
+	require.NoError(t, duInstance.HandleResourceStatusRequest(resourceStatusRequest(1, ies.RegistrationRequestStart, []byte{0x80, 0, 0, 0})))
+	waitForMessage(t, client, ies.ProcedureCode_ResourceStatusReportingInitiation)
+	assert.Empty(t, ueClient.UeIdsC)
+}
+
+// Test 2: F1 clients without UE streams send UE-associated messages with Send
+func TestSendUeFallback(t *testing.T) {
+	duInstance, client := createTestDU(t, config.DUConfig{})
+
+	require.NoError(t, duInstance.HandleUeContextSetupRequest(ueContextSetupRequest(100, 7)))
+	pdu := waitForMessage(t, client, ies.ProcedureCode_UEContextSetup)
+	assert.Equal(t, int64(100), pdu.Message.Msg.(*ies.UEContextSetupResponse).GNBCUUEF1APID)
+}
+
+// Test 3: Non UE-associated signalling on stream 0, UE-associated signalling spread on the others
+func TestSctpUeStreams(t *testing.T) {
+	listener := listenSctp(t, "127.0.0.1")
+	_, conn := startSctpDU(t, listener, config.DUConfig{SCTPStreams: 4})
+
+	pdu, stream := readSctp(t, conn)
+	assert.Equal(t, int64(ies.ProcedureCode_F1Setup), int64(pdu.Message.ProcedureCode.Value))
+	assert.Equal(t, uint16(0), stream)
+
+	send := func(msg f1ap.F1apMessageEncoder) {
+		data, err := f1ap.F1apEncode(msg)
+		require.NoError(t, err)
+		_, err = conn.SCTPWrite(data, &sctp.SndRcvInfo{PPID: du.F1AP_PPID})
+		require.NoError(t, err)
+	}
+
+	// 3 UE streams: stream 1 + gNB-DU UE F1AP ID mod 3
+	for duUeId, want := range map[int64]uint16{3: 1, 4: 2, 5: 3} {
+		id := duUeId
+		send(&ies.UEContextSetupRequest{GNBCUUEF1APID: 100, GNBDUUEF1APID: &id})
+		pdu, stream := readSctp(t, conn)
+		require.Equal(t, int64(ies.ProcedureCode_UEContextSetup), int64(pdu.Message.ProcedureCode.Value))
+		assert.Equal(t, id, pdu.Message.Msg.(*ies.UEContextSetupResponse).GNBDUUEF1APID)
+		assert.Equal(t, want, stream, "gNB-DU UE F1AP ID %d", id)
+	}
+
+	send(&ies.ResourceStatusRequest{
+		GNBCUMeasurementID:  1,
+		RegistrationRequest: ies.RegistrationRequest{Value: ies.RegistrationRequestStop},
+	})
+	pdu, stream = readSctp(t, conn)
+	assert.Equal(t, int64(ies.ProcedureCode_ResourceStatusReportingInitiation), int64(pdu.Message.ProcedureCode.Value))
+	assert.Equal(t, uint16(0), stream)
+}
@@ -0,0 +1,169 @@
+package test
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext/sec"
+)
+
+func unhex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	require.NoError(t, err)
+	return b
+}
+
+// curve returns the curve of an ECIES profile
+func curve(scheme uint8) ecdh.Curve {
+	if scheme == nas.ProtectionSchemeECIESProfileB {
+		return ecdh.P256()
+	}
+	return ecdh.X25519()
+}
+
+// Test 1: scheme output for the test data of TS 33.501 C.4.3 (Profile A)
+// and C.4.4 (Profile B) for the MSIN 0123456789 (plaintext block 00012080f6)
+func TestConcealVectors(t *testing.T) {
+	tests := []struct {
+		name         string
+		scheme       uint8
+		hnPrivate    string
+		hnPublic     string
+		ephPrivate   string // empty when only the home network side of the vector is used
+		ephPublic    string
+		plaintext    string
+		schemeOutput string
+	}{
+		{
+			name:         "Profile A",
+			scheme:       nas.ProtectionSchemeECIESProfileA,
+			hnPrivate:    "c53c22208b61860b06c62e5406a7b330c2b577aa5558981510d128247d38bd1d",
+			hnPublic:     "5a8d38864820197c3394b92613b20b91633cbd897119273bf8e4a6f4eec0a650",
+			ephPublic:    "b2e92f836055a255837debf850b528997ce0201cb82adfe4be1f587d07d8457d",
+			plaintext:    "00012080f6",
+			schemeOutput: "b2e92f836055a255837debf850b528997ce0201cb82adfe4be1f587d07d8457d" + "cb02352410" + "cddd9e730ef3fa87",
+		},
+		{
+			name:         "Profile B",
+			scheme:       nas.ProtectionSchemeECIESProfileB,
+			hnPrivate:    "f1ab1074477ebcc7f554ea1c5fc368b1616730155e0041ac447d6301975fecda",
+			hnPublic:     "0272da71976234ce833a6907425867b82e074d44ef907dfb4b3e21c1c2256ebcd1",
+			ephPrivate:   "99798858a1dc6a2c68637149a4b1dbfd1fdff5addd62a2142f06699ed7602529",
+			ephPublic:    "039aab8376597021e855679a9778ea0b67396e68c66df32c0f41e9acca2da9b9d1",
+			plaintext:    "00012080f6",
+			schemeOutput: "039aab8376597021e855679a9778ea0b67396e68c66df32c0f41e9acca2da9b9d1" + "46a33fc271" + "6ac7dae96aa30a4d",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hn, err := sec.NewHomeNetworkKey(tt.scheme, unhex(t, tt.hnPublic))
+			require.NoError(t, err)
+			plaintext := unhex(t, tt.plaintext)
+			want := unhex(t, tt.schemeOutput)
+
+			if tt.ephPrivate != "" {
+				ephemeral, err := curve(tt.scheme).NewPrivateKey(unhex(t, tt.ephPrivate))
+				require.NoError(t, err)
+				output, err := hn.ConcealForTest(plaintext, ephemeral)
+				require.NoError(t, err)
+				assert.Equal(t, want, output)
+			}
+
+			// the shared secret of the vector from the home network private key
+			hnPrivate, err := curve(tt.scheme).NewPrivateKey(unhex(t, tt.hnPrivate))
+			require.NoError(t, err)
+			ephPublic := unhex(t, tt.ephPublic)
+			ephKey, err := curve(tt.scheme).NewPublicKey(expandPoint(t, tt.scheme, ephPublic))
+			require.NoError(t, err)
+			shared, err := hnPrivate.ECDH(ephKey)
+			require.NoError(t, err)
+			sealed, err := sec.SealForTest(shared, ephPublic, plaintext)
+			require.NoError(t, err)
+			assert.Equal(t, want[len(ephPublic):], sealed)
+		})
+	}
+}
+
+// Test 2: concealment with fixed ephemeral keys, the SIDF recovers the plaintext
+func TestConcealRoundTrip(t *testing.T) {
+	tests := []struct {
+		name       string
+		curve      ecdh.Curve
+		scheme     uint8
+		ephPrivate string
+		keyLen     int
+	}{
+		{"Profile A", ecdh.X25519(), nas.ProtectionSchemeECIESProfileA, "0a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20212223242526272829", 32},
+		{"Profile B", ecdh.P256(), nas.ProtectionSchemeECIESProfileB, "0a0b0c0d0e0f101112131415161718191a1b1c1d1e1f20212223242526272829", 33},
+	}
+	plaintext := unhex(t, "21436587f9")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hnPrivate, err := tt.curve.NewPrivateKey(unhex(t, "c53c22208b61860b06c62e5406a7b330c2b577aa5558981510d128247d38bd1d"))
+			require.NoError(t, err)
+			hn, err := sec.NewHomeNetworkKey(tt.scheme, hnPrivate.PublicKey().Bytes())
+			require.NoError(t, err)
+			ephemeral, err := tt.curve.NewPrivateKey(unhex(t, tt.ephPrivate))
+			require.NoError(t, err)
+
+			output, err := hn.ConcealForTest(plaintext, ephemeral)
+			require.NoError(t, err)
+			require.Len(t, output, tt.keyLen+len(plaintext)+sec.SUCI_MAC_TAG_LEN)
+
+			ephPublic := output[:tt.keyLen]
+			ciphertext := output[tt.keyLen : tt.keyLen+len(plaintext)]
+			tag := output[tt.keyLen+len(plaintext):]
+			ephKey, err := tt.curve.NewPublicKey(expandPoint(t, tt.scheme, ephPublic))
+			require.NoError(t, err)
+			shared, err := hnPrivate.ECDH(ephKey)
+			require.NoError(t, err)
+
+			keys := sec.AnsiX963KdfForTest(shared, ephPublic, sec.SUCI_ENC_KEY_LEN+sec.SUCI_ICB_LEN+sec.SUCI_MAC_KEY_LEN)
+			mac := hmac.New(sha256.New, keys[sec.SUCI_ENC_KEY_LEN+sec.SUCI_ICB_LEN:])
+			mac.Write(ciphertext)
+			assert.Equal(t, mac.Sum(nil)[:sec.SUCI_MAC_TAG_LEN], tag)
+
+			block, err := aes.NewCipher(keys[:sec.SUCI_ENC_KEY_LEN])
+			require.NoError(t, err)
+			recovered := make([]byte, len(ciphertext))
+			cipher.NewCTR(block, keys[sec.SUCI_ENC_KEY_LEN:sec.SUCI_ENC_KEY_LEN+sec.SUCI_ICB_LEN]).XORKeyStream(recovered, ciphertext)
+			assert.Equal(t, plaintext, recovered)
+		})
+	}
+}
+
+// Test 3: each SUCI has its own ephemeral key
+func TestConcealFreshEphemeralKey(t *testing.T) {
+	hn, err := sec.NewHomeNetworkKey(nas.ProtectionSchemeECIESProfileA, unhex(t, "5a8d38864820197c3394b92613b20b91633cbd897119273bf8e4a6f4eec0a650"))
+	require.NoError(t, err)
+	first, err := hn.Conceal([]byte{0x00, 0x01, 0x20, 0x80, 0xf6})
+	require.NoError(t, err)
+	second, err := hn.Conceal([]byte{0x00, 0x01, 0x20, 0x80, 0xf6})
+	require.NoError(t, err)
+	assert.NotEqual(t, first[:32], second[:32])
+}
+
+// expandPoint returns a compressed Profile B point in the uncompressed form of crypto/ecdh
+func expandPoint(t *testing.T, scheme uint8, point []byte) []byte {
+	if scheme != nas.ProtectionSchemeECIESProfileB || len(point) != 33 {
+		return point
+	}
+	x, y := elliptic.UnmarshalCompressed(elliptic.P256(), point)
+	require.NotNil(t, x)
+	uncompressed := make([]byte, 65)
+	uncompressed[0] = 0x04
+	x.FillBytes(uncompressed[1:33])
+	y.FillBytes(uncompressed[33:])
+	return uncompressed
+}
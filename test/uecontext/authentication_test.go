@@ -0,0 +1,165 @@
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+	"du_ue/internal/uecontext/sec"
+	"du_ue/pkg/config"
+)
+
+var (
+	testRand = bytes.Repeat([]byte{0x23}, 16)
+	testAbba = []byte{0x00, 0x00}
+	amf5g    = []byte{0x80, 0x00} // separation bit set
+)
+
+// authUe creates a UE without NAS security context, with the given SQN in its state file
+func authUe(t *testing.T, sqn string) *uecontext.UeContext {
+	dir := t.TempDir()
+	state := `{"sqn": "` + sqn + `"}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nas_imsi-999700000000001.json"), []byte(state), 0o600))
+	ue := createTestUe(t, config.UEConfig{StateDir: dir})
+	ue.SendToDuChannel = make(chan []byte, 16)
+	return ue
+}
+
+// testMilenage returns the Milenage of the network for the subscription of createTestUe
+func testMilenage(t *testing.T, rand []byte) *sec.Milenage {
+	key, _ := hex.DecodeString("00112233445566778899aabbccddeeff")
+	opc, _ := hex.DecodeString("00112233445566778899aabbccddeeff")
+	milenage, err := sec.NewMilenage(key, opc, true)
+	require.NoError(t, err)
+	require.NoError(t, milenage.SetRand(rand))
+	return milenage
+}
+
+// autn returns the AUTN of the network for a RAND, SQN and AMF field
+func autn(t *testing.T, rand []byte, sqn string, amfField []byte) []byte {
+	milenage := testMilenage(t, rand)
+	netSqn, _ := hex.DecodeString(sqn)
+	_, ak := milenage.F2F5()
+	macA, _, err := milenage.F1(netSqn, amfField)
+	require.NoError(t, err)
+
+	autn := make([]byte, 0, 16)
+	for i := range netSqn {
+		autn = append(autn, netSqn[i]^ak[i])
+	}
+	autn = append(autn, amfField...)
+	return append(autn, macA...)
+}
+
+// authenticationRequest encodes a plain Authentication Request
+func authenticationRequest(t *testing.T, ngKsi uint8, abba, rand, autn []byte) []byte {
+	msg := &nas.AuthenticationRequest{
+		Ngksi:                       nas.KeySetIdentifier{Id: ngKsi},
+		Abba:                        abba,
+		AuthenticationParameterRand: rand,
+		AuthenticationParameterAutn: autn,
+	}
+	msg.SetSecurityHeader(nas.NasSecNone)
+	pdu, err := nas.EncodeMm(nil, msg)
+	require.NoError(t, err)
+	return pdu
+}
+
+// authenticationFailure returns the next Authentication Failure of the UE and checks its cause
+func authenticationFailure(t *testing.T, ue *uecontext.UeContext, cause uint8) *nas.AuthenticationFailure {
+	gmm := ulNas(t, ue, nil)
+	require.NotNil(t, gmm.AuthenticationFailure)
+	assert.Equal(t, cause, gmm.AuthenticationFailure.GmmCause)
+	return gmm.AuthenticationFailure
+}
+
+// Test 1: an Authentication Request with a missing or invalid mandatory IE is answered by 5GMM
+// Status #96 and counted, the UE keeps running
+func TestAuthenticationRequestMalformed(t *testing.T) {
+	validAutn := autn(t, testRand, "000000000021", amf5g)
+	tests := []struct {
+		name  string
+		ngKsi uint8
+		abba  []byte
+		rand  []byte
+		autn  []byte
+	}{
+		{"ngKSI 7", 7, testAbba, testRand, validAutn},
+		{"RAND missing", 1, testAbba, nil, validAutn},
+		{"AUTN missing", 1, testAbba, testRand, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ue := authUe(t, "000000000020")
+			ue.HandleNasMsg(authenticationRequest(t, tt.ngKsi, tt.abba, tt.rand, tt.autn))
+
+			gmm := ulNas(t, ue, nil)
+			require.NotNil(t, gmm.GmmStatus)
+			assert.Equal(t, nas.Cause5GMMInvalidMandatoryInformation, gmm.GmmStatus.GmmCause)
+			assert.Equal(t, uecontext.AuthErrorStats{MalformedRequests: 1}, ue.AuthErrors())
+		})
+	}
+}
+
+// Test 2: a valid Authentication Request is answered with RES*, a retransmission with the same
+// RAND again, a new RAND reusing the ngKSI of the security context with Authentication Failure #71
+func TestAuthenticationRequestNgKsiInUse(t *testing.T) {
+	ue := authUe(t, "000000000020")
+	request := authenticationRequest(t, 1, testAbba, testRand, autn(t, testRand, "000000000021", amf5g))
+
+	for i := 0; i < 2; i++ {
+		ue.HandleNasMsg(request)
+		gmm := ulNas(t, ue, nil)
+		require.NotNil(t, gmm.AuthenticationResponse)
+		assert.Len(t, gmm.AuthenticationResponse.AuthenticationResponseParameter, 16)
+	}
+
+	newRand := bytes.Repeat([]byte{0x42}, 16)
+	ue.HandleNasMsg(authenticationRequest(t, 1, testAbba, newRand, autn(t, newRand, "000000000022", amf5g)))
+	authenticationFailure(t, ue, nas.Cause5GMMngKSIAlreadyInUse)
+	assert.Equal(t, uecontext.AuthErrorStats{NgKsiInUse: 1}, ue.AuthErrors())
+}
+
+// Test 3: AUTN checks, the cause of the Authentication Failure and the error counters
+func TestAuthenticationRequestAutn(t *testing.T) {
+	badMac := autn(t, testRand, "000000000021", amf5g)
+	badMac[15] ^= 0xff
+
+	tests := []struct {
+		name  string
+		autn  []byte
+		cause uint8
+		stats uecontext.AuthErrorStats
+	}{
+		{"MAC failure", badMac, nas.Cause5GMMMACFailure, uecontext.AuthErrorStats{MacFailures: 1}},
+		{"separation bit not set", autn(t, testRand, "000000000021", []byte{0x00, 0x00}), nas.Cause5GMMNon5GAuthenticationUnacceptable, uecontext.AuthErrorStats{Non5GAuth: 1}},
+		{"SQN out of range", autn(t, testRand, "000000000010", amf5g), nas.Cause5GMMSynchFailure, uecontext.AuthErrorStats{SynchFailures: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ue := authUe(t, "000000000020")
+			ue.HandleNasMsg(authenticationRequest(t, 1, testAbba, testRand, tt.autn))
+
+			failure := authenticationFailure(t, ue, tt.cause)
+			assert.Equal(t, tt.stats, ue.AuthErrors())
+			if tt.cause != nas.Cause5GMMSynchFailure {
+				assert.Nil(t, failure.AuthenticationFailureParameter)
+				return
+			}
+			// the AUTS gives the network the SQN of the UE
+			sqn, err := testMilenage(t, testRand).ValidateAuts(failure.AuthenticationFailureParameter, testRand)
+			require.NoError(t, err)
+			assert.Equal(t, "000000000020", hex.EncodeToString(sqn[:]))
+		})
+	}
+}
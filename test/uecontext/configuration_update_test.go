@@ -0,0 +1,210 @@
+package test
+
+import (
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+)
+
+const newGuti = "99970cafe0000000002"
+
+// plmn99970 is PLMN 999/70 as coded in a TAI list
+var plmn99970 = []byte{0x99, 0xf9, 0x07}
+
+func indication(ack, registration bool) *uint8 {
+	var v uint8
+	if ack {
+		v |= uecontext.CONFIGURATION_UPDATE_ACK
+	}
+	if registration {
+		v |= uecontext.CONFIGURATION_UPDATE_RED
+	}
+	return &v
+}
+
+// gsm7NetworkName codes a network name in the GSM 7 bit default alphabet (TS 24.501 9.11.3.35),
+// for ASCII letters and digits
+func gsm7NetworkName(text string) *nas.NetworkName {
+	bits := 7 * len(text)
+	packed := make([]byte, (bits+7)/8)
+	for i := 0; i < len(text); i++ {
+		bit := 7 * i
+		packed[bit/8] |= text[i] << (bit % 8)
+		if bit%8 > 1 {
+			packed[bit/8+1] |= text[i] >> (8 - bit%8)
+		}
+	}
+	spare := len(packed)*8 - bits
+	return &nas.NetworkName{Bytes: append([]byte{0x80 | uint8(spare)}, packed...)}
+}
+
+// ucs2NetworkName codes a network name in UCS2
+func ucs2NetworkName(text string) *nas.NetworkName {
+	value := []byte{0x90}
+	for _, c := range utf16.Encode([]rune(text)) {
+		value = append(value, byte(c>>8), byte(c))
+	}
+	return &nas.NetworkName{Bytes: value}
+}
+
+// ladnInformation codes a LADN of a single label DNN available in the given TACs of PLMN 999/70
+func ladnInformation(dnn string, tacs ...uint32) []byte {
+	tai := append([]byte{uint8(len(tacs) - 1)}, plmn99970...)
+	for _, tac := range tacs {
+		tai = append(tai, byte(tac>>16), byte(tac>>8), byte(tac))
+	}
+	value := []byte{uint8(len(dnn) + 1), uint8(len(dnn))}
+	value = append(value, dnn...)
+	value = append(value, uint8(len(tai)))
+	return append(value, tai...)
+}
+
+func configurationUpdate(t *testing.T, ind *uint8) *nas.ConfigurationUpdateCommand {
+	var guti nas.Guti
+	require.NoError(t, guti.Parse(newGuti))
+	return &nas.ConfigurationUpdateCommand{
+		ConfigurationUpdateIndication: ind,
+		Guti:                          &nas.MobileIdentity{Id: &guti},
+	}
+}
+
+// Test 1: 5G-GUTI reallocation acknowledged by Configuration Update Complete
+func TestConfigurationUpdateGuti(t *testing.T) {
+	ue, amf := securedUe(t)
+
+	ue.HandleNasMsg(dlNas(t, amf, configurationUpdate(t, indication(true, false))))
+
+	gmm := ulNas(t, ue, amf)
+	assert.NotNil(t, gmm.ConfigurationUpdateComplete)
+	assert.Equal(t, nas.NasSecBoth, gmm.SecHeader)
+	require.NotNil(t, ue.GutiForTest())
+	assert.Equal(t, newGuti, ue.GutiForTest().String())
+	assert.Equal(t, uecontext.UE_STATE_REGISTERED, ue.GetState())
+	assert.Zero(t, ue.PendingRegistrationForTest())
+}
+
+// Test 2: without acknowledgement requested the command is not answered
+func TestConfigurationUpdateNoAck(t *testing.T) {
+	ue, amf := securedUe(t)
+
+	ue.HandleNasMsg(dlNas(t, amf, configurationUpdate(t, nil)))
+
+	assert.Equal(t, newGuti, ue.GutiForTest().String())
+	select {
+	case <-ue.SendToDuChannel:
+		assert.Fail(t, "Configuration Update Complete without acknowledgement requested")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// Test 3: TAI list and NSSAI of the command replace those of the registration, in the NAS state
+func TestConfigurationUpdateState(t *testing.T) {
+	dir := t.TempDir()
+	ue, amf := storedUe(t, dir)
+
+	command := configurationUpdate(t, indication(true, false))
+	var plmn nas.PlmnId
+	require.NoError(t, plmn.Set("999", "70"))
+	command.TaiList = &nas.TrackingAreaIdentityList{
+		Lists: []nas.TaiListInf{&nas.TaiListType0{PlmnId: plmn, TacList: []uint32{1, 2, 3}}},
+	}
+	command.AllowedNssai = &nas.Nssai{}
+	command.AllowedNssai.Add(&nas.SNssai{Sst: 2})
+	ue.HandleNasMsg(dlNas(t, amf, command))
+	ulNas(t, ue, amf)
+
+	state := readState(t, dir)
+	assert.Equal(t, newGuti, state.Guti)
+	require.Len(t, state.TaiList, 1)
+	assert.Equal(t, []uint32{1, 2, 3}, state.TaiList[0].Tacs)
+	require.Len(t, state.AllowedNssai, 1)
+	assert.Equal(t, uint8(2), state.AllowedNssai[0].Sst)
+	assert.Empty(t, state.AllowedNssai[0].Sd)
+}
+
+// Test 4: network names in the GSM 7 bit default alphabet and in UCS2
+func TestConfigurationUpdateNetworkName(t *testing.T) {
+	tests := []struct {
+		name  string
+		full  *nas.NetworkName
+		short *nas.NetworkName
+		want  [2]string
+	}{
+		{"GSM 7 bit", gsm7NetworkName("Test Network 1"), gsm7NetworkName("TestNet"), [2]string{"Test Network 1", "TestNet"}},
+		{"GSM 7 bit, 8 characters", gsm7NetworkName("Operator"), nil, [2]string{"Operator", ""}},
+		{"UCS2", ucs2NetworkName("Réseau Test"), ucs2NetworkName("Rés"), [2]string{"Réseau Test", "Rés"}},
+		{"invalid UCS2", &nas.NetworkName{Bytes: []byte{0x90, 0x00}}, nil, [2]string{"", ""}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ue, amf := securedUe(t)
+			command := configurationUpdate(t, nil)
+			command.FullNameForNetwork = tt.full
+			command.ShortNameForNetwork = tt.short
+
+			ue.HandleNasMsg(dlNas(t, amf, command))
+
+			full, short := ue.NetworkNameForTest()
+			assert.Equal(t, tt.want[0], full)
+			assert.Equal(t, tt.want[1], short)
+		})
+	}
+}
+
+// Test 5: LADN information, a DNN with the tracking areas it is available in
+func TestConfigurationUpdateLadn(t *testing.T) {
+	ue, amf := securedUe(t)
+
+	command := configurationUpdate(t, nil)
+	ladns := append(ladnInformation("ladn1", 1, 2), ladnInformation("ladn2", 7)...)
+	command.LadnInformation = &nas.LadnInformation{Bytes: ladns}
+	ue.HandleNasMsg(dlNas(t, amf, command))
+
+	assert.Equal(t, map[string][]uint32{"ladn1": {1, 2}, "ladn2": {7}}, ue.LadnsForTest())
+
+	// an incomplete LADN information keeps the previous LADNs
+	command = configurationUpdate(t, nil)
+	command.LadnInformation = &nas.LadnInformation{Bytes: ladns[:len(ladns)-2]}
+	ue.HandleNasMsg(dlNas(t, amf, command))
+	assert.Len(t, ue.LadnsForTest(), 2)
+}
+
+// Test 6: registration requested, a mobility registration update follows the release of the RRC
+// connection
+func TestConfigurationUpdateRegistration(t *testing.T) {
+	ue, amf := securedUe(t)
+
+	ue.HandleNasMsg(dlNas(t, amf, configurationUpdate(t, indication(true, true))))
+
+	gmm := ulNas(t, ue, amf)
+	assert.NotNil(t, gmm.ConfigurationUpdateComplete)
+	assert.Equal(t, nas.RegistrationType5GSMobilityRegistrationUpdating, ue.PendingRegistrationForTest())
+
+	toIdle(t, ue)
+	_, gmm = initialNas(t, ue, amf)
+	require.NotNil(t, gmm.RegistrationRequest)
+	assert.Equal(t, nas.RegistrationType5GSMobilityRegistrationUpdating, gmm.RegistrationRequest.RegistrationType.GetType())
+	guti, ok := gmm.RegistrationRequest.MobileIdentity.Id.(*nas.Guti)
+	require.True(t, ok)
+	assert.Equal(t, newGuti, guti.String())
+	assert.Zero(t, ue.PendingRegistrationForTest())
+}
+
+// Test 7: a command with nothing to apply but the acknowledgement keeps the 5G-GUTI
+func TestConfigurationUpdateEmpty(t *testing.T) {
+	ue, amf := securedUe(t)
+
+	ue.HandleNasMsg(dlNas(t, amf, &nas.ConfigurationUpdateCommand{ConfigurationUpdateIndication: indication(true, false)}))
+
+	gmm := ulNas(t, ue, amf)
+	assert.NotNil(t, gmm.ConfigurationUpdateComplete)
+	assert.Equal(t, testGuti, ue.GutiForTest().String())
+}
@@ -0,0 +1,155 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+const testGuti = "99970cafe0000000001"
+
+// registeredUe creates a UE registered with a 5G-GUTI and PDU session 1 active, without a NAS
+// security context so that the NAS messages go in plain
+func registeredUe(t *testing.T) *uecontext.UeContext {
+	ue := createTestUe(t, config.UEConfig{})
+	ue.SendToDuChannel = make(chan []byte, 16)
+
+	var guti nas.Guti
+	require.NoError(t, guti.Parse(testGuti))
+	ue.SetRegisteredForTest(&guti)
+	ue.AddPduSessionForTest(1, ueIp, 1)
+	return ue
+}
+
+// ulRrc returns the next RRC message the UE sends to the DU, waiting for the NAS timers of the tests
+func ulRrc(t *testing.T, ue *uecontext.UeContext) []byte {
+	select {
+	case rrcBytes := <-ue.SendToDuChannel:
+		return rrcBytes
+	case <-time.After(5 * time.Second):
+		require.FailNow(t, "no UL RRC message from the UE")
+	}
+	return nil
+}
+
+// ulNas returns the next NAS message the UE sends in an UL Information Transfer, decoded with the
+// NAS context of the AMF or in plain when nasCtx is nil
+func ulNas(t *testing.T, ue *uecontext.UeContext, nasCtx *nas.NasContext) *nas.DecodedGmmMessage {
+	var msg rrcies.UL_DCCH_Message
+	require.NoError(t, rrc.Decode(ulRrc(t, ue), &msg))
+	require.NotNil(t, msg.Message.C1)
+	require.Equal(t, rrcies.UL_DCCH_MessageType_C1_Choice_UlInformationTransfer, msg.Message.C1.Choice)
+	ies := msg.Message.C1.UlInformationTransfer.CriticalExtensions.UlInformationTransfer
+	require.NotNil(t, ies)
+	require.NotNil(t, ies.DedicatedNAS_Message)
+
+	nasMsg, err := nas.Decode(nasCtx, ies.DedicatedNAS_Message.Value)
+	require.NoError(t, err)
+	require.NotNil(t, nasMsg.Gmm)
+	return nasMsg.Gmm
+}
+
+// deregistrationRequest encodes a plain network-initiated Deregistration Request
+func deregistrationRequest(t *testing.T, accessType uint8, reregistration bool, cause *uint8) []byte {
+	msg := &nas.DeregistrationRequestToUe{GmmCause: cause}
+	msg.DeRegistrationType.SetAccessType(accessType)
+	msg.DeRegistrationType.SetReregistration(reregistration)
+	msg.SetSecurityHeader(nas.NasSecNone)
+	pdu, err := nas.EncodeMm(nil, msg)
+	require.NoError(t, err)
+	return pdu
+}
+
+func cause(c uint8) *uint8 {
+	return &c
+}
+
+// Test 1: de-registration without re-registration, accepted and PDU sessions released locally
+func TestDeregistrationRequest(t *testing.T) {
+	ue := registeredUe(t)
+
+	ue.HandleNasMsg(deregistrationRequest(t, uecontext.DEREGISTRATION_ACCESS_3GPP, false, nil))
+
+	gmm := ulNas(t, ue, nil)
+	assert.NotNil(t, gmm.DeregistrationAcceptToUe)
+	assert.Equal(t, uecontext.UE_STATE_DEREGISTERED, ue.GetState())
+	assert.Nil(t, ue.PduSessionForTest(1))
+	require.NotNil(t, ue.GutiForTest())
+	assert.Equal(t, testGuti, ue.GutiForTest().String())
+	assert.Zero(t, ue.PendingRegistrationForTest())
+}
+
+// Test 2: re-registration required, an initial registration waits for the release of the RRC connection
+func TestDeregistrationReregistration(t *testing.T) {
+	ue := registeredUe(t)
+
+	ue.HandleNasMsg(deregistrationRequest(t, uecontext.DEREGISTRATION_ACCESS_3GPP, true, nil))
+
+	gmm := ulNas(t, ue, nil)
+	assert.NotNil(t, gmm.DeregistrationAcceptToUe)
+	assert.Equal(t, uecontext.UE_STATE_DEREGISTERED, ue.GetState())
+	assert.NotNil(t, ue.GutiForTest())
+	assert.Equal(t, nas.RegistrationType5GSInitialRegistration, ue.PendingRegistrationForTest())
+}
+
+// Test 3: the 5GMM cause decides whether the 5G-GUTI is deleted and the UE registers again
+func TestDeregistrationCause(t *testing.T) {
+	tests := []struct {
+		name         string
+		cause        uint8
+		dropIdentity bool
+		reregister   bool
+	}{
+		{"illegal UE", nas.Cause5GMMIllegalUE, true, false},
+		{"5GS services not allowed", nas.Cause5GMM5GSServicesNotAllowed, true, false},
+		{"PLMN not allowed", nas.Cause5GMMPLMNNotAllowed, true, false},
+		{"tracking area not allowed", nas.Cause5GMMTrackingAreaNotAllowed, true, false},
+		{"implicitly de-registered", nas.Cause5GMMImplicitlyDeregistered, true, true},
+		{"congestion", nas.Cause5GMMCongestion, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ue := registeredUe(t)
+
+			ue.HandleNasMsg(deregistrationRequest(t, uecontext.DEREGISTRATION_ACCESS_3GPP, true, cause(tt.cause)))
+
+			gmm := ulNas(t, ue, nil)
+			assert.NotNil(t, gmm.DeregistrationAcceptToUe)
+			assert.Equal(t, uecontext.UE_STATE_DEREGISTERED, ue.GetState())
+			if tt.dropIdentity {
+				assert.Nil(t, ue.GutiForTest())
+			} else {
+				assert.NotNil(t, ue.GutiForTest())
+			}
+			if tt.reregister {
+				assert.Equal(t, nas.RegistrationType5GSInitialRegistration, ue.PendingRegistrationForTest())
+			} else {
+				assert.Zero(t, ue.PendingRegistrationForTest())
+			}
+		})
+	}
+}
+
+// Test 4: de-registration for non-3GPP access only, the 3GPP registration is kept
+func TestDeregistrationNon3gpp(t *testing.T) {
+	ue := registeredUe(t)
+
+	ue.HandleNasMsg(deregistrationRequest(t, uecontext.DEREGISTRATION_ACCESS_NON_3GPP, true, cause(nas.Cause5GMMIllegalUE)))
+
+	gmm := ulNas(t, ue, nil)
+	assert.NotNil(t, gmm.DeregistrationAcceptToUe)
+	assert.Equal(t, uecontext.UE_STATE_REGISTERED, ue.GetState())
+	assert.NotNil(t, ue.PduSessionForTest(1))
+	assert.NotNil(t, ue.GutiForTest())
+	assert.Zero(t, ue.PendingRegistrationForTest())
+}
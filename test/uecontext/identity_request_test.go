@@ -0,0 +1,72 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reogac/nas"
+)
+
+// identityRequest encodes a plain Identity Request
+func identityRequest(t *testing.T, identityType uint8) []byte {
+	msg := &nas.IdentityRequest{IdentityType: identityType}
+	msg.SetSecurityHeader(nas.NasSecNone)
+	pdu, err := nas.EncodeMm(nil, msg)
+	require.NoError(t, err)
+	return pdu
+}
+
+// Test 1: with a NAS security context the UE answers every identity type, ciphered
+func TestIdentityRequest(t *testing.T) {
+	var guti nas.Guti
+	require.NoError(t, guti.Parse(testGuti))
+	tmsi := &nas.Tmsi5Gs{AmfId: guti.AmfId, Tmsi: guti.Tmsi}
+
+	tests := []struct {
+		name         string
+		identityType uint8
+		want         string
+	}{
+		{"IMEI", nas.MobileIdentity5GSTypeImei, "352099000000014"},
+		{"IMEISV", nas.MobileIdentity5GSTypeImeisv, "3520990000000101"},
+		{"5G-S-TMSI", nas.MobileIdentity5GSType5gSTmsi, tmsi.String()},
+		{"MAC address", nas.MobileIdentity5GSTypeMac, "020000000001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ue, amf := securedUe(t)
+			request := &nas.IdentityRequest{IdentityType: tt.identityType}
+			ue.HandleNasMsg(dlNas(t, amf, request))
+
+			gmm := ulNas(t, ue, amf)
+			assert.Equal(t, nas.NasSecBoth, gmm.SecHeader)
+			require.NotNil(t, gmm.IdentityResponse)
+			identity := gmm.IdentityResponse.MobileIdentity
+			assert.Equal(t, tt.identityType, identity.GetType())
+			require.NotNil(t, identity.Id)
+			assert.Equal(t, tt.want, identity.Id.String())
+		})
+	}
+}
+
+// Test 2: without a NAS security context only the SUCI is given, in plain
+func TestIdentityRequestUnprotected(t *testing.T) {
+	ue := registeredUe(t)
+
+	ue.HandleNasMsg(identityRequest(t, nas.MobileIdentity5GSTypeSuci))
+	gmm := ulNas(t, ue, nil)
+	assert.Equal(t, nas.NasSecNone, gmm.SecHeader)
+	require.NotNil(t, gmm.IdentityResponse)
+	assert.Equal(t, nas.MobileIdentity5GSTypeSuci, gmm.IdentityResponse.MobileIdentity.GetType())
+
+	ue.HandleNasMsg(identityRequest(t, nas.MobileIdentity5GSTypeImei))
+	select {
+	case <-ue.SendToDuChannel:
+		assert.Fail(t, "IMEI sent without NAS security context")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
@@ -0,0 +1,145 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lvdund/asn1go/aper"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+// mobileUe creates a registered UE camping on cell PCI 1 of TAC 1, with cell PCI 2 of TAC 2 next
+// to it, and a registration area of the given TACs
+func mobileUe(t *testing.T, conf config.UEConfig, tacs ...uint32) (*uecontext.UeContext, *nas.NasContext) {
+	conf.Cells = []config.CellConfig{
+		{PCI: 1, TAC: "000001"},
+		{PCI: 2, TAC: "000002"},
+	}
+	ue := createTestUe(t, conf)
+	ue.SendToDuChannel = make(chan []byte, 16)
+	require.NoError(t, ue.SetSecurityContextForTest(testNgKsi, testKamf, testEncAlg, testIntAlg))
+	ue.AddPduSessionForTest(1, ueIp, 1)
+	ue.SetServingCell(config.CellConfig{PCI: 1, TAC: "000001"})
+
+	amf := securedAmf(t)
+	ue.SetState(uecontext.UE_STATE_REGISTERING)
+	acceptRegistration(t, ue, amf, registrationAccept(t, t3512Deactivated, tacs...))
+	return ue, amf
+}
+
+// initialNas answers the RRCSetupRequest of the UE in RRC idle and returns its establishment cause
+// and the initial NAS message of RRCSetupComplete
+func initialNas(t *testing.T, ue *uecontext.UeContext, amf *nas.NasContext) (aper.Enumerated, *nas.DecodedGmmMessage) {
+	var request rrcies.UL_CCCH_Message
+	require.NoError(t, rrc.Decode(ulRrc(t, ue), &request))
+	require.NotNil(t, request.Message.C1)
+	require.NotNil(t, request.Message.C1.RrcSetupRequest)
+	ies := request.Message.C1.RrcSetupRequest.RrcSetupRequest
+	assert.Equal(t, rrcies.InitialUE_Identity_Choice_Ng_5G_S_TMSI_Part1, ies.Ue_Identity.Choice)
+
+	ue.ReceiveFromDuChannel <- rrcSetup()
+
+	var complete rrcies.UL_DCCH_Message
+	require.NoError(t, rrc.Decode(ulRrc(t, ue), &complete))
+	require.NotNil(t, complete.Message.C1)
+	require.NotNil(t, complete.Message.C1.RrcSetupComplete)
+	setupComplete := complete.Message.C1.RrcSetupComplete.CriticalExtensions.RrcSetupComplete
+	require.NotNil(t, setupComplete)
+
+	nasMsg, err := nas.Decode(amf, setupComplete.DedicatedNAS_Message.Value)
+	require.NoError(t, err)
+	require.NotNil(t, nasMsg.Gmm)
+	assert.False(t, nasMsg.Gmm.MacFailed)
+	return ies.EstablishmentCause.Value, nasMsg.Gmm
+}
+
+// Test 1: reselection to a cell out of the registration area runs a mobility registration update
+// on a new RRC connection, with the PDU session status
+func TestReselectionOutOfArea(t *testing.T) {
+	ue, amf := mobileUe(t, config.UEConfig{}, 1)
+	toIdle(t, ue)
+
+	errC := make(chan error, 1)
+	go func() { errC <- ue.ReselectCell(2) }()
+
+	cause, gmm := initialNas(t, ue, amf)
+	require.NoError(t, <-errC)
+	assert.Equal(t, aper.Enumerated(rrcies.EstablishmentCause_Enum_mo_Signalling), cause)
+	checkRegistrationRequest(t, gmm.RegistrationRequest, nas.RegistrationType5GSMobilityRegistrationUpdating)
+	assert.False(t, gmm.RegistrationRequest.RegistrationType.GetFor())
+
+	inner, err := amf.DecodeMmContainer(gmm.RegistrationRequest.NasMessageContainer)
+	require.NoError(t, err)
+	require.NotNil(t, inner.RegistrationRequest)
+	require.NotNil(t, inner.RegistrationRequest.PduSessionStatus)
+	assert.True(t, inner.RegistrationRequest.PduSessionStatus.Get()[1])
+	assert.Nil(t, inner.RegistrationRequest.UplinkDataStatus)
+}
+
+// Test 2: reselection within the registration area, no registration update
+func TestReselectionInArea(t *testing.T) {
+	ue, _ := mobileUe(t, config.UEConfig{}, 1, 2)
+	toIdle(t, ue)
+
+	require.NoError(t, ue.ReselectCell(2))
+	select {
+	case <-ue.SendToDuChannel:
+		assert.Fail(t, "registration update within the registration area")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// Test 3: reselection only in RRC idle, unknown cells do not change the registration
+func TestReselectionErrors(t *testing.T) {
+	ue, _ := mobileUe(t, config.UEConfig{}, 1)
+	assert.Error(t, ue.ReselectCell(2))
+
+	toIdle(t, ue)
+	assert.NoError(t, ue.ReselectCell(9))
+	select {
+	case <-ue.SendToDuChannel:
+		assert.Fail(t, "registration update for an unknown cell")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+// Test 4: UL data in RRC idle out of the registration area, the mobility registration update asks
+// for the user plane with the uplink data status
+func TestUlDataOutOfArea(t *testing.T) {
+	ue, amf := mobileUe(t, config.UEConfig{}, 1)
+	toIdle(t, ue)
+	ue.SetServingCell(config.CellConfig{PCI: 2, TAC: "000002"})
+
+	assert.Error(t, ue.SendUlDataForTest(1, ipv4(ueIp, dnIp, 17, make([]byte, 8))))
+
+	cause, gmm := initialNas(t, ue, amf)
+	assert.Equal(t, aper.Enumerated(rrcies.EstablishmentCause_Enum_mo_Data), cause)
+	checkRegistrationRequest(t, gmm.RegistrationRequest, nas.RegistrationType5GSMobilityRegistrationUpdating)
+	assert.True(t, gmm.RegistrationRequest.RegistrationType.GetFor())
+
+	inner, err := amf.DecodeMmContainer(gmm.RegistrationRequest.NasMessageContainer)
+	require.NoError(t, err)
+	require.NotNil(t, inner.RegistrationRequest)
+	require.NotNil(t, inner.RegistrationRequest.UplinkDataStatus)
+	assert.True(t, inner.RegistrationRequest.UplinkDataStatus.Get()[1])
+	assert.True(t, inner.RegistrationRequest.PduSessionStatus.Get()[1])
+}
+
+// Test 5: a configured cell reselection event, run once the UE is registered
+func TestReselectionEvent(t *testing.T) {
+	ue, amf := mobileUe(t, config.UEConfig{Events: []config.EventConfig{
+		{Type: "cell_reselection", Delay: 300, PCI: 2},
+	}}, 1)
+	toIdle(t, ue)
+
+	_, gmm := initialNas(t, ue, amf)
+	checkRegistrationRequest(t, gmm.RegistrationRequest, nas.RegistrationType5GSMobilityRegistrationUpdating)
+}
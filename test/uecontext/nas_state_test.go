@@ -0,0 +1,196 @@
+package test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+// storedState is the part of the NAS state file the tests check
+type storedState struct {
+	Guti     string `json:"guti"`
+	Sqn      string `json:"sqn"`
+	Security *struct {
+		NgKsi   uint8  `json:"ngksi"`
+		Kamf    string `json:"kamf"`
+		EncAlg  uint8  `json:"enc_alg"`
+		IntAlg  uint8  `json:"int_alg"`
+		UlCount uint32 `json:"ul_count"`
+		DlCount uint32 `json:"dl_count"`
+	} `json:"security"`
+	AllowedNssai []struct {
+		Sst uint8  `json:"sst"`
+		Sd  string `json:"sd"`
+	} `json:"allowed_nssai"`
+	TaiList []struct {
+		Type uint8    `json:"type"`
+		Tacs []uint32 `json:"tacs"`
+	} `json:"tai_list"`
+}
+
+func readState(t *testing.T, dir string) storedState {
+	data, err := os.ReadFile(filepath.Join(dir, "nas_imsi-999700000000001.json"))
+	require.NoError(t, err)
+	var state storedState
+	require.NoError(t, json.Unmarshal(data, &state))
+	return state
+}
+
+// storedUe registers a UE keeping its NAS state in dir, with an allowed NSSAI and a TAI list
+func storedUe(t *testing.T, dir string) (*uecontext.UeContext, *nas.NasContext) {
+	ue := createTestUe(t, config.UEConfig{StateDir: dir})
+	ue.SendToDuChannel = make(chan []byte, 16)
+	require.NoError(t, ue.SetSecurityContextForTest(testNgKsi, testKamf, testEncAlg, testIntAlg))
+	amf := securedAmf(t)
+
+	accept := registrationAccept(t, t3512Deactivated, 1)
+	var slice nas.SNssai
+	require.NoError(t, slice.Set(1, "010203"))
+	accept.AllowedNssai = &nas.Nssai{}
+	accept.AllowedNssai.Add(&slice)
+	accept.AllowedNssai.Add(&nas.SNssai{Sst: 2})
+	ue.SetState(uecontext.UE_STATE_REGISTERING)
+	acceptRegistration(t, ue, amf, accept)
+	return ue, amf
+}
+
+// Test 1: the NAS state of a registered UE is saved to its state file
+func TestNasStateSave(t *testing.T) {
+	dir := t.TempDir()
+	ue, _ := storedUe(t, dir)
+	ue.SaveState()
+
+	state := readState(t, dir)
+	assert.Equal(t, testGuti, state.Guti)
+	assert.Len(t, state.Sqn, 12)
+	require.NotNil(t, state.Security)
+	assert.Equal(t, uint8(testNgKsi), state.Security.NgKsi)
+	assert.Equal(t, hex.EncodeToString(testKamf), state.Security.Kamf)
+	assert.Equal(t, uint8(testEncAlg), state.Security.EncAlg)
+	assert.Equal(t, uint8(testIntAlg), state.Security.IntAlg)
+	assert.Equal(t, uint32(1), state.Security.UlCount) // next after Registration Complete
+	assert.Equal(t, uint32(0), state.Security.DlCount) // Registration Accept
+	require.Len(t, state.AllowedNssai, 2)
+	assert.Equal(t, uint8(1), state.AllowedNssai[0].Sst)
+	assert.Equal(t, "010203", state.AllowedNssai[0].Sd)
+	assert.Equal(t, uint8(2), state.AllowedNssai[1].Sst)
+	assert.Empty(t, state.AllowedNssai[1].Sd)
+	require.Len(t, state.TaiList, 1)
+	assert.Equal(t, []uint32{1}, state.TaiList[0].Tacs)
+}
+
+// Test 2: the state file keeps a UL NAS COUNT ahead of the protected uplink NAS messages and is
+// only written again once the messages used it up
+func TestNasStateCounts(t *testing.T) {
+	dir := t.TempDir()
+	ue, amf := storedUe(t, dir)
+	ue.SaveState()
+
+	require.NoError(t, ue.TriggerServiceRequest(nas.ServiceTypeSignalling))
+	ulNas(t, ue, amf)
+	state := readState(t, dir)
+	require.NotNil(t, state.Security)
+	assert.Equal(t, uint32(2+uecontext.NAS_COUNT_SAVE_STEP), state.Security.UlCount)
+
+	for i := 0; i <= uecontext.NAS_COUNT_SAVE_STEP; i++ {
+		require.NoError(t, ue.TriggerServiceRequest(nas.ServiceTypeSignalling))
+		ulNas(t, ue, amf)
+		if i < uecontext.NAS_COUNT_SAVE_STEP {
+			assert.Equal(t, uint32(2+uecontext.NAS_COUNT_SAVE_STEP), readState(t, dir).Security.UlCount)
+		}
+	}
+	assert.Equal(t, uint32(3+2*uecontext.NAS_COUNT_SAVE_STEP), readState(t, dir).Security.UlCount)
+
+	// a restarted UE goes on after the COUNTs kept in the file
+	restarted := createTestUe(t, config.UEConfig{StateDir: dir})
+	nasPdu, err := restarted.TriggerInitRegistration()
+	require.NoError(t, err)
+	nasMsg, err := nas.Decode(amf, nasPdu)
+	require.NoError(t, err)
+	require.NotNil(t, nasMsg.Gmm)
+	assert.False(t, nasMsg.Gmm.MacFailed)
+}
+
+// Test 3: a UE restarted with the state file registers with its 5G-GUTI, integrity protected with
+// the stored security context and the requested NSSAI in the ciphered NAS message container
+func TestNasStateRestore(t *testing.T) {
+	dir := t.TempDir()
+	ue, amf := storedUe(t, dir)
+	ue.SaveState()
+
+	restarted := createTestUe(t, config.UEConfig{StateDir: dir})
+	require.NotNil(t, restarted.GutiForTest())
+	assert.Equal(t, testGuti, restarted.GutiForTest().String())
+	assert.Equal(t, uecontext.UE_STATE_DEREGISTERED, restarted.GetState())
+
+	nasPdu, err := restarted.TriggerInitRegistration()
+	require.NoError(t, err)
+	nasMsg, err := nas.Decode(amf, nasPdu)
+	require.NoError(t, err)
+	require.NotNil(t, nasMsg.Gmm)
+	assert.False(t, nasMsg.Gmm.MacFailed)
+	assert.Equal(t, nas.NasSecIntegrity, nasMsg.Gmm.SecHeader)
+	checkRegistrationRequest(t, nasMsg.Gmm.RegistrationRequest, nas.RegistrationType5GSInitialRegistration)
+	assert.Nil(t, nasMsg.Gmm.RegistrationRequest.RequestedNssai)
+	_, remote := amf.Counters()
+	assert.Equal(t, uint32(1), remote) // continues after Registration Complete
+
+	inner, err := amf.DecodeMmContainer(nasMsg.Gmm.RegistrationRequest.NasMessageContainer)
+	require.NoError(t, err)
+	require.NotNil(t, inner.RegistrationRequest)
+	require.NotNil(t, inner.RegistrationRequest.RequestedNssai)
+	requested := inner.RegistrationRequest.RequestedNssai.List
+	require.Len(t, requested, 2)
+	assert.Equal(t, uint8(1), requested[0].Sst)
+	assert.Equal(t, "010203", requested[0].GetSd())
+	assert.Equal(t, uint8(2), requested[1].Sst)
+	assert.Empty(t, requested[1].GetSd())
+}
+
+// Test 4: without a state file, or with an invalid one, the UE registers with its SUCI
+func TestNasStateMissing(t *testing.T) {
+	dir := t.TempDir()
+	ue := createTestUe(t, config.UEConfig{StateDir: dir})
+	assert.Nil(t, ue.GutiForTest())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nas_imsi-999700000000001.json"), []byte("{"), 0o600))
+	ue = createTestUe(t, config.UEConfig{StateDir: dir})
+	assert.Nil(t, ue.GutiForTest())
+
+	nasPdu, err := ue.TriggerInitRegistration()
+	require.NoError(t, err)
+	nasMsg, err := nas.Decode(nil, nasPdu)
+	require.NoError(t, err)
+	require.NotNil(t, nasMsg.Gmm)
+	require.NotNil(t, nasMsg.Gmm.RegistrationRequest)
+	assert.Equal(t, nas.MobileIdentity5GSTypeSuci, nasMsg.Gmm.RegistrationRequest.MobileIdentity.GetType())
+}
+
+// Test 5: a state file with a 5G-GUTI but an invalid KAMF gives the 5G-GUTI without security context
+func TestNasStateInvalidKamf(t *testing.T) {
+	dir := t.TempDir()
+	state := `{"guti": "` + testGuti + `", "sqn": "000000000020", "security": {"ngksi": 1, "kamf": "00"}}`
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "nas_imsi-999700000000001.json"), []byte(state), 0o600))
+
+	ue := createTestUe(t, config.UEConfig{StateDir: dir})
+	require.NotNil(t, ue.GutiForTest())
+
+	// the registration falls back to the SUCI without a security context
+	nasPdu, err := ue.TriggerInitRegistration()
+	require.NoError(t, err)
+	nasMsg, err := nas.Decode(nil, nasPdu)
+	require.NoError(t, err)
+	require.NotNil(t, nasMsg.Gmm)
+	require.NotNil(t, nasMsg.Gmm.RegistrationRequest)
+	assert.Equal(t, nas.MobileIdentity5GSTypeSuci, nasMsg.Gmm.RegistrationRequest.MobileIdentity.GetType())
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+// timedUe creates a registered UE like securedUe, with the given NAS timer values
+func timedUe(t *testing.T, timers config.NasTimersConfig) (*uecontext.UeContext, *nas.NasContext) {
+	ue := createTestUe(t, config.UEConfig{NasTimers: timers})
+	ue.SendToDuChannel = make(chan []byte, 16)
+
+	var guti nas.Guti
+	require.NoError(t, guti.Parse(testGuti))
+	ue.SetRegisteredForTest(&guti)
+	ue.AddPduSessionForTest(1, ueIp, 1)
+	require.NoError(t, ue.SetSecurityContextForTest(testNgKsi, testKamf, testEncAlg, testIntAlg))
+	return ue, securedAmf(t)
+}
+
+// Test 1: GPRS timer 2 values
+func TestGprsTimer2(t *testing.T) {
+	tests := []struct {
+		value  uint8
+		want   time.Duration
+		active bool
+	}{
+		{0x00, 0, true},
+		{0x05, 10 * time.Second, true}, // 2 s units
+		{0x3f, 31 * time.Minute, true}, // 1 min units
+		{0x42, 12 * time.Minute, true}, // decihour units
+		{0xe1, 0, false},               // deactivated
+	}
+	for _, tt := range tests {
+		d, active := uecontext.GprsTimer2ForTest(&nas.GprsTimer2{Value: tt.value})
+		assert.Equal(t, tt.want, d, "value 0x%02x", tt.value)
+		assert.Equal(t, tt.active, active, "value 0x%02x", tt.value)
+	}
+}
+
+// Test 2: GPRS timer 3 values
+func TestGprsTimer3(t *testing.T) {
+	tests := []struct {
+		value  uint8
+		want   time.Duration
+		active bool
+	}{
+		{0x05, 50 * time.Minute, true}, // 10 min units
+		{0x21, time.Hour, true},        // 1 h units
+		{0x43, 30 * time.Hour, true},   // 10 h units
+		{0x7f, 62 * time.Second, true}, // 2 s units
+		{0x82, time.Minute, true},      // 30 s units
+		{0xa9, 9 * time.Minute, true},  // 1 min units
+		{0xc1, 320 * time.Hour, true},  // 320 h units
+		{0xe0, 0, false},               // deactivated
+		{0x60, 0, true},                // zero value, timer expires at once
+	}
+	for _, tt := range tests {
+		d, active := uecontext.GprsTimer3ForTest(&nas.GprsTimer3{Value: tt.value})
+		assert.Equal(t, tt.want, d, "value 0x%02x", tt.value)
+		assert.Equal(t, tt.active, active, "value 0x%02x", tt.value)
+	}
+}
+
+// Test 3: a registration the network does not answer is retried after T3511, the fifth failed
+// attempt deletes the 5G-GUTI and the registration is retried with the SUCI after T3502
+func TestRegistrationAttempts(t *testing.T) {
+	ue, amf := timedUe(t, config.NasTimersConfig{T3510: 100, T3511: 100, T3502: 600})
+	_, err := ue.TriggerInitRegistration()
+	require.NoError(t, err)
+
+	var last time.Time
+	for attempt := 1; attempt < uecontext.MAX_REGISTRATION_ATTEMPTS; attempt++ {
+		gmm := ulNas(t, ue, amf)
+		checkRegistrationRequest(t, gmm.RegistrationRequest, nas.RegistrationType5GSInitialRegistration)
+		last = time.Now()
+	}
+
+	gmm := ulNas(t, ue, nil)
+	assert.GreaterOrEqual(t, time.Since(last), 600*time.Millisecond)
+	require.NotNil(t, gmm.RegistrationRequest)
+	assert.Equal(t, nas.MobileIdentity5GSTypeSuci, gmm.RegistrationRequest.MobileIdentity.GetType())
+	assert.Nil(t, ue.GutiForTest())
+}
+
+// Test 4: the Deregistration Request is retransmitted on T3521 expiry, the fifth expiry aborts the
+// de-registration and the UE de-registers locally
+func TestDeregistrationRetransmission(t *testing.T) {
+	ue, amf := timedUe(t, config.NasTimersConfig{T3521: 100})
+	require.NoError(t, ue.TriggerDeregistration(false))
+
+	for i := 0; i < uecontext.MAX_DEREGISTRATION_ATTEMPTS; i++ {
+		gmm := ulNas(t, ue, amf)
+		require.NotNil(t, gmm.DeregistrationRequestFromUe)
+	}
+	assert.Eventually(t, func() bool {
+		return ue.GetState() == uecontext.UE_STATE_DEREGISTERED
+	}, time.Second, 20*time.Millisecond)
+	assert.Nil(t, ue.PduSessionForTest(1))
+
+	select {
+	case <-ue.SendToDuChannel:
+		assert.Fail(t, "Deregistration Request after the de-registration was aborted")
+	case <-time.After(300 * time.Millisecond):
+	}
+}
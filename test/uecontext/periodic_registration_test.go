@@ -0,0 +1,116 @@
+package test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+const (
+	t3512TwoSeconds  = 3<<5 | 1 // GPRS timer 3, 1 x 2 seconds
+	t3512Deactivated = 7 << 5
+)
+
+// registrationAccept returns a Registration Accept assigning the test 5G-GUTI, with T3512 and a
+// registration area of the given TACs of PLMN 999/70
+func registrationAccept(t *testing.T, t3512 uint8, tacs ...uint32) *nas.RegistrationAccept {
+	var guti nas.Guti
+	require.NoError(t, guti.Parse(testGuti))
+	accept := &nas.RegistrationAccept{
+		Guti:       &nas.MobileIdentity{Id: &guti},
+		T3512Value: &nas.GprsTimer3{Value: t3512},
+	}
+	accept.RegistrationResult.SetResult(1) // 3GPP access
+	if len(tacs) > 0 {
+		var plmn nas.PlmnId
+		require.NoError(t, plmn.Set("999", "70"))
+		accept.TaiList = &nas.TrackingAreaIdentityList{
+			Lists: []nas.TaiListInf{&nas.TaiListType0{PlmnId: plmn, TacList: tacs}},
+		}
+	}
+	return accept
+}
+
+// acceptRegistration registers the UE with a Registration Accept and checks its Registration Complete
+func acceptRegistration(t *testing.T, ue *uecontext.UeContext, amf *nas.NasContext, accept *nas.RegistrationAccept) {
+	ue.HandleNasMsg(dlNas(t, amf, accept))
+	gmm := ulNas(t, ue, amf)
+	require.NotNil(t, gmm.RegistrationComplete)
+	require.Equal(t, uecontext.UE_STATE_REGISTERED, ue.GetState())
+}
+
+// checkRegistrationRequest checks a Registration Request identified by the test 5G-GUTI
+func checkRegistrationRequest(t *testing.T, request *nas.RegistrationRequest, registrationType uint8) {
+	require.NotNil(t, request)
+	assert.Equal(t, registrationType, request.RegistrationType.GetType())
+	assert.Equal(t, nas.MobileIdentity5GSType5gGuti, request.MobileIdentity.GetType())
+	guti, ok := request.MobileIdentity.Id.(*nas.Guti)
+	require.True(t, ok)
+	assert.Equal(t, testGuti, guti.String())
+	assert.Equal(t, uint8(testNgKsi), request.Ngksi.Id)
+}
+
+// Test 1: T3512 of Registration Accept runs a periodic registration update in connected mode
+func TestPeriodicRegistrationConnected(t *testing.T) {
+	ue, amf := securedUe(t)
+	ue.SetState(uecontext.UE_STATE_REGISTERING)
+	acceptRegistration(t, ue, amf, registrationAccept(t, t3512TwoSeconds))
+
+	start := time.Now()
+	gmm := ulNas(t, ue, amf)
+	assert.GreaterOrEqual(t, time.Since(start), 1500*time.Millisecond)
+	assert.Equal(t, nas.NasSecBoth, gmm.SecHeader)
+	checkRegistrationRequest(t, gmm.RegistrationRequest, nas.RegistrationType5GSPeriodicRegistrationUpdating)
+}
+
+// Test 2: from RRC idle the periodic registration update goes on a new RRC connection, integrity
+// protected with the PDU session status in the ciphered NAS message container
+func TestPeriodicRegistrationIdle(t *testing.T) {
+	ue, amf := securedUe(t)
+	ue.SetState(uecontext.UE_STATE_REGISTERING)
+	acceptRegistration(t, ue, amf, registrationAccept(t, t3512TwoSeconds))
+	toIdle(t, ue)
+
+	_, gmm := initialNas(t, ue, amf)
+	assert.Equal(t, nas.NasSecIntegrity, gmm.SecHeader)
+	checkRegistrationRequest(t, gmm.RegistrationRequest, nas.RegistrationType5GSPeriodicRegistrationUpdating)
+	assert.Nil(t, gmm.RegistrationRequest.PduSessionStatus)
+
+	inner, err := amf.DecodeMmContainer(gmm.RegistrationRequest.NasMessageContainer)
+	require.NoError(t, err)
+	require.NotNil(t, inner.RegistrationRequest)
+	require.NotNil(t, inner.RegistrationRequest.PduSessionStatus)
+	assert.True(t, inner.RegistrationRequest.PduSessionStatus.Get()[1])
+}
+
+// Test 3: T3512 deactivated by the network, no periodic registration update
+func TestPeriodicRegistrationDeactivated(t *testing.T) {
+	ue, amf := securedUe(t)
+	ue.SetState(uecontext.UE_STATE_REGISTERING)
+	acceptRegistration(t, ue, amf, registrationAccept(t, t3512Deactivated))
+
+	select {
+	case <-ue.SendToDuChannel:
+		assert.Fail(t, "registration update with T3512 deactivated")
+	case <-time.After(2500 * time.Millisecond):
+	}
+}
+
+// Test 4: out of the registration area a mobility registration update is run on T3512 expiry
+func TestPeriodicRegistrationOutOfArea(t *testing.T) {
+	ue, amf := securedUe(t)
+	ue.SetServingCell(config.CellConfig{PCI: 1, TAC: "000001"})
+	ue.SetState(uecontext.UE_STATE_REGISTERING)
+	acceptRegistration(t, ue, amf, registrationAccept(t, t3512TwoSeconds, 1))
+	ue.SetServingCell(config.CellConfig{PCI: 2, TAC: "000002"})
+
+	gmm := ulNas(t, ue, amf)
+	checkRegistrationRequest(t, gmm.RegistrationRequest, nas.RegistrationType5GSMobilityRegistrationUpdating)
+}
@@ -0,0 +1,140 @@
+package test
+
+import (
+	"net"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"du_ue/internal/uecontext"
+)
+
+// qosRulesWire has two rules: rule 1 maps UL UDP to remote port 5000 to QFI 2 with precedence 10,
+// default rule 2 maps all packets to QFI 1 with precedence 255
+var qosRulesWire = []byte{
+	// rule 1, 10 octets: create with 1 packet filter
+	0x01, 0x00, 0x0a, 0x21,
+	// filter 1 UL: protocol UDP, remote port 5000
+	0x21, 0x05, 0x30, 0x11, 0x50, 0x13, 0x88,
+	// precedence 10, QFI 2
+	0x0a, 0x02,
+	// rule 2, 6 octets: create default rule with 1 packet filter
+	0x02, 0x00, 0x06, 0x31,
+	// filter 1 bidirectional: match all
+	0x31, 0x01, 0x01,
+	// precedence 255, QFI 1
+	0xff, 0x01,
+}
+
+// Test 1: QoS rules IE with a default rule
+func TestDecodeQosRules(t *testing.T) {
+	rules, err := uecontext.DecodeQosRulesForTest(qosRulesWire)
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+
+	assert.Equal(t, uecontext.QosRule{
+		Id:         1,
+		OpCode:     uecontext.QOS_RULE_OP_CREATE,
+		Precedence: 10,
+		Qfi:        2,
+		Filters: []uecontext.PacketFilter{{
+			Id:        1,
+			Direction: uecontext.PACKET_FILTER_UL,
+			Components: []uecontext.PacketFilterComponent{
+				{Type: uecontext.PF_PROTOCOL_ID, Value: []byte{uecontext.IP_PROTO_UDP}},
+				{Type: uecontext.PF_SINGLE_REMOTE_PORT, Value: []byte{0x13, 0x88}},
+			},
+		}},
+	}, rules[0])
+	assert.Equal(t, uecontext.QosRule{
+		Id:         2,
+		OpCode:     uecontext.QOS_RULE_OP_CREATE,
+		Default:    true,
+		Precedence: 255,
+		Qfi:        1,
+		Filters: []uecontext.PacketFilter{{
+			Id:         1,
+			Direction:  uecontext.PACKET_FILTER_BIDIRECTIONAL,
+			Components: []uecontext.PacketFilterComponent{{Type: uecontext.PF_MATCH_ALL, Value: []byte{}}},
+		}},
+	}, rules[1])
+}
+
+// Test 2: QoS rules deleting a rule and packet filters
+func TestDecodeQosRulesModification(t *testing.T) {
+	rules, err := uecontext.DecodeQosRulesForTest([]byte{
+		0x03, 0x00, 0x01, 0x40, // delete rule 3
+		0x01, 0x00, 0x05, 0xa2, 0x01, 0x02, 0x0a, 0x02, // delete filters 1 and 2 of rule 1
+	})
+	require.NoError(t, err)
+	require.Len(t, rules, 2)
+	assert.Equal(t, uecontext.QosRule{Id: 3, OpCode: uecontext.QOS_RULE_OP_DELETE}, rules[0])
+	assert.Equal(t, uint8(uecontext.QOS_RULE_OP_MODIFY_DELETE_FILTERS), rules[1].OpCode)
+	assert.Equal(t, []uint8{1, 2}, rules[1].FilterIds)
+	assert.Equal(t, uint8(2), rules[1].Qfi)
+}
+
+// Test 3: Invalid QoS rules IEs
+func TestDecodeQosRulesErrors(t *testing.T) {
+	for name, wire := range map[string][]byte{
+		"header truncated":           {0x01, 0x00},
+		"rule truncated":             {0x01, 0x00, 0x05, 0x21},
+		"empty rule":                 {0x01, 0x00, 0x00},
+		"unknown operation":          {0x01, 0x00, 0x03, 0xe0, 0x0a, 0x01},
+		"filter truncated":           {0x01, 0x00, 0x04, 0x21, 0x21, 0x05, 0x30},
+		"unknown component":          {0x01, 0x00, 0x06, 0x21, 0x21, 0x01, 0x99, 0x0a, 0x01},
+		"component truncated":        {0x01, 0x00, 0x06, 0x21, 0x21, 0x01, 0x50, 0x0a, 0x01},
+		"precedence and QFI missing": {0x01, 0x00, 0x04, 0x21, 0x21, 0x01, 0x01},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := uecontext.DecodeQosRulesForTest(wire)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// Test 4: UL packets are mapped to the QFI of the first matching rule by precedence
+func TestClassifyUl(t *testing.T) {
+	ueIP, server := net.IPv4(10, 60, 0, 1).To4(), net.IPv4(10, 0, 0, 1).To4()
+	udp := func(dport uint16) []byte {
+		return ipv4(ueIP, server, uecontext.IP_PROTO_UDP, []byte{0x9c, 0x40, byte(dport >> 8), byte(dport), 0x00, 0x0c, 0x00, 0x00, 'p', 'i', 'n', 'g'})
+	}
+	icmp := ipv4(ueIP, server, uecontext.IP_PROTO_ICMP, []byte{0x08, 0x00, 0x00, 0x00, 0x00, 0x01, 0x00, 0x01, 'p', 'i', 'n', 'g'})
+
+	rules, err := uecontext.DecodeQosRulesForTest(qosRulesWire)
+	require.NoError(t, err)
+
+	// rules are evaluated by precedence whatever their order in the IE
+	ps := &uecontext.PduSession{}
+	ps.ApplyQosRulesForTest([]uecontext.QosRule{rules[1], rules[0]})
+	tests := []struct {
+		name   string
+		packet []byte
+		qfi    uint8
+		ok     bool
+	}{
+		{"UDP to port 5000", udp(5000), 2, true},
+		{"UDP to another port", udp(5001), 1, true},
+		{"ICMP", icmp, 1, true},
+		{"not IPv4", []byte{0x60, 0x00, 0x00, 0x00}, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			qfi, ok := ps.ClassifyUlForTest(tt.packet)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.qfi, qfi)
+		})
+	}
+
+	// without the default rule, packets no filter matches are discarded
+	ps = &uecontext.PduSession{}
+	ps.ApplyQosRulesForTest(rules[:1])
+	_, ok := ps.ClassifyUlForTest(icmp)
+	assert.False(t, ok)
+
+	// a session without QoS rules sends everything with QFI 0
+	qfi, ok := (&uecontext.PduSession{}).ClassifyUlForTest(icmp)
+	assert.True(t, ok)
+	assert.Zero(t, qfi)
+}
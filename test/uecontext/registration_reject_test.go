@@ -0,0 +1,103 @@
+package test
+
+import (
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+// Test 1: the 5GMM cause of a Registration Reject decides whether the UE deletes its 5G-GUTI and
+// whether the registration is retried
+func TestRegistrationReject(t *testing.T) {
+	tests := []struct {
+		name         string
+		reject       *nas.RegistrationReject
+		dropIdentity bool
+		retry        bool
+		reregister   bool
+		wait         time.Duration // minimum wait before the retry
+	}{
+		{"illegal UE", &nas.RegistrationReject{GmmCause: nas.Cause5GMMIllegalUE}, true, false, false, 0},
+		{"PLMN not allowed", &nas.RegistrationReject{GmmCause: nas.Cause5GMMPLMNNotAllowed}, true, false, false, 0},
+		{"tracking area not allowed", &nas.RegistrationReject{GmmCause: nas.Cause5GMMTrackingAreaNotAllowed}, true, false, false, 0},
+		{"redirection to EPC required", &nas.RegistrationReject{GmmCause: uecontext.CAUSE_5GMM_REDIRECTION_TO_EPC_REQUIRED}, false, false, false, 0},
+		{"UE identity cannot be derived", &nas.RegistrationReject{GmmCause: nas.Cause5GMMUEIdentityCannotBeDerivedByTheNetwork}, true, false, true, 0},
+		{"protocol error", &nas.RegistrationReject{GmmCause: nas.Cause5GMMProtocolErrorUnspecified}, false, true, false, 0},
+		{"congestion without T3346", &nas.RegistrationReject{GmmCause: nas.Cause5GMMCongestion}, false, true, false, 0},
+		{"congestion with T3346", &nas.RegistrationReject{
+			GmmCause:   nas.Cause5GMMCongestion,
+			T3346Value: &nas.GprsTimer2{Value: 0x01}, // 2 s
+		}, false, true, false, 1500 * time.Millisecond},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ue, amf := timedUe(t, config.NasTimersConfig{T3511: 200})
+			_, err := ue.TriggerInitRegistration()
+			require.NoError(t, err)
+
+			start := time.Now()
+			ue.HandleNasMsg(dlNas(t, amf, tt.reject))
+
+			assert.Equal(t, uecontext.UE_STATE_DEREGISTERED, ue.GetState())
+			if tt.dropIdentity {
+				assert.Nil(t, ue.GutiForTest())
+			} else {
+				assert.NotNil(t, ue.GutiForTest())
+			}
+			if tt.reregister {
+				assert.Equal(t, nas.RegistrationType5GSInitialRegistration, ue.PendingRegistrationForTest())
+			} else {
+				assert.Zero(t, ue.PendingRegistrationForTest())
+			}
+
+			if !tt.retry {
+				select {
+				case <-ue.SendToDuChannel:
+					assert.Fail(t, "registration retried")
+				case <-time.After(500 * time.Millisecond):
+				}
+				return
+			}
+			gmm := ulNas(t, ue, amf)
+			assert.GreaterOrEqual(t, time.Since(start), tt.wait)
+			checkRegistrationRequest(t, gmm.RegistrationRequest, nas.RegistrationType5GSInitialRegistration)
+		})
+	}
+}
+
+// Test 2: no network slices available, the rejected S-NSSAIs are removed from the NSSAI the UE
+// requests and the registration is not retried
+func TestRegistrationRejectNoSlices(t *testing.T) {
+	dir := t.TempDir()
+	ue, amf := storedUe(t, dir)
+	_, err := ue.TriggerInitRegistration()
+	require.NoError(t, err)
+
+	sd, _ := hex.DecodeString("010203")
+	reject := &nas.RegistrationReject{
+		GmmCause:      uecontext.CAUSE_5GMM_NO_NETWORK_SLICES_AVAILABLE,
+		RejectedNssai: &nas.RejectedNssai{List: []nas.RejectedSnssai{{Sst: 1, Sd: sd}}},
+	}
+	ue.HandleNasMsg(dlNas(t, amf, reject))
+
+	assert.Equal(t, uecontext.UE_STATE_DEREGISTERED, ue.GetState())
+	assert.NotNil(t, ue.GutiForTest())
+	state := readState(t, dir)
+	require.Len(t, state.AllowedNssai, 1)
+	assert.Equal(t, uint8(2), state.AllowedNssai[0].Sst)
+
+	select {
+	case <-ue.SendToDuChannel:
+		assert.Fail(t, "registration retried")
+	case <-time.After(500 * time.Millisecond):
+	}
+}
@@ -0,0 +1,244 @@
+package test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/lvdund/asn1go/aper"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+	"du_ue/internal/uecontext/sec"
+	"du_ue/pkg/config"
+)
+
+const (
+	testNgKsi  = 1
+	testEncAlg = 2 // 128-NEA2
+	testIntAlg = 2 // 128-NIA2
+)
+
+var testKamf = bytes.Repeat([]byte{0x5a}, 32)
+
+// securedUe creates a registered UE with a native NAS security context, and the NAS context of the
+// AMF for the other end
+func securedUe(t *testing.T) (*uecontext.UeContext, *nas.NasContext) {
+	ue := registeredUe(t)
+	require.NoError(t, ue.SetSecurityContextForTest(testNgKsi, testKamf, testEncAlg, testIntAlg))
+	return ue, securedAmf(t)
+}
+
+// securedAmf returns the NAS context of the AMF for the security context of securedUe
+func securedAmf(t *testing.T) *nas.NasContext {
+	amf := sec.NewSecurityContext(&nas.KeySetIdentifier{Id: testNgKsi}, testKamf, true)
+	require.NoError(t, amf.DeriveNasKeys(testEncAlg, testIntAlg, sec.HDP_NONE))
+	return amf.NasContext(true)
+}
+
+// dlNas encodes a NAS message of the AMF, integrity protected and ciphered
+func dlNas(t *testing.T, amf *nas.NasContext, msg interface {
+	nas.GmmMessage
+	SetSecurityHeader(uint8)
+}) []byte {
+	msg.SetSecurityHeader(nas.NasSecBoth)
+	pdu, err := nas.EncodeMm(amf, msg)
+	require.NoError(t, err)
+	return pdu
+}
+
+func rrcRelease() []byte {
+	msg := rrcies.DL_DCCH_Message{
+		Message: rrcies.DL_DCCH_MessageType{
+			Choice: rrcies.DL_DCCH_MessageType_Choice_C1,
+			C1: &rrcies.DL_DCCH_MessageType_C1{
+				Choice: rrcies.DL_DCCH_MessageType_C1_Choice_RrcRelease,
+				RrcRelease: &rrcies.RRCRelease{
+					CriticalExtensions: rrcies.RRCRelease_CriticalExtensions{
+						Choice:     rrcies.RRCRelease_CriticalExtensions_Choice_RrcRelease,
+						RrcRelease: &rrcies.RRCRelease_IEs{},
+					},
+				},
+			},
+		},
+	}
+	encoded, _ := rrc.Encode(&msg)
+	return encoded
+}
+
+func rrcSetup() []byte {
+	msg := rrcies.DL_CCCH_Message{
+		Message: rrcies.DL_CCCH_MessageType{
+			Choice: rrcies.DL_CCCH_MessageType_Choice_C1,
+			C1: &rrcies.DL_CCCH_MessageType_C1{
+				Choice: rrcies.DL_CCCH_MessageType_C1_Choice_RrcSetup,
+				RrcSetup: &rrcies.RRCSetup{
+					CriticalExtensions: rrcies.RRCSetup_CriticalExtensions{
+						Choice: rrcies.RRCSetup_CriticalExtensions_Choice_RrcSetup,
+						RrcSetup: &rrcies.RRCSetup_IEs{
+							MasterCellGroup: aper.OctetString{0x00},
+						},
+					},
+				},
+			},
+		},
+	}
+	encoded, _ := rrc.Encode(&msg)
+	return encoded
+}
+
+// toIdle releases the RRC connection of the UE, DL-CCCH messages then go to the RRC connection
+// establishment
+func toIdle(t *testing.T, ue *uecontext.UeContext) {
+	require.NoError(t, ue.HandleRrcMsg(rrcRelease()))
+	ue.ReceiveFromDuChannel = make(chan []byte, 16)
+	ue.StartRrcListenerForTest()
+}
+
+// Test 1: a service request needs a registered UE with a 5G-GUTI and a security context
+func TestServiceRequestErrors(t *testing.T) {
+	ue := createTestUe(t, config.UEConfig{})
+	assert.Error(t, ue.TriggerServiceRequest(nas.ServiceTypeData))
+
+	ue = registeredUe(t)
+	assert.Error(t, ue.TriggerServiceRequest(nas.ServiceTypeData))
+}
+
+// Test 2: from RRC idle, RRCSetupRequest with the 5G-S-TMSI, then the Service Request in
+// RRCSetupComplete with the status IEs in the ciphered NAS message container
+func TestServiceRequestIdle(t *testing.T) {
+	ue, amf := securedUe(t)
+	toIdle(t, ue)
+
+	errC := make(chan error, 1)
+	go func() { errC <- ue.TriggerServiceRequest(nas.ServiceTypeData) }()
+
+	var guti nas.Guti
+	require.NoError(t, guti.Parse(testGuti))
+	sTmsi := uint64(guti.AmfId.GetSet())<<38 | uint64(guti.AmfId.GetPointer())<<32 | uint64(guti.Tmsi)
+
+	var request rrcies.UL_CCCH_Message
+	require.NoError(t, rrc.Decode(ulRrc(t, ue), &request))
+	require.NotNil(t, request.Message.C1)
+	require.NotNil(t, request.Message.C1.RrcSetupRequest)
+	ies := request.Message.C1.RrcSetupRequest.RrcSetupRequest
+	assert.Equal(t, rrcies.InitialUE_Identity_Choice_Ng_5G_S_TMSI_Part1, ies.Ue_Identity.Choice)
+	assert.Equal(t, uint64(39), ies.Ue_Identity.Ng_5G_S_TMSI_Part1.NumBits)
+	assert.Equal(t, aper.Enumerated(rrcies.EstablishmentCause_Enum_mo_Data), ies.EstablishmentCause.Value)
+
+	ue.ReceiveFromDuChannel <- rrcSetup()
+
+	var complete rrcies.UL_DCCH_Message
+	require.NoError(t, rrc.Decode(ulRrc(t, ue), &complete))
+	require.NotNil(t, complete.Message.C1)
+	require.NotNil(t, complete.Message.C1.RrcSetupComplete)
+	setupComplete := complete.Message.C1.RrcSetupComplete.CriticalExtensions.RrcSetupComplete
+	require.NotNil(t, setupComplete)
+	require.NotNil(t, setupComplete.Ng_5G_S_TMSI_Value)
+	part2 := setupComplete.Ng_5G_S_TMSI_Value.Ng_5G_S_TMSI_Part2
+	assert.Equal(t, uint64(9), part2.NumBits)
+	assert.Equal(t, byte(sTmsi>>39>>1), part2.Bytes[0])
+	require.NoError(t, <-errC)
+
+	nasMsg, err := nas.Decode(amf, setupComplete.DedicatedNAS_Message.Value)
+	require.NoError(t, err)
+	require.NotNil(t, nasMsg.Gmm)
+	assert.False(t, nasMsg.Gmm.MacFailed)
+	assert.Equal(t, nas.NasSecIntegrity, nasMsg.Gmm.SecHeader)
+	serviceRequest := nasMsg.Gmm.ServiceRequest
+	require.NotNil(t, serviceRequest)
+	assert.Equal(t, nas.ServiceTypeData, serviceRequest.ServiceType)
+	assert.Nil(t, serviceRequest.PduSessionStatus)
+	assert.Nil(t, serviceRequest.UplinkDataStatus)
+
+	inner, err := amf.DecodeMmContainer(serviceRequest.NasMessageContainer)
+	require.NoError(t, err)
+	require.NotNil(t, inner.ServiceRequest)
+	require.NotNil(t, inner.ServiceRequest.PduSessionStatus)
+	require.NotNil(t, inner.ServiceRequest.UplinkDataStatus)
+	assert.True(t, inner.ServiceRequest.PduSessionStatus.Get()[1])
+	assert.True(t, inner.ServiceRequest.UplinkDataStatus.Get()[1])
+}
+
+// Test 3: in RRC connected mode the Service Request goes in an UL Information Transfer, without
+// uplink data status for signalling
+func TestServiceRequestConnected(t *testing.T) {
+	ue, amf := securedUe(t)
+
+	require.NoError(t, ue.TriggerServiceRequest(nas.ServiceTypeSignalling))
+
+	gmm := ulNas(t, ue, amf)
+	require.NotNil(t, gmm.ServiceRequest)
+	assert.Equal(t, nas.ServiceTypeSignalling, gmm.ServiceRequest.ServiceType)
+
+	inner, err := amf.DecodeMmContainer(gmm.ServiceRequest.NasMessageContainer)
+	require.NoError(t, err)
+	require.NotNil(t, inner.ServiceRequest)
+	assert.NotNil(t, inner.ServiceRequest.PduSessionStatus)
+	assert.Nil(t, inner.ServiceRequest.UplinkDataStatus)
+}
+
+// Test 4: Service Accept, the PDU sessions the network does not have any more are released locally
+func TestServiceAccept(t *testing.T) {
+	ue, amf := securedUe(t)
+	ue.AddPduSessionForTest(2, ueIp, 2)
+	require.NoError(t, ue.TriggerServiceRequest(nas.ServiceTypeData))
+	ulNas(t, ue, amf)
+
+	accept := &nas.ServiceAccept{PduSessionStatus: &nas.PduSessionStatus{}}
+	var status [16]bool
+	status[2] = true
+	accept.PduSessionStatus.Set(status)
+	ue.HandleNasMsg(dlNas(t, amf, accept))
+
+	assert.Equal(t, uecontext.UE_STATE_REGISTERED, ue.GetState())
+	assert.Nil(t, ue.PduSessionForTest(1))
+	assert.NotNil(t, ue.PduSessionForTest(2))
+}
+
+// Test 5: the 5GMM cause of a Service Reject decides whether the UE registers again
+func TestServiceReject(t *testing.T) {
+	tests := []struct {
+		name         string
+		cause        uint8
+		state        uint8
+		dropIdentity bool
+		reregister   bool
+	}{
+		{"UE identity cannot be derived", nas.Cause5GMMUEIdentityCannotBeDerivedByTheNetwork, uecontext.UE_STATE_DEREGISTERED, true, true},
+		{"implicitly de-registered", nas.Cause5GMMImplicitlyDeregistered, uecontext.UE_STATE_DEREGISTERED, false, true},
+		{"illegal UE", nas.Cause5GMMIllegalUE, uecontext.UE_STATE_DEREGISTERED, true, false},
+		{"congestion", nas.Cause5GMMCongestion, uecontext.UE_STATE_REGISTERED, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ue, amf := securedUe(t)
+			require.NoError(t, ue.TriggerServiceRequest(nas.ServiceTypeData))
+			ulNas(t, ue, amf)
+
+			ue.HandleNasMsg(dlNas(t, amf, &nas.ServiceReject{GmmCause: tt.cause}))
+
+			assert.Equal(t, tt.state, ue.GetState())
+			if tt.dropIdentity {
+				assert.Nil(t, ue.GutiForTest())
+			} else {
+				assert.NotNil(t, ue.GutiForTest())
+			}
+			if tt.state == uecontext.UE_STATE_DEREGISTERED {
+				assert.Nil(t, ue.PduSessionForTest(1))
+			} else {
+				assert.NotNil(t, ue.PduSessionForTest(1))
+			}
+			if tt.reregister {
+				assert.Equal(t, nas.RegistrationType5GSInitialRegistration, ue.PendingRegistrationForTest())
+			} else {
+				assert.Zero(t, ue.PendingRegistrationForTest())
+			}
+		})
+	}
+}
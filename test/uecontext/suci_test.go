@@ -0,0 +1,103 @@
+package test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/reogac/nas"
+
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+// registrationSuci returns the SUCI of the plain initial Registration Request of the UE
+func registrationSuci(t *testing.T, ue *uecontext.UeContext) *nas.SupiImsi {
+	nasPdu, err := ue.TriggerInitRegistration()
+	require.NoError(t, err)
+	nasMsg, err := nas.Decode(nil, nasPdu)
+	require.NoError(t, err)
+	require.NotNil(t, nasMsg.Gmm)
+	require.NotNil(t, nasMsg.Gmm.RegistrationRequest)
+	suci, ok := nasMsg.Gmm.RegistrationRequest.MobileIdentity.Id.(*nas.Suci)
+	require.True(t, ok)
+	imsi, ok := suci.Content.(*nas.SupiImsi)
+	require.True(t, ok)
+	return imsi
+}
+
+// Test 1: with an ECIES profile the MSIN is concealed with the configured key ID and a fresh
+// ephemeral key for each registration
+func TestSuciConcealed(t *testing.T) {
+	tests := []struct {
+		name   string
+		scheme uint8
+		key    string
+		keyLen int
+	}{
+		{"Profile A", nas.ProtectionSchemeECIESProfileA, "5a8d38864820197c3394b92613b20b91633cbd897119273bf8e4a6f4eec0a650", 32},
+		{"Profile B", nas.ProtectionSchemeECIESProfileB, "0272da71976234ce833a6907425867b82e074d44ef907dfb4b3e21c1c2256ebcd1", 33},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ue := createTestUe(t, config.UEConfig{
+				ProtectionScheme:       int(tt.scheme),
+				HomeNetworkPublicKey:   tt.key,
+				HomeNetworkPublicKeyID: 3,
+			})
+
+			first := registrationSuci(t, ue)
+			assert.Equal(t, tt.scheme, first.ProtectionScheme)
+			assert.Equal(t, uint8(3), first.HomeNetworkPublicKeyId)
+			// ephemeral public key, 5 octets of MSIN and the MAC tag
+			require.Len(t, first.SchemeOutput, tt.keyLen+5+8)
+
+			second := registrationSuci(t, ue)
+			assert.NotEqual(t, first.SchemeOutput[:tt.keyLen], second.SchemeOutput[:tt.keyLen])
+		})
+	}
+}
+
+// Test 2: the null scheme sends the MSIN
+func TestSuciNullScheme(t *testing.T) {
+	ue := createTestUe(t, config.UEConfig{})
+
+	suci := registrationSuci(t, ue)
+	assert.Equal(t, nas.ProtectionSchemeNullScheme, suci.ProtectionScheme)
+	msin, err := nas.ParseMsin("0000000001")
+	require.NoError(t, err)
+	assert.Equal(t, msin, suci.SchemeOutput)
+}
+
+// Test 3: a home network public key that is not usable fails the creation of the UE
+func TestSuciInvalidKey(t *testing.T) {
+	tests := []struct {
+		name   string
+		scheme uint8
+		key    string
+	}{
+		{"not hex", nas.ProtectionSchemeECIESProfileA, "zz"},
+		{"Profile A key length", nas.ProtectionSchemeECIESProfileA, "5a8d3886"},
+		{"Profile B x out of the field", nas.ProtectionSchemeECIESProfileB, "02" + strings.Repeat("ff", 32)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := config.UEConfig{
+				PLMN:                 config.PLMNConfig{MCC: "999", MNC: "70"},
+				MSIN:                 "0000000001",
+				Key:                  "00112233445566778899aabbccddeeff",
+				OPC:                  "00112233445566778899aabbccddeeff",
+				AMF:                  "8000",
+				ProtectionScheme:     int(tt.scheme),
+				HomeNetworkPublicKey: tt.key,
+			}
+			_, err := uecontext.CreateUe(conf, context.Background())
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,271 @@
+package test
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+var (
+	ueIp = net.IPv4(10, 45, 0, 2).To4()
+	dnIp = net.IPv4(192, 168, 1, 1).To4()
+)
+
+// createTestUe creates a UE of PLMN 999/70, stopped with the test
+func createTestUe(t *testing.T, conf config.UEConfig) *uecontext.UeContext {
+	conf.PLMN = config.PLMNConfig{MCC: "999", MNC: "70"}
+	if conf.MSIN == "" {
+		conf.MSIN = "0000000001"
+	}
+	conf.Key = "00112233445566778899aabbccddeeff"
+	conf.OPC = "00112233445566778899aabbccddeeff"
+	conf.AMF = "8000"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	ue, err := uecontext.CreateUe(conf, ctx)
+	require.NoError(t, err)
+	return ue
+}
+
+// dataNetwork answers the UL packets of the UE on its DRB like the hosts of the data network do
+type dataNetwork struct {
+	ue        *uecontext.UeContext
+	drbId     int64
+	drop      func(seq int) bool // UL packets not answered, by arrival order
+	received  atomic.Int64
+	badChecks atomic.Int64
+}
+
+// startUserPlane sets up PDU session 1 of the UE on DRB 1 with a data network behind it
+func startUserPlane(t *testing.T, ue *uecontext.UeContext) *dataNetwork {
+	ue.SendDataToDuChannel = make(chan uecontext.DrbPdu, 1024)
+	ue.ReceiveDataFromDuChannel = make(chan uecontext.DrbPdu, 1024)
+	ue.AddPduSessionForTest(1, ueIp, 1)
+	ue.StartUserPlaneForTest()
+
+	dn := &dataNetwork{ue: ue, drbId: 1}
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	go dn.run(ctx)
+	return dn
+}
+
+func (dn *dataNetwork) run(ctx context.Context) {
+	var tcpNxt uint32
+	for {
+		var pdu uecontext.DrbPdu
+		select {
+		case pdu = <-dn.ue.SendDataToDuChannel:
+		case <-ctx.Done():
+			return
+		}
+		seq := int(dn.received.Add(1)) - 1
+		b := pdu.Data
+		if len(b) < 20 || checksum(b[:20], 0) != 0 {
+			dn.badChecks.Add(1)
+			continue
+		}
+		src, dst, proto, payload := net.IP(b[12:16]), net.IP(b[16:20]), b[9], b[20:]
+		if pseudo := pseudoHeader(src, dst, proto, len(payload)); proto != 1 && checksum(payload, pseudo) != 0 {
+			dn.badChecks.Add(1)
+			continue
+		}
+		if dn.drop != nil && dn.drop(seq) {
+			continue
+		}
+
+		var reply []byte
+		switch proto {
+		case 1: // echo request -> echo reply
+			reply = append([]byte{0, 0, 0, 0}, payload[4:]...)
+		case 17: // echo the datagram back
+			reply = append([]byte{}, payload...)
+			copy(reply[0:], payload[2:4])
+			copy(reply[2:], payload[0:2])
+		case 6:
+			flags := payload[13]
+			segSeq := binary.BigEndian.Uint32(payload[4:])
+			dataLen := uint32(len(payload) - int(payload[12]>>4)*4)
+			answer := byte(0x10)
+			switch {
+			case flags&0x02 != 0:
+				tcpNxt = segSeq + 1
+				answer = 0x12
+			case flags&0x01 != 0:
+				tcpNxt = segSeq + 1
+				answer = 0x11
+			case dataLen > 0 && segSeq == tcpNxt:
+				tcpNxt += dataLen
+			case dataLen == 0:
+				continue
+			}
+			reply = make([]byte, 20)
+			copy(reply[0:], payload[2:4])
+			copy(reply[2:], payload[0:2])
+			binary.BigEndian.PutUint32(reply[4:], 5000)
+			if answer&0x02 != 0 || answer&0x01 != 0 {
+				binary.BigEndian.PutUint32(reply[4:], 4999)
+			}
+			binary.BigEndian.PutUint32(reply[8:], tcpNxt)
+			reply[12] = 5 << 4
+			reply[13] = answer
+		default:
+			continue
+		}
+		dn.ue.ReceiveDataFromDuChannel <- uecontext.DrbPdu{DrbId: dn.drbId, Data: ipv4(dst, src, proto, reply)}
+	}
+}
+
+func ipv4(src, dst net.IP, proto uint8, payload []byte) []byte {
+	b := make([]byte, 20, 20+len(payload))
+	b[0] = 0x45
+	binary.BigEndian.PutUint16(b[2:], uint16(20+len(payload)))
+	b[8] = 64
+	b[9] = proto
+	copy(b[12:], src)
+	copy(b[16:], dst)
+	binary.BigEndian.PutUint16(b[10:], checksum(b, 0))
+	return append(b, payload...)
+}
+
+func pseudoHeader(src, dst net.IP, proto uint8, length int) uint32 {
+	var sum uint32
+	for _, ip := range []net.IP{src, dst} {
+		sum += uint32(ip[0])<<8 | uint32(ip[1])
+		sum += uint32(ip[2])<<8 | uint32(ip[3])
+	}
+	return sum + uint32(proto) + uint32(length)
+}
+
+// checksum returns the Internet checksum of b, 0 when b includes a valid checksum
+func checksum(b []byte, sum uint32) uint16 {
+	for i := 0; i+1 < len(b); i += 2 {
+		sum += uint32(b[i])<<8 | uint32(b[i+1])
+	}
+	if len(b)%2 == 1 {
+		sum += uint32(b[len(b)-1]) << 8
+	}
+	for sum>>16 != 0 {
+		sum = sum&0xffff + sum>>16
+	}
+	return ^uint16(sum)
+}
+
+// Test 1: ICMP echo requests answered by the destination
+func TestTrafficIcmp(t *testing.T) {
+	ue := createTestUe(t, config.UEConfig{})
+	dn := startUserPlane(t, ue)
+
+	stats, err := ue.RunTraffic(1, config.TrafficConfig{Type: uecontext.TRAFFIC_ICMP, Dst: dnIp.String(), Count: 4, Interval: 10, Size: 32})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(4), stats.PacketsSent)
+	assert.Equal(t, uint64(4), stats.PacketsReceived)
+	assert.Equal(t, uint64(0), stats.PacketsLost)
+	assert.Equal(t, uint64(4*40), stats.BytesSent)
+	assert.Positive(t, stats.RttMax)
+	assert.LessOrEqual(t, stats.RttMin, stats.RttAvg)
+	assert.LessOrEqual(t, stats.RttAvg, stats.RttMax)
+	assert.Zero(t, dn.badChecks.Load())
+}
+
+// Test 2: Unanswered echo requests are lost
+func TestTrafficIcmpLoss(t *testing.T) {
+	ue := createTestUe(t, config.UEConfig{})
+	dn := startUserPlane(t, ue)
+	dn.drop = func(seq int) bool { return seq%2 == 1 }
+
+	stats, err := ue.RunTraffic(1, config.TrafficConfig{Type: uecontext.TRAFFIC_ICMP, Dst: dnIp.String(), Count: 4, Interval: 10})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), stats.PacketsReceived)
+	assert.Equal(t, uint64(2), stats.PacketsLost)
+	assert.Equal(t, 50.0, stats.LossRate())
+}
+
+// Test 3: UDP at a constant bitrate echoed back by the destination
+func TestTrafficUdp(t *testing.T) {
+	ue := createTestUe(t, config.UEConfig{})
+	dn := startUserPlane(t, ue)
+
+	// 128 byte IP packets at 1024 kbit/s: one every ms
+	stats, err := ue.RunTraffic(1, config.TrafficConfig{Type: uecontext.TRAFFIC_UDP, Dst: dnIp.String(), Port: 5001, Count: 20, Size: 100, Rate: 1024})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(20), stats.PacketsSent)
+	assert.Equal(t, uint64(20), stats.PacketsReceived)
+	assert.Equal(t, uint64(0), stats.PacketsLost)
+	assert.Equal(t, uint64(20*108), stats.BytesSent)
+	assert.Equal(t, uint64(20*108), stats.BytesReceived)
+	assert.GreaterOrEqual(t, stats.Duration, 19*time.Millisecond)
+	assert.Zero(t, dn.badChecks.Load())
+}
+
+// Test 4: TCP upload acknowledged by the destination
+func TestTrafficTcp(t *testing.T) {
+	ue := createTestUe(t, config.UEConfig{})
+	dn := startUserPlane(t, ue)
+
+	stats, err := ue.RunTraffic(1, config.TrafficConfig{Type: uecontext.TRAFFIC_TCP, Dst: dnIp.String(), Port: 5001, Bytes: 10000, Size: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(10000), stats.BytesSent)
+	assert.Equal(t, uint64(0), stats.PacketsLost)
+	assert.Positive(t, stats.UlThroughput())
+	assert.Zero(t, dn.badChecks.Load())
+}
+
+// Test 5: A lost TCP segment is retransmitted after the timeout
+func TestTrafficTcpRetransmission(t *testing.T) {
+	ue := createTestUe(t, config.UEConfig{})
+	dn := startUserPlane(t, ue)
+	// SYN, ACK, then the first data segment is lost
+	dn.drop = func(seq int) bool { return seq == 2 }
+
+	stats, err := ue.RunTraffic(1, config.TrafficConfig{Type: uecontext.TRAFFIC_TCP, Dst: dnIp.String(), Port: 5001, Bytes: 3000, Size: 1000})
+	require.NoError(t, err)
+	assert.Equal(t, uint64(3000), stats.BytesSent)
+	assert.Equal(t, uint64(1), stats.PacketsLost)
+}
+
+// Test 6: Traffic needs an active PDU session with an IPv4 address, one run at a time
+func TestTrafficErrors(t *testing.T) {
+	ue := createTestUe(t, config.UEConfig{})
+
+	_, err := ue.RunTraffic(1, config.TrafficConfig{Type: uecontext.TRAFFIC_ICMP, Dst: dnIp.String()})
+	assert.Error(t, err, "no PDU session")
+
+	startUserPlane(t, ue)
+	_, err = ue.RunTraffic(1, config.TrafficConfig{Type: uecontext.TRAFFIC_ICMP, Dst: "2001:db8::1"})
+	assert.Error(t, err, "IPv6 destination")
+	_, err = ue.RunTraffic(1, config.TrafficConfig{Type: "sctp", Dst: dnIp.String()})
+	assert.Error(t, err, "unknown type")
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ue.RunTraffic(1, config.TrafficConfig{Type: uecontext.TRAFFIC_ICMP, Dst: dnIp.String(), Count: 2, Interval: 200})
+	}()
+	time.Sleep(50 * time.Millisecond)
+	_, err = ue.RunTraffic(1, config.TrafficConfig{Type: uecontext.TRAFFIC_ICMP, Dst: dnIp.String()})
+	assert.Error(t, err, "traffic already running")
+	<-done
+}
+
+// Test 7: Throughput and loss rate of the statistics
+func TestTrafficStats(t *testing.T) {
+	stats := &uecontext.TrafficStats{PacketsSent: 4, PacketsLost: 1, BytesSent: 1000, BytesReceived: 500, Duration: 2 * time.Second}
+	assert.Equal(t, 4000.0, stats.UlThroughput())
+	assert.Equal(t, 2000.0, stats.DlThroughput())
+	assert.Equal(t, 25.0, stats.LossRate())
+
+	empty := &uecontext.TrafficStats{}
+	assert.Zero(t, empty.UlThroughput())
+	assert.Zero(t, empty.LossRate())
+}
@@ -0,0 +1,90 @@
+package test
+
+import (
+	"net"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"du_ue/internal/uecontext"
+	"du_ue/pkg/config"
+)
+
+// skipWithoutTun skips tests that create TUN devices and routing rules without the privileges for it
+func skipWithoutTun(t *testing.T) {
+	if _, err := os.Stat("/dev/net/tun"); err != nil {
+		t.Skipf("TUN devices not available: %v", err)
+	}
+	if os.Geteuid() != 0 {
+		t.Skip("TUN devices need CAP_NET_ADMIN")
+	}
+	if _, err := exec.LookPath("ip"); err != nil {
+		t.Skip("ip command not available")
+	}
+}
+
+// Test 1: Local applications reach the data network through the TUN device of the session
+func TestTunDevice(t *testing.T) {
+	skipWithoutTun(t)
+	ue := createTestUe(t, config.UEConfig{Tun: config.TunConfig{Enabled: true, Prefix: "uetest", TableBase: 150}})
+	startUserPlane(t, ue)
+
+	require.NoError(t, ue.OpenTunForTest(1))
+	closed := false
+	t.Cleanup(func() {
+		if !closed {
+			ue.CloseTunForTest(1)
+		}
+	})
+
+	iface, err := net.InterfaceByName("uetest1")
+	require.NoError(t, err)
+	assert.NotZero(t, iface.Flags&net.FlagUp)
+	addrs, err := iface.Addrs()
+	require.NoError(t, err)
+	var ips []string
+	for _, addr := range addrs {
+		ips = append(ips, addr.String())
+	}
+	assert.Contains(t, ips, "10.45.0.2/32")
+
+	rules, err := exec.Command("ip", "rule", "show", "from", ueIp.String()).Output()
+	require.NoError(t, err)
+	assert.Contains(t, string(rules), "lookup 151")
+
+	// UL through the session, echoed back by the data network as DL
+	conn, err := net.DialUDP("udp", &net.UDPAddr{IP: ueIp}, &net.UDPAddr{IP: dnIp, Port: 5001})
+	require.NoError(t, err)
+	defer conn.Close()
+	_, err = conn.Write([]byte("hello"))
+	require.NoError(t, err)
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(3 * time.Second))
+	n, err := conn.Read(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "hello", string(buf[:n]))
+
+	ue.CloseTunForTest(1)
+	closed = true
+	_, err = net.InterfaceByName("uetest1")
+	assert.Error(t, err)
+	rules, err = exec.Command("ip", "rule", "show", "from", ueIp.String()).Output()
+	require.NoError(t, err)
+	assert.NotContains(t, string(rules), "lookup 151")
+}
+
+// Test 2: A session without an IP address gets no TUN device
+func TestTunWithoutIp(t *testing.T) {
+	ue := createTestUe(t, config.UEConfig{Tun: config.TunConfig{Enabled: true}})
+	ue.AddPduSessionForTest(2, nil, 2)
+
+	assert.Error(t, ue.OpenTunForTest(2))
+	ue.CloseTunForTest(2)
+	_, err := net.InterfaceByName(uecontext.DEFAULT_TUN_PREFIX + "2")
+	assert.Error(t, err)
+}
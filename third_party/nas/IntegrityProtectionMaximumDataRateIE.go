@@ -0,0 +1,51 @@
+/*
+* Copyright [2024] [Quang Tung Thai <tqtung@etri.re.kr>]
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package nas
+
+// 9.11.4.7 Integrity protection maximum data rate [M V 2]
+type IntegrityProtectionMaximumDataRate struct {
+	uplink   uint8
+	downlink uint8
+}
+
+func NewIntegrityProtectionMaximumDataRate(u, d uint8) IntegrityProtectionMaximumDataRate {
+	return IntegrityProtectionMaximumDataRate{
+		uplink:   u,
+		downlink: d,
+	}
+}
+
+func (ie *IntegrityProtectionMaximumDataRate) Uplink() uint8 {
+	return ie.uplink
+}
+
+func (ie *IntegrityProtectionMaximumDataRate) Downlink() uint8 {
+	return ie.downlink
+}
+
+func (ie *IntegrityProtectionMaximumDataRate) encode() (wire []byte, err error) {
+	wire = []byte{ie.uplink, ie.downlink}
+	return
+}
+
+func (ie *IntegrityProtectionMaximumDataRate) decode(wire []byte) (err error) {
+	if len(wire) != 2 {
+		err = ErrInvalidSize
+		return
+	}
+	ie.uplink, ie.downlink = wire[0], wire[1]
+	return
+}
@@ -0,0 +1,32 @@
+/*
+* Copyright [2024] [Quang Tung Thai <tqtung@etri.re.kr>]
+*
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*
+*     http://www.apache.org/licenses/LICENSE-2.0
+*
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+ */
+package nas
+
+// 9.11.3.31B Mapped NSSAI [O TLV 3-42]
+type MappedNssai struct {
+	Bytes []byte
+}
+
+func (ie *MappedNssai) encode() (wire []byte, err error) {
+	wire = ie.Bytes
+	return
+}
+
+func (ie *MappedNssai) decode(wire []byte) (err error) {
+	ie.Bytes = make([]byte, len(wire))
+	copy(ie.Bytes, wire)
+	return
+}